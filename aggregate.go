@@ -0,0 +1,109 @@
+package selection_condition
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	// GroupByParamName is the param of the fields the rows are grouped by: group_by=country,city
+	GroupByParamName = "group_by"
+	// AggregateParamName is the param of the aggregates of the groups: agg=count,sum:amount
+	AggregateParamName = "agg"
+	// AggregateFieldSeparator separates the function of an aggregate and its field: sum:amount
+	AggregateFieldSeparator = ":"
+
+	AggregateCount = "count"
+	AggregateSum   = "sum"
+	AggregateAvg   = "avg"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+)
+
+var AggregateVariants = []string{AggregateCount, AggregateSum, AggregateAvg, AggregateMin, AggregateMax}
+
+// Aggregate is a function of the rows of a group by the path of Go names of a field,
+// the field of count is empty to count the rows.
+type Aggregate struct {
+	Func  string `json:"func"`
+	Field string `json:"field,omitempty"`
+}
+
+// parseGroupByParam parses the fields of the grouping to the paths of Go names.
+func parseGroupByParam(s *Schema, vals []string) ([]string, error) {
+	items := strings.Split(vals[0], s.o.valuesSeparator)
+	fields := make([]string, 0, len(items))
+
+	for _, item := range items {
+		paramName := strings.TrimSpace(item)
+		if paramName == "" {
+			return nil, newParamError(ErrInvalidParam, GroupByParamName, "Empty field in parameter %s", GroupByParamName)
+		}
+		fieldName, _, ok := s.fieldByParamName(paramName)
+		if !ok {
+			return nil, newParamError(ErrUnknownField, paramName, "Unknown field %s", paramName)
+		}
+		if !slices.Contains(fields, fieldName) {
+			fields = append(fields, fieldName)
+		}
+	}
+	return fields, nil
+}
+
+// parseAggregateParam parses the aggregates like count, count:id or sum:amount, sum and avg require a numeric field.
+func parseAggregateParam(s *Schema, vals []string) ([]Aggregate, error) {
+	items := strings.Split(vals[0], s.o.valuesSeparator)
+	aggregates := make([]Aggregate, 0, len(items))
+
+	for _, item := range items {
+		fn, paramName, hasField := strings.Cut(strings.TrimSpace(item), AggregateFieldSeparator)
+		if !slices.Contains(AggregateVariants, fn) {
+			return nil, newParamError(ErrInvalidOperator, AggregateParamName, "Unknown aggregate %q in parameter %s", fn, AggregateParamName)
+		}
+
+		aggregate := Aggregate{Func: fn}
+		switch {
+		case hasField:
+			fieldName, f, ok := s.fieldByParamName(paramName)
+			if !ok {
+				return nil, newParamError(ErrUnknownField, paramName, "Unknown field %s", paramName)
+			}
+			if (fn == AggregateSum || fn == AggregateAvg) && !isNumericType(f.typ) {
+				return nil, newParamError(ErrInvalidOperator, paramName, "Aggregate %q requires a numeric field, %s is not", fn, paramName)
+			}
+			aggregate.Field = fieldName
+		case fn != AggregateCount:
+			return nil, newParamError(ErrInvalidParam, AggregateParamName, "Aggregate %q requires a field: %s%s<field>", fn, fn, AggregateFieldSeparator)
+		}
+
+		if !slices.Contains(aggregates, aggregate) {
+			aggregates = append(aggregates, aggregate)
+		}
+	}
+	return aggregates, nil
+}
+
+// checkGrouping checks that the projection of grouped rows has only the fields they are grouped by.
+func checkGrouping(conditions *SelectionCondition, s *Schema) error {
+	if len(conditions.GroupBy) == 0 {
+		return nil
+	}
+	for _, field := range conditions.Fields {
+		if !slices.Contains(conditions.GroupBy, field) {
+			paramName, _ := s.paramNameByPath(field)
+			return newParamError(ErrInvalidParam, FieldsParamName, "Field %s of parameter %s is not in parameter %s", paramName, FieldsParamName, GroupByParamName)
+		}
+	}
+	return nil
+}
+
+func isNumericType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
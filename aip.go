@@ -0,0 +1,353 @@
+package selection_condition
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// AIPFilterParamName is the param of a filter in the AIP-160 syntax.
+const AIPFilterParamName = "filter"
+
+// aipComparators maps comparators of AIP-160 to conditions, "!=" is the negated eq and ":" is eq as for
+// a field which is not a collection.
+var aipComparators = map[string]string{
+	"=":  ConditionEq,
+	"!=": ConditionEq,
+	":":  ConditionEq,
+	"<":  ConditionLt,
+	"<=": ConditionLte,
+	">":  ConditionGt,
+	">=": ConditionGte,
+}
+
+// ParseAIPFilter parses the filter in the syntax of AIP-160 (https://google.aip.dev/160) by the fields
+// of the struct pointed by struc, e.g.
+//
+//	age > 30 AND (name = "john" OR NOT status = "archived")
+//
+// It returns the condition with Where only, Where is WhereConditions if all conditions are joined by AND
+// otherwise it is a WhereConditionGroup. Global restrictions without a field and functions are not supported.
+func ParseAIPFilter(filter string, struc interface{}, opts ...Option) (*SelectionCondition, error) {
+	schema, err := NewParser(opts...).Schema(struc)
+	if err != nil {
+		return nil, err
+	}
+	return schema.ParseAIPFilter(filter)
+}
+
+// ParseAIPFilter parses the filter in the syntax of AIP-160 by the fields of the schema.
+func (s *Schema) ParseAIPFilter(filter string) (*SelectionCondition, error) {
+	return s.ParseAIPFilterContext(context.Background(), filter)
+}
+
+// ParseAIPFilterContext is ParseAIPFilter with ctx passed to the functions set by WithForcedConditionsFunc.
+func (s *Schema) ParseAIPFilterContext(ctx context.Context, filter string) (*SelectionCondition, error) {
+	conditions, err := parseAIPFilter(s, filter)
+	if err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	errs := &errorCollector{all: s.o.allErrors}
+	if err := checkWhere(ctx, s, conditions, errs); err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	if err := errs.err(); err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	return conditions, nil
+}
+
+func parseAIPFilter(s *Schema, filter string) (*SelectionCondition, error) {
+	tokens, err := aipTokens(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return &SelectionCondition{Where: WhereConditions{}}, nil
+	}
+
+	p := &aipParser{schema: s, filter: filter, tokens: tokens}
+	where, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if !p.end() {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	return &SelectionCondition{Where: asWhere(aipWhere(where))}, nil
+}
+
+// aipWhere returns WhereConditions for a condition or for a group of conditions joined by AND.
+func aipWhere(where interface{}) interface{} {
+	switch w := where.(type) {
+	case WhereCondition:
+		return WhereConditions{w}
+	case WhereConditionGroup:
+		if w.Logic != LogicAnd || w.Not {
+			return w
+		}
+		conds := make(WhereConditions, 0, len(w.Conditions))
+		for _, item := range w.Conditions {
+			c, ok := item.(WhereCondition)
+			if !ok {
+				return w
+			}
+			conds = append(conds, c)
+		}
+		return conds
+	}
+	return where
+}
+
+type aipTokenKind int
+
+const (
+	aipText aipTokenKind = iota
+	aipString
+	aipComparator
+	aipOpening
+	aipClosing
+	aipMinus
+)
+
+type aipToken struct {
+	kind aipTokenKind
+	text string
+}
+
+func aipTokens(filter string) ([]aipToken, error) {
+	var tokens []aipToken
+	runes := []rune(filter)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, aipToken{kind: aipOpening, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, aipToken{kind: aipClosing, text: ")"})
+			i++
+		case r == '"' || r == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, newParamError(ErrInvalidFilter, AIPFilterParamName, "Unterminated string in filter %q", filter)
+			}
+			tokens = append(tokens, aipToken{kind: aipString, text: b.String()})
+			i = j + 1
+		case strings.ContainsRune("=!<>:", r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && r != '=' && r != ':' {
+				op += "="
+			}
+			if _, ok := aipComparators[op]; !ok {
+				return nil, newParamError(ErrInvalidFilter, AIPFilterParamName, "Unknown comparator %q in filter %q", op, filter)
+			}
+			tokens = append(tokens, aipToken{kind: aipComparator, text: op})
+			i += len(op)
+		case r == '-' && (len(tokens) == 0 || tokens[len(tokens)-1].kind != aipComparator):
+			tokens = append(tokens, aipToken{kind: aipMinus, text: "-"})
+			i++
+		default:
+			// a value may contain colons like a timestamp 2024-01-01T10:00:00Z
+			stop := "()=!<>:\"'"
+			if len(tokens) > 0 && tokens[len(tokens)-1].kind == aipComparator {
+				stop = "()=!<>\"'"
+			}
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(stop, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, aipToken{kind: aipText, text: string(runes[i:j])})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// aipParser parses the tokens by the grammar of AIP-160 where OR binds tighter than AND:
+//
+//	expression = sequence {"AND" sequence}
+//	sequence   = factor {factor}
+//	factor     = term {"OR" term}
+//	term       = ["NOT" | "-"] (restriction | "(" expression ")")
+//	restriction = field comparator value
+type aipParser struct {
+	schema *Schema
+	filter string
+	tokens []aipToken
+	pos    int
+}
+
+func (p *aipParser) end() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *aipParser) peek() aipToken {
+	return p.tokens[p.pos]
+}
+
+func (p *aipParser) keyword(word string) bool {
+	if p.end() || p.peek().kind != aipText || p.peek().text != word {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *aipParser) errorf(format string, args ...interface{}) error {
+	return newParamError(ErrInvalidFilter, AIPFilterParamName, "Invalid filter %q: "+format, append([]interface{}{p.filter}, args...)...)
+}
+
+func (p *aipParser) expression() (interface{}, error) {
+	var items []interface{}
+	for {
+		item, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.keyword("AND") {
+			return aipGroup(LogicAnd, items), nil
+		}
+	}
+}
+
+func (p *aipParser) sequence() (interface{}, error) {
+	var items []interface{}
+	for {
+		item, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.end() || p.peek().kind == aipClosing || (p.peek().kind == aipText && p.peek().text == "AND") {
+			return aipGroup(LogicAnd, items), nil
+		}
+	}
+}
+
+func (p *aipParser) factor() (interface{}, error) {
+	var items []interface{}
+	for {
+		item, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.keyword("OR") {
+			return aipGroup(LogicOr, items), nil
+		}
+	}
+}
+
+func (p *aipParser) term() (interface{}, error) {
+	if p.end() {
+		return nil, p.errorf("unexpected end")
+	}
+
+	negated := p.keyword("NOT")
+	if !negated && p.peek().kind == aipMinus {
+		negated = true
+		p.pos++
+	}
+	if p.end() {
+		return nil, p.errorf("unexpected end")
+	}
+
+	var item interface{}
+	var err error
+	if p.peek().kind == aipOpening {
+		p.pos++
+		if item, err = p.expression(); err != nil {
+			return nil, err
+		}
+		if p.end() || p.peek().kind != aipClosing {
+			return nil, p.errorf("missing %q", ")")
+		}
+		p.pos++
+	} else if item, err = p.restriction(); err != nil {
+		return nil, err
+	}
+
+	if negated {
+		return aipNot(item), nil
+	}
+	return item, nil
+}
+
+func (p *aipParser) restriction() (interface{}, error) {
+	field := p.peek()
+	if field.kind != aipText {
+		return nil, p.errorf("field expected, got %q", field.text)
+	}
+	p.pos++
+
+	if p.end() || p.peek().kind != aipComparator {
+		return nil, p.errorf("comparator expected after %q", field.text)
+	}
+	comparator := p.peek().text
+	p.pos++
+
+	if p.end() || (p.peek().kind != aipText && p.peek().kind != aipString) {
+		return nil, p.errorf("value expected after %q", field.text+comparator)
+	}
+	value := p.peek().text
+	p.pos++
+
+	key := field.text + p.schema.o.conditionSeparator + aipComparators[comparator]
+	cond, ok, err := parseWhereParam(p.schema, key, []string{value})
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, newParamError(ErrUnknownField, field.text, "Unknown field %s", field.text)
+	}
+
+	if comparator == "!=" {
+		return aipNot(*cond), nil
+	}
+	return *cond, nil
+}
+
+// aipGroup joins the items by the logic, a single item is returned as it is.
+func aipGroup(logic string, items []interface{}) interface{} {
+	if len(items) == 1 {
+		return items[0]
+	}
+
+	group := WhereConditionGroup{
+		Logic:      logic,
+		Conditions: make([]interface{}, 0, len(items)),
+	}
+	for _, item := range items {
+		// nested groups with the same logic are flattened: a AND (b AND c) is a AND b AND c
+		if g, ok := item.(WhereConditionGroup); ok && g.Logic == logic && !g.Not {
+			group.Conditions = append(group.Conditions, g.Conditions...)
+			continue
+		}
+		group.Conditions = append(group.Conditions, item)
+	}
+	return group
+}
+
+func aipNot(item interface{}) interface{} {
+	if g, ok := item.(WhereConditionGroup); ok && !g.Not {
+		g.Not = true
+		return g
+	}
+	return WhereConditionGroup{
+		Logic:      LogicAnd,
+		Not:        true,
+		Conditions: []interface{}{item},
+	}
+}
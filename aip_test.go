@@ -0,0 +1,105 @@
+package selection_condition
+
+import "testing"
+
+func TestParseAIPFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		opts   []Option
+		// params are the query params of the same conditions
+		params map[string][]string
+		err    error
+	}{
+		{
+			name:   "empty",
+			filter: "",
+			params: map[string][]string{},
+		},
+		{
+			name:   "comparisons",
+			filter: `status = "open" AND amount >= 10 AND quantity < 5 AND author.name : john`,
+			params: map[string][]string{"status": {"open"}, "amount__gte": {"10"}, "quantity__lt": {"5"}, "author.name": {"john"}},
+		},
+		{
+			name:   "sequence is and",
+			filter: `status = "open" amount > 10`,
+			params: map[string][]string{"status": {"open"}, "amount__gt": {"10"}},
+		},
+		{
+			name:   "or binds tighter than and",
+			filter: `status = "open" OR status = "new" AND quantity < 5`,
+			params: map[string][]string{"or": {"(status=open,status=new)"}, "quantity__lt": {"5"}},
+		},
+		{
+			name:   "parentheses",
+			filter: `(status = "open" OR amount > 10) AND quantity < 5`,
+			params: map[string][]string{"or": {"(status=open,amount__gt=10)"}, "quantity__lt": {"5"}},
+		},
+		{
+			name:   "not",
+			filter: `NOT status = "archived" AND -paid = true`,
+			params: map[string][]string{"not": {"(status=archived)", "(paid=true)"}},
+		},
+		{
+			name:   "not equal",
+			filter: `status != "archived"`,
+			params: map[string][]string{"not": {"(status=archived)"}},
+		},
+		{
+			name:   "time with colons",
+			filter: `created_at > 2024-01-01T10:00:00Z`,
+			params: map[string][]string{"created_at__gt": {"2024-01-01T10:00:00Z"}},
+		},
+		{
+			name:   "unterminated string",
+			filter: `status = "open`,
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "unbalanced parentheses",
+			filter: `(status = "open"`,
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "bad value",
+			filter: `quantity = many`,
+			err:    &ErrBadValue{},
+		},
+		{
+			name:   "max conditions",
+			filter: `status = "open" AND amount > 10`,
+			opts:   []Option{WithMaxConditions(1)},
+			err:    ErrTooManyConditions,
+		},
+		{
+			name:   "required filter",
+			filter: `amount > 10`,
+			opts:   []Option{WithRequiredFilters("Status")},
+			err:    ErrRequiredFilter,
+		},
+		{
+			name:   "contradiction",
+			filter: `status = "open" AND status = "new"`,
+			opts:   []Option{WithRejectContradictions()},
+			err:    ErrContradiction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAIPFilter(tt.filter, &testOrder{}, tt.opts...)
+			checkError(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			want, err := ParseQueryParams(tt.params, &testOrder{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("got %s, want %s", got.normalizedJSON(), want.normalizedJSON())
+			}
+		})
+	}
+}
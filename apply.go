@@ -0,0 +1,596 @@
+package selection_condition
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Apply returns the items selected by cond: the ones matching its where conditions ordered by its sort order
+// and paginated by its limit and offset, e.g. to serve cached data or a fake of a repository by the same params:
+//
+//	cond, err := sc.ParseQueryParams(r.URL.Query(), &User{})
+//	...
+//	users, err := sc.Apply(cachedUsers, cond)
+//
+// Items are structs or pointers on structs, fields of conditions are their paths of Go names. A condition on a path
+// through a slice matches if an element of the slice matches. Like in SQL a condition on a null value does not match
+// and nulls are greater than other values, unlike in SQL its negation matches. A case-insensitive sort field
+// is compared in lower case. The projection with its distinct rows, the relations and the grouping are not applied.
+// The items are not changed, a nil cond selects all of them.
+func Apply[T any](items []T, cond *SelectionCondition) ([]T, error) {
+	if cond == nil {
+		return append([]T(nil), items...), nil
+	}
+
+	res := make([]T, 0, len(items))
+	for i := range items {
+		ok, err := matchWhere(reflect.ValueOf(&items[i]).Elem(), cond.Where)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			res = append(res, items[i])
+		}
+	}
+
+	if err := sortItems(res, cond.SortOrder); err != nil {
+		return nil, err
+	}
+
+	if uint(len(res)) <= cond.Offset {
+		return res[:0], nil
+	}
+	res = res[cond.Offset:]
+	if cond.Limit > 0 && uint(len(res)) > cond.Limit {
+		res = res[:cond.Limit]
+	}
+	return res, nil
+}
+
+func matchWhere(item reflect.Value, where interface{}) (bool, error) {
+	switch w := where.(type) {
+	case nil:
+		return true, nil
+	case WhereConditions:
+		return matchConditions(item, w)
+	case WhereCondition:
+		return matchCondition(item, w)
+	case WhereConditionGroup:
+		return matchGroup(item, w)
+	}
+	return false, errors.Errorf("Where must be WhereConditions or a WhereConditionGroup, got %T", where)
+}
+
+func matchConditions(item reflect.Value, conditions []WhereCondition) (bool, error) {
+	for _, c := range conditions {
+		ok, err := matchCondition(item, c)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchGroup(item reflect.Value, group WhereConditionGroup) (bool, error) {
+	res := group.Logic != LogicOr
+	for _, c := range group.Conditions {
+		var ok bool
+		var err error
+		switch c := c.(type) {
+		case WhereCondition:
+			ok, err = matchCondition(item, c)
+		case WhereConditionGroup:
+			ok, err = matchGroup(item, c)
+		default:
+			return false, errors.Errorf("Condition of a group must be a WhereCondition or a WhereConditionGroup, got %T", c)
+		}
+		if err != nil {
+			return false, err
+		}
+		if ok == (group.Logic == LogicOr) {
+			res = ok
+			break
+		}
+	}
+	return res != group.Not, nil
+}
+
+// matchCondition reports whether a value of the field of the item matches the condition.
+func matchCondition(item reflect.Value, c WhereCondition) (bool, error) {
+	values, ok := pathValues(item, c.Field)
+	if !ok {
+		return false, errors.Errorf("Field %s not found in %s", c.Field, reflect.Indirect(item).Type())
+	}
+	if c.Condition == ConditionOverlaps {
+		matched, err := matchOverlaps(item, c)
+		return matched, errors.Wrapf(err, "Condition %s of field %s", c.Condition, c.Field)
+	}
+	if isGeoCondition(c.Condition) {
+		matched, err := matchGeo(item, c)
+		return matched, errors.Wrapf(err, "Condition %s of field %s", c.Condition, c.Field)
+	}
+	if c.JSONPath != "" || c.Condition == ConditionJSONContains {
+		matched, err := matchJSON(values, c)
+		return matched, errors.Wrapf(err, "Condition %s of field %s", c.Condition, c.Field)
+	}
+	if c.Condition == ConditionAll {
+		matched, err := matchAll(values, c)
+		return matched, errors.Wrapf(err, "Condition %s of field %s", c.Condition, c.Field)
+	}
+
+	for _, v := range values {
+		value, ok := comparableValue(v)
+		if !ok {
+			continue
+		}
+		matched, err := matchValue(value, c)
+		if err != nil {
+			return false, errors.Wrapf(err, "Condition %s of field %s", c.Condition, c.Field)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchOverlaps reports whether the range of the field has common points with the one of the condition,
+// the range is taken as a whole so the field is not expanded as a list.
+func matchOverlaps(item reflect.Value, c WhereCondition) (bool, error) {
+	v, ok := fieldValueByPath(item, c.Field)
+	if !ok || !reflect.Indirect(v).IsValid() {
+		return false, nil
+	}
+	v = reflect.Indirect(v)
+	bounds, ok := c.Value.([]interface{})
+	if !ok || len(bounds) != 2 || !isRangeType(v.Type()) {
+		return false, errors.Errorf("Range of type %s and value %v must have two bounds", v.Type(), c.Value)
+	}
+
+	start, ok := comparableValue(v.Index(0))
+	if !ok {
+		return false, nil
+	}
+	end, ok := comparableValue(v.Index(1))
+	if !ok {
+		return false, nil
+	}
+	cmp, err := compareWithValue(start, bounds[1])
+	if err != nil || cmp > 0 {
+		return false, err
+	}
+	cmp, err = compareWithValue(end, bounds[0])
+	return cmp >= 0, err
+}
+
+// matchGeo reports whether the point of the geo field matches the condition, a pair of numbers is taken
+// as a point so the field is not expanded as a list.
+func matchGeo(item reflect.Value, c WhereCondition) (bool, error) {
+	v, ok := fieldValueByPath(item, c.Field)
+	if !ok || !reflect.Indirect(v).IsValid() {
+		return false, nil
+	}
+	point, ok := geoPointOf(v)
+	if !ok {
+		return false, errors.Errorf("Value of type %s is not a point", v.Type())
+	}
+
+	switch value := c.Value.(type) {
+	case GeoNear:
+		return distance(point, value.Point) <= value.Radius, nil
+	case GeoBoundingBox:
+		return value.Contains(point), nil
+	}
+	return false, errors.Errorf("Value of type %T is not supported", c.Value)
+}
+
+// matchJSON reports whether a document of the JSON field contains the one of the condition
+// or its value at the JSON path of the condition matches the condition.
+func matchJSON(values []reflect.Value, c WhereCondition) (bool, error) {
+	for _, v := range values {
+		document, err := jsonDocument(v)
+		if err != nil {
+			return false, err
+		}
+		if c.Condition == ConditionJSONContains {
+			if jsonContains(document, c.Value) {
+				return true, nil
+			}
+			continue
+		}
+
+		text, ok := jsonValueAt(document, c.JSONPath)
+		if !ok {
+			continue
+		}
+		matched, err := matchValue(text, c)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// matchAll reports whether each of the values of the condition equals one of the values of the field.
+func matchAll(values []reflect.Value, c WhereCondition) (bool, error) {
+	list := reflect.ValueOf(c.Value)
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return false, errors.Errorf("Value must be a list, got %T", c.Value)
+	}
+	for i := 0; i < list.Len(); i++ {
+		var found bool
+		for _, v := range values {
+			value, ok := comparableValue(v)
+			if !ok {
+				continue
+			}
+			cmp, err := compareWithValue(value, list.Index(i).Interface())
+			if err != nil {
+				return false, err
+			}
+			if cmp == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchValue(value interface{}, c WhereCondition) (bool, error) {
+	switch c.Condition {
+	case ConditionIn, ConditionBt, ConditionBtExcl, ConditionAny:
+		list := reflect.ValueOf(c.Value)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			return false, errors.Errorf("Value must be a list, got %T", c.Value)
+		}
+		if c.Condition == ConditionBt || c.Condition == ConditionBtExcl {
+			if list.Len() != 2 {
+				return false, errors.Errorf("Value must be a list of two values, got %d", list.Len())
+			}
+			from, err := compareWithValue(value, list.Index(0).Interface())
+			if err != nil {
+				return false, err
+			}
+			to, err := compareWithValue(value, list.Index(1).Interface())
+			if err != nil {
+				return false, err
+			}
+			if c.Condition == ConditionBtExcl {
+				return from > 0 && to < 0, nil
+			}
+			return from >= 0 && to <= 0, nil
+		}
+		for i := 0; i < list.Len(); i++ {
+			cmp, err := compareWithValue(value, list.Index(i).Interface())
+			if err != nil {
+				return false, err
+			}
+			if cmp == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ConditionTS:
+		text, ok := value.(string)
+		if !ok {
+			return false, errors.Errorf("Text search requires a string field, got %T", value)
+		}
+		text = strings.ToLower(text)
+		for _, word := range strings.Fields(strings.ToLower(fmt.Sprint(c.Value))) {
+			if !strings.Contains(text, word) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case ConditionFuzzy:
+		text, ok := value.(string)
+		fuzzy, isFuzzy := c.Value.(Fuzzy)
+		if !ok || !isFuzzy {
+			return false, errors.Errorf("Condition %q requires a string field and a Fuzzy value, got %T and %T", c.Condition, value, c.Value)
+		}
+		return trigramSimilarity(text, fuzzy.Text) >= fuzzy.Similarity, nil
+	case ConditionIEq:
+		text, ok := value.(string)
+		if !ok {
+			return false, errors.Errorf("Condition %q requires a string field, got %T", c.Condition, value)
+		}
+		return strings.EqualFold(text, fmt.Sprint(c.Value)), nil
+	case ConditionILike:
+		text, ok := value.(string)
+		if !ok {
+			return false, errors.Errorf("Condition %q requires a string field, got %T", c.Condition, value)
+		}
+		return strings.Contains(strings.ToLower(text), strings.ToLower(fmt.Sprint(c.Value))), nil
+	}
+
+	cmp, err := compareWithValue(value, c.Value)
+	if err != nil {
+		return false, err
+	}
+	switch c.Condition {
+	case ConditionEq:
+		return cmp == 0, nil
+	case ConditionGt:
+		return cmp > 0, nil
+	case ConditionGte:
+		return cmp >= 0, nil
+	case ConditionLt:
+		return cmp < 0, nil
+	case ConditionLte:
+		return cmp <= 0, nil
+	}
+	return false, errors.Errorf("Unknown condition %q", c.Condition)
+}
+
+// compareWithValue compares the comparable value of a field with the value of a condition.
+func compareWithValue(value interface{}, condValue interface{}) (int, error) {
+	other, ok := comparableValue(reflect.ValueOf(condValue))
+	if !ok {
+		return 0, errors.New("Value must not be null")
+	}
+	return compareValues(value, other)
+}
+
+// pathValues returns the values of the field by its path of Go names, the elements of slices on the path
+// and of a slice at its end are returned one by one. It returns false if the field is not found.
+func pathValues(v reflect.Value, path string) ([]reflect.Value, bool) {
+	values := []reflect.Value{v}
+	for _, name := range strings.Split(path, FieldPathSeparator) {
+		next := make([]reflect.Value, 0, len(values))
+		for _, v := range expandValues(values) {
+			v = reflect.Indirect(v)
+			if !v.IsValid() {
+				continue
+			}
+			if v.Kind() != reflect.Struct {
+				return nil, false
+			}
+			field, ok := v.Type().FieldByName(name)
+			if !ok {
+				return nil, false
+			}
+			// FieldByIndexErr does not panic on a nil pointer on an embedded struct
+			if fieldVal, err := v.FieldByIndexErr(field.Index); err == nil {
+				next = append(next, fieldVal)
+			}
+		}
+		values = next
+	}
+	return expandValues(values), true
+}
+
+// expandValues replaces slices and arrays except UUIDs by their elements.
+func expandValues(values []reflect.Value) []reflect.Value {
+	res := make([]reflect.Value, 0, len(values))
+	for _, v := range values {
+		elem := reflect.Indirect(v)
+		if !elem.IsValid() || (elem.Kind() != reflect.Slice && elem.Kind() != reflect.Array) || isUUIDType(elem.Type()) || elem.Type() == jsonRawMessageType {
+			res = append(res, v)
+			continue
+		}
+		for i := 0; i < elem.Len(); i++ {
+			res = append(res, elem.Index(i))
+		}
+	}
+	return res
+}
+
+// comparableValue returns the value as a string, an int64, a uint64, a float64, a bool or a time.Time,
+// it returns false for a null value.
+func comparableValue(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	v, ok := nonNullValue(v)
+	if !ok {
+		return nil, false
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time), true
+	case isUUIDType(v.Type()):
+		return encodeValue(v.Interface()), true
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Bool:
+		return v.Bool(), true
+	}
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text), true
+			}
+		}
+	}
+	return v.Interface(), true
+}
+
+// compareValues compares comparable values, numbers of different types are compared by their values.
+func compareValues(a, b interface{}) (int, error) {
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b), nil
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			switch {
+			case a == b:
+				return 0, nil
+			case b:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	case int64, uint64, float64:
+		switch b.(type) {
+		case int64, uint64, float64:
+			return compareNumbers(a, b), nil
+		}
+	}
+	return 0, errors.Errorf("Cannot compare %T with %T", a, b)
+}
+
+func compareNumbers(a, b interface{}) int {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return cmpOrdered(a, b)
+		case uint64:
+			if a < 0 {
+				return -1
+			}
+			return cmpOrdered(uint64(a), b)
+		}
+	case uint64:
+		switch b := b.(type) {
+		case uint64:
+			return cmpOrdered(a, b)
+		case int64:
+			return -compareNumbers(b, a)
+		}
+	}
+	return cmpOrdered(toFloat(a), toFloat(b))
+}
+
+func toFloat(n interface{}) float64 {
+	switch n := n.(type) {
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	}
+	return n.(float64)
+}
+
+func cmpOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// sortItems orders the items by the sort order keeping the order of equal items.
+func sortItems[T any](items []T, sortOrder []SortField) error {
+	if len(sortOrder) == 0 {
+		return nil
+	}
+
+	// keys are taken once as getting them by reflection is slow, a nil key is null
+	keys := make([][]interface{}, len(items))
+	for i := range items {
+		item := reflect.ValueOf(&items[i]).Elem()
+		keys[i] = make([]interface{}, len(sortOrder))
+		for j, sortField := range sortOrder {
+			values, ok := pathValues(item, sortField.Field)
+			if !ok {
+				return errors.Errorf("Field %s not found in %s", sortField.Field, reflect.Indirect(item).Type())
+			}
+			if len(values) == 0 {
+				continue
+			}
+			value, ok := comparableValue(values[0])
+			if !ok {
+				continue
+			}
+			if s, isString := value.(string); isString && sortField.CaseInsensitive {
+				value = strings.ToLower(s)
+			}
+			keys[i][j] = value
+		}
+	}
+
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	var err error
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a, b := keys[indexes[i]], keys[indexes[j]]
+		for k, sortField := range sortOrder {
+			cmp, cmpErr := compareSortKeys(a[k], b[k], sortField)
+			if cmpErr != nil {
+				if err == nil {
+					err = errors.Wrapf(cmpErr, "Sort field %s", sortField.Field)
+				}
+				return false
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+
+	sorted := make([]T, len(items))
+	for i, index := range indexes {
+		sorted[i] = items[index]
+	}
+	copy(items, sorted)
+	return nil
+}
+
+// compareSortKeys compares the keys in the order of the sort field, nulls are greater than other values
+// unless their placement is set.
+func compareSortKeys(a, b interface{}, sortField SortField) (int, error) {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return 0, nil
+		}
+		cmp := 1
+		if b == nil {
+			cmp = -1
+		}
+		switch {
+		case sortField.Nulls == NullsFirst:
+			return -cmp, nil
+		case sortField.Nulls == NullsLast:
+			return cmp, nil
+		case sortField.Direction == SortOrderDesc:
+			return -cmp, nil
+		}
+		return cmp, nil
+	}
+
+	cmp, err := compareValues(a, b)
+	if sortField.Direction == SortOrderDesc {
+		cmp = -cmp
+	}
+	return cmp, err
+}
@@ -0,0 +1,132 @@
+package selection_condition
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	late := "delivered late"
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	orders := []testOrder{
+		{ID: 1, Status: "open", Amount: 10, Quantity: 1, CreatedAt: day, Author: testAuthor{Name: "John"}},
+		{ID: 2, Status: "new", Amount: 200, Quantity: 5, Paid: true, CreatedAt: day.Add(time.Hour), Author: testAuthor{Name: "jane"}},
+		{ID: 3, Status: "paid", Amount: 50, Quantity: 2, Paid: true, Comment: &late, CreatedAt: day.Add(2 * time.Hour), Author: testAuthor{Name: "Bob"}},
+		{ID: 4, Status: "open", Amount: 75.5, Quantity: 3, CreatedAt: day.Add(3 * time.Hour), Author: testAuthor{Name: "alice"}},
+	}
+
+	tests := []struct {
+		name string
+		cond *SelectionCondition
+		// want are the IDs of the selected orders
+		want []uint
+		err  bool
+	}{
+		{
+			name: "nil",
+			want: []uint{1, 2, 3, 4},
+		},
+		{
+			name: "eq",
+			cond: &SelectionCondition{Where: WhereConditions{{Field: "Status", Condition: ConditionEq, Value: "open"}}},
+			want: []uint{1, 4},
+		},
+		{
+			name: "comparisons joined by and",
+			cond: &SelectionCondition{Where: WhereConditions{
+				{Field: "Amount", Condition: ConditionGte, Value: 50.0},
+				{Field: "Quantity", Condition: ConditionLt, Value: 5},
+			}},
+			want: []uint{3, 4},
+		},
+		{
+			name: "in and bt",
+			cond: &SelectionCondition{Where: WhereConditions{
+				{Field: "Status", Condition: ConditionIn, Value: []interface{}{"open", "paid"}},
+				{Field: "CreatedAt", Condition: ConditionBt, Value: []interface{}{day.Add(time.Hour), day.Add(3 * time.Hour)}},
+			}},
+			want: []uint{3, 4},
+		},
+		{
+			name: "ilike of a nested field",
+			cond: &SelectionCondition{Where: WhereConditions{{Field: "Author.Name", Condition: ConditionILike, Value: "J"}}},
+			want: []uint{1, 2},
+		},
+		{
+			name: "null pointer does not match",
+			cond: &SelectionCondition{Where: WhereConditions{{Field: "Comment", Condition: ConditionILike, Value: "LATE"}}},
+			want: []uint{3},
+		},
+		{
+			name: "or",
+			cond: &SelectionCondition{Where: WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{
+				WhereCondition{Field: "Paid", Condition: ConditionEq, Value: true},
+				WhereCondition{Field: "Amount", Condition: ConditionLt, Value: 20.0},
+			}}},
+			want: []uint{1, 2, 3},
+		},
+		{
+			name: "not",
+			cond: &SelectionCondition{Where: WhereConditionGroup{Logic: LogicAnd, Not: true, Conditions: []interface{}{
+				WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"},
+				WhereCondition{Field: "Quantity", Condition: ConditionEq, Value: 1},
+			}}},
+			want: []uint{2, 3, 4},
+		},
+		{
+			name: "sort and page",
+			cond: &SelectionCondition{
+				SortOrder: []SortField{{Field: "Amount", Direction: SortOrderDesc}},
+				Limit:     2,
+				Offset:    1,
+			},
+			want: []uint{4, 3},
+		},
+		{
+			name: "case-insensitive sort",
+			cond: &SelectionCondition{SortOrder: []SortField{{Field: "Author.Name", Direction: SortOrderAsc, CaseInsensitive: true}}},
+			want: []uint{4, 3, 2, 1},
+		},
+		{
+			name: "sort by several fields",
+			cond: &SelectionCondition{SortOrder: []SortField{
+				{Field: "Status", Direction: SortOrderAsc},
+				{Field: "ID", Direction: SortOrderDesc},
+			}},
+			want: []uint{2, 4, 1, 3},
+		},
+		{
+			name: "offset beyond the items",
+			cond: &SelectionCondition{Offset: 10},
+			want: []uint{},
+		},
+		{
+			name: "unknown field",
+			cond: &SelectionCondition{Where: WhereConditions{{Field: "Unknown", Condition: ConditionEq, Value: 1}}},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(orders, tt.cond)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]uint, 0, len(got))
+			for _, order := range got {
+				ids = append(ids, order.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
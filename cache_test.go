@@ -0,0 +1,74 @@
+package selection_condition
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type benchAuthor struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Country string `json:"country"`
+}
+
+type benchOrder struct {
+	ID        uint        `json:"id" selection:"filter,sort"`
+	Status    string      `json:"status" selection:"filter,sort"`
+	Amount    float64     `json:"amount" selection:"filter,sort"`
+	Quantity  int         `json:"quantity" selection:"filter"`
+	Paid      bool        `json:"paid" selection:"filter"`
+	Comment   *string     `json:"comment" selection:"filter"`
+	CreatedAt time.Time   `json:"created_at" selection:"filter,sort"`
+	UpdatedAt *time.Time  `json:"updated_at" selection:"filter,sort"`
+	Author    benchAuthor `json:"author" selection:"filter"`
+}
+
+var benchParams = map[string][]string{
+	"status__in":     {"new,open,paid"},
+	"amount__bt":     {"10,500"},
+	"created_at__gt": {"2024-01-01T00:00:00Z"},
+	"author.name":    {"john"},
+	"sort_order":     {"created_at__desc,id"},
+	"limit":          {"50"},
+	"offset":         {"100"},
+}
+
+// BenchmarkParse compares parsing by a parser reused across requests, which compiles the schema of the struct
+// once, with ParseQueryParams compiling it on each call.
+func BenchmarkParse(b *testing.B) {
+	b.Run("cached", func(b *testing.B) {
+		p := NewParser()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := p.Parse(benchParams, &benchOrder{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseQueryParams(benchParams, &benchOrder{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkStructFieldIndexes compares the cached indexes of the fields of a struct with walking its type.
+func BenchmarkStructFieldIndexes(b *testing.B) {
+	structType := reflect.TypeOf(benchOrder{})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			cachedStructFieldIndexes(structType, DefaultTagName)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			structFieldIndexesByTagName(structType, DefaultTagName)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package selection_condition
+
+import "reflect"
+
+// Clone returns a deep copy of the condition, so the copy may be changed without changing the condition.
+func (e *SelectionCondition) Clone() *SelectionCondition {
+	if e == nil {
+		return nil
+	}
+
+	clone := *e
+	clone.Where = asWhere(cloneWhere(e.Where))
+	if e.SortOrder != nil {
+		clone.SortOrder = append([]SortField{}, e.SortOrder...)
+	}
+	if e.Fields != nil {
+		clone.Fields = append([]string{}, e.Fields...)
+	}
+	if e.Include != nil {
+		clone.Include = append([]string{}, e.Include...)
+	}
+	if e.GroupBy != nil {
+		clone.GroupBy = append([]string{}, e.GroupBy...)
+	}
+	if e.Aggregates != nil {
+		clone.Aggregates = append([]Aggregate{}, e.Aggregates...)
+	}
+	if e.Having != nil {
+		clone.Having = make([]HavingCondition, 0, len(e.Having))
+		for _, c := range e.Having {
+			c.Value = cloneCondition(WhereCondition{Value: c.Value}).Value
+			clone.Having = append(clone.Having, c)
+		}
+	}
+	return &clone
+}
+
+func cloneWhere(where interface{}) interface{} {
+	switch w := where.(type) {
+	case WhereConditions:
+		return WhereConditions(cloneConditions(w))
+	case WhereCondition:
+		return cloneCondition(w)
+	case WhereConditionGroup:
+		return cloneGroup(w)
+	}
+	return where
+}
+
+func cloneConditions(conditions []WhereCondition) []WhereCondition {
+	if conditions == nil {
+		return nil
+	}
+	res := make([]WhereCondition, 0, len(conditions))
+	for _, c := range conditions {
+		res = append(res, cloneCondition(c))
+	}
+	return res
+}
+
+func cloneGroup(group WhereConditionGroup) WhereConditionGroup {
+	clone := group
+	if group.Conditions != nil {
+		clone.Conditions = make([]interface{}, 0, len(group.Conditions))
+		for _, item := range group.Conditions {
+			clone.Conditions = append(clone.Conditions, cloneWhere(item))
+		}
+	}
+	return clone
+}
+
+// cloneCondition copies the slice of values of in and bt.
+func cloneCondition(c WhereCondition) WhereCondition {
+	v := reflect.ValueOf(c.Value)
+	if v.Kind() == reflect.Slice && !v.IsNil() {
+		values := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(values, v)
+		c.Value = values.Interface()
+	}
+	return c
+}
@@ -0,0 +1,467 @@
+// Command scgen generates a parser of query params for a struct that does not use reflection, for hot paths where
+// the reflection of parsing shows up in profiles. It is run by go generate in the package of the struct:
+//
+//	//go:generate go run github.com/minipkg/selection_condition/cmd/scgen -type Order
+//
+// The generated Parse<Type>Selection(params) returns the same condition as sc.ParseQueryParams(params, &Order{})
+// without options. It parses by a switch on the known names of params the conditions eq, gt, gte, lt, lte, in, bt
+// and bt_excl on fields of strings, booleans and numbers, the sort order without modifiers, limit and offset.
+// Any other params, e.g. on nested or time fields, and invalid values are passed to sc.ParseQueryParams,
+// so the errors are the same too. Fields with the enum tag or with conditions or transformers in the selection tag
+// are always parsed by sc.ParseQueryParams, converters registered for the predeclared types are not applied.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	sc "github.com/minipkg/selection_condition"
+	"github.com/minipkg/selection_condition/internal/naming"
+)
+
+// parseFuncs are the suffixes of the generated functions parsing values by the predeclared types of fields.
+var parseFuncs = map[string]string{
+	"string":  "String",
+	"int":     "Int",
+	"int8":    "Int",
+	"int16":   "Int",
+	"int32":   "Int",
+	"int64":   "Int",
+	"rune":    "Int",
+	"uint":    "Uint",
+	"uint8":   "Uint",
+	"uint16":  "Uint",
+	"uint32":  "Uint",
+	"uint64":  "Uint",
+	"byte":    "Uint",
+	"float32": "Float",
+	"float64": "Float",
+	"bool":    "Bool",
+}
+
+// orderedConditions are the conditions parsed by the generated code on fields of strings and numbers.
+var orderedConditions = []string{
+	sc.ConditionEq, sc.ConditionGt, sc.ConditionGte, sc.ConditionLt, sc.ConditionLte,
+	sc.ConditionIn, sc.ConditionBt, sc.ConditionBtExcl,
+}
+
+// reservedParamNames are the names of params that are not conditions on fields.
+var reservedParamNames = map[string]bool{
+	sc.LimitParamName:         true,
+	sc.OffsetParamName:        true,
+	sc.SortOrderParamName:     true,
+	sc.CursorParamName:        true,
+	sc.FieldsParamName:        true,
+	sc.DistinctParamName:      true,
+	sc.WithCountParamName:     true,
+	sc.CountOnlyParamName:     true,
+	sc.IncludeParamName:       true,
+	sc.ExpandParamName:        true,
+	sc.GroupByParamName:       true,
+	sc.AggregateParamName:     true,
+	sc.HavingParamName:        true,
+	sc.SearchParamName:        true,
+	sc.LogicAnd:               true,
+	sc.LogicOr:                true,
+	sc.Negation:               true,
+	sc.GraphQLFilterParamName: true,
+}
+
+type field struct {
+	Name   string
+	GoName string
+	Parse  string
+}
+
+// Keys returns the quoted names of the params of the conditions on the field.
+func (f field) Keys() string {
+	conditions := orderedConditions
+	if f.Parse == "Bool" {
+		conditions = []string{sc.ConditionEq}
+	}
+	keys := []string{strconv.Quote(f.Name)}
+	for _, condition := range conditions {
+		keys = append(keys, strconv.Quote(f.Name+sc.ConditionSeparator+condition))
+	}
+	return strings.Join(keys, ", ")
+}
+
+func main() {
+	typeName := flag.String("type", "", "name of the struct, required")
+	output := flag.String("output", "", "name of the generated file, default is <type in snake case>_selection.go")
+	tagName := flag.String("tag", sc.DefaultTagName, "struct tag of the names of fields in params")
+	flag.Parse()
+
+	if err := run(*typeName, *output, *tagName); err != nil {
+		fmt.Fprintln(os.Stderr, "scgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(typeName string, output string, tagName string) error {
+	if typeName == "" {
+		return errors.New("Flag -type is required")
+	}
+	if output == "" {
+		output = naming.SnakeCase(typeName) + "_selection.go"
+	}
+
+	pkgName, fields, err := loadStruct(typeName, tagName)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	err = generatedTemplate.Execute(&buf, map[string]interface{}{
+		"Package": pkgName,
+		"Type":    typeName,
+		"Prefix":  lowerFirst(typeName) + "Selection",
+		"Fields":  fields,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Generating code")
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "Formatting generated code")
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+// loadStruct returns the name of the package in the current directory and the fields of the struct
+// parsed by the generated code.
+func loadStruct(typeName string, tagName string) (string, []field, error) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		return "", nil, err
+	}
+
+	var pkgName string
+	var structType *ast.StructType
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, err
+		}
+		pkgName = f.Name.Name
+
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				// the rules of values of the provider are applied by sc.ParseQueryParams only
+				if d.Recv != nil && d.Name.Name == "FilterRules" && receiverName(d.Recv) == typeName {
+					return "", nil, errors.Errorf("Type %s provides filter rules, they are not supported", typeName)
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == typeName {
+						if structType, ok = ts.Type.(*ast.StructType); !ok {
+							return "", nil, errors.Errorf("Type %s is not a struct", typeName)
+						}
+					}
+				}
+			}
+		}
+	}
+	if structType == nil {
+		return "", nil, errors.Errorf("Type %s is not found", typeName)
+	}
+	return pkgName, structFields(structType, tagName), nil
+}
+
+// structFields returns the fields of the struct parsed by the generated code, a later field with the same name
+// replaces a former one like in parsing by reflection.
+func structFields(structType *ast.StructType, tagName string) []field {
+	var fields []field
+	index := make(map[string]int)
+	for _, f := range structType.Fields.List {
+		// embedded fields are parsed by sc.ParseQueryParams
+		if len(f.Names) == 0 {
+			continue
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			value, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				continue
+			}
+			tag = reflect.StructTag(value)
+		}
+		parse, ok := fieldParseFunc(f.Type, tag)
+
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			name, named := tagFieldName(tag, tagName, ident.Name)
+			if !named {
+				continue
+			}
+			// a field left to sc.ParseQueryParams hides a former field with the name too
+			fd := field{Name: name, GoName: ident.Name, Parse: parse}
+			if !ok || !isPlainParamName(name) {
+				fd.Parse = ""
+			}
+			if i, ok := index[name]; ok {
+				fields[i] = fd
+				continue
+			}
+			index[name] = len(fields)
+			fields = append(fields, fd)
+		}
+	}
+
+	res := fields[:0]
+	for _, f := range fields {
+		if f.Parse != "" {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// tagFieldName returns the name of the field in params, it returns false if the field is skipped by the tag "-".
+func tagFieldName(tag reflect.StructTag, tagName string, goName string) (string, bool) {
+	value := tag.Get(tagName)
+	if value == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(value, ",")
+	if name == "" {
+		name = goName
+	}
+	return name, true
+}
+
+// fieldParseFunc returns the suffix of the function parsing values of the field, it returns false if the field
+// is parsed by sc.ParseQueryParams.
+func fieldParseFunc(typ ast.Expr, tag reflect.StructTag) (string, bool) {
+	if _, ok := tag.Lookup(sc.EnumTagName); ok {
+		return "", false
+	}
+	for _, item := range strings.Split(tag.Get(sc.SelectionTagName), ",") {
+		key, _, _ := strings.Cut(strings.TrimSpace(item), "=")
+		if key == sc.SelectionConditions || key == sc.SelectionTransform {
+			return "", false
+		}
+	}
+
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	ident, ok := typ.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	parse, ok := parseFuncs[ident.Name]
+	return parse, ok
+}
+
+// isPlainParamName reports whether the name is of a field param that is not parsed as another param.
+func isPlainParamName(name string) bool {
+	return name != "" && !reservedParamNames[name] && !strings.Contains(name, sc.ConditionSeparator) &&
+		!strings.ContainsAny(name, sc.ValuesSeparator+sc.FieldPathSeparator+"[]$") &&
+		!strings.HasPrefix(name, sc.SortOrderDescPrefix)
+}
+
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	typ := recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+var generatedTemplate = template.Must(template.New("").Parse(`// Code generated by scgen -type {{.Type}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	sc "github.com/minipkg/selection_condition"
+)
+
+// Parse{{.Type}}Selection parses params like sc.ParseQueryParams(params, &{{.Type}}{}) without reflection,
+// the params it does not know are parsed by sc.ParseQueryParams.
+func Parse{{.Type}}Selection(params map[string][]string) (*sc.SelectionCondition, error) {
+	if cond, ok := parse{{.Type}}Selection(params); ok {
+		return cond, nil
+	}
+	return sc.ParseQueryParams(params, &{{.Type}}{})
+}
+
+// parse{{.Type}}Selection parses the params of the known fields, it returns false for other params and invalid values.
+func parse{{.Type}}Selection(params map[string][]string) (*sc.SelectionCondition, bool) {
+	// params are parsed in the order of their names like by sc.ParseQueryParams
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	cond := &sc.SelectionCondition{}
+	where := make(sc.WhereConditions, 0, len(params))
+	for _, key := range keys {
+		vals := params[key]
+		if len(vals) == 0 {
+			continue
+		}
+		if len(vals) > 1 {
+			return nil, false
+		}
+
+		var c sc.WhereCondition
+		var ok bool
+		switch key {
+		case sc.LimitParamName, sc.OffsetParamName:
+			n, err := strconv.ParseUint(vals[0], 10, 0)
+			if err != nil {
+				return nil, false
+			}
+			if key == sc.LimitParamName {
+				cond.Limit = uint(n)
+			} else {
+				cond.Offset = uint(n)
+			}
+			continue
+		case sc.SortOrderParamName:
+			if cond.SortOrder, ok = {{.Prefix}}SortOrder(vals[0]); !ok {
+				return nil, false
+			}
+			continue
+{{- range .Fields}}
+		case {{.Keys}}:
+{{- if eq .Parse "Bool"}}
+			c, ok = {{$.Prefix}}BoolValue("{{.GoName}}", vals[0])
+{{- else}}
+			c, ok = {{$.Prefix}}Value("{{.GoName}}", key, vals[0], {{$.Prefix}}{{.Parse}})
+{{- end}}
+{{- end}}
+		}
+		if !ok {
+			return nil, false
+		}
+		where = append(where, c)
+	}
+	cond.Where = where
+	return cond, true
+}
+
+// {{.Prefix}}SortOrder parses the sort order of the known fields without modifiers like "-created_at,id".
+func {{.Prefix}}SortOrder(value string) ([]sc.SortField, bool) {
+	items := strings.Split(value, sc.ValuesSeparator)
+	sortOrder := make([]sc.SortField, 0, len(items))
+	for _, item := range items {
+		name, desc := strings.CutPrefix(item, sc.SortOrderDescPrefix)
+		f := sc.SortField{Field: {{.Prefix}}GoName(name), Direction: sc.DefaultSortDirect}
+		if f.Field == "" {
+			return nil, false
+		}
+		if desc {
+			f.Direction = sc.SortOrderDesc
+		}
+		sortOrder = append(sortOrder, f)
+	}
+	return sortOrder, true
+}
+
+// {{.Prefix}}GoName returns the Go name of the known field by its name in params, it returns "" for others.
+func {{.Prefix}}GoName(name string) string {
+	switch name {
+{{- range .Fields}}
+	case "{{.Name}}":
+		return "{{.GoName}}"
+{{- end}}
+	}
+	return ""
+}
+
+// {{.Prefix}}Value parses the value of the condition in the key, half-open intervals are left to sc.ParseQueryParams.
+func {{.Prefix}}Value[T cmp.Ordered](field string, key string, value string, parse func(string) (T, error)) (sc.WhereCondition, bool) {
+	c := sc.WhereCondition{Field: field, Condition: sc.DefaultWhereCondition}
+	if _, condition, ok := strings.Cut(key, sc.ConditionSeparator); ok {
+		c.Condition = condition
+	}
+	if c.Condition != sc.ConditionIn && c.Condition != sc.ConditionBt && c.Condition != sc.ConditionBtExcl {
+		v, err := parse(value)
+		c.Value = v
+		return c, err == nil
+	}
+
+	items := strings.Split(value, sc.ValuesSeparator)
+	if c.Condition != sc.ConditionIn && (len(items) != 2 || items[0] == "" || items[1] == "") {
+		return c, false
+	}
+	values := make([]T, 0, len(items))
+	for _, item := range items {
+		v, err := parse(item)
+		if err != nil {
+			return c, false
+		}
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	list := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		list = append(list, v)
+	}
+	c.Value = list
+	return c, true
+}
+
+func {{.Prefix}}BoolValue(field string, value string) (sc.WhereCondition, bool) {
+	v, err := strconv.ParseBool(value)
+	return sc.WhereCondition{Field: field, Condition: sc.ConditionEq, Value: v}, err == nil
+}
+
+func {{.Prefix}}String(value string) (string, error) {
+	return value, nil
+}
+
+func {{.Prefix}}Int(value string) (int64, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func {{.Prefix}}Uint(value string) (uint64, error) {
+	return strconv.ParseUint(value, 10, 64)
+}
+
+func {{.Prefix}}Float(value string) (float64, error) {
+	return strconv.ParseFloat(value, 64)
+}
+`))
@@ -0,0 +1,233 @@
+package selection_condition
+
+// Contradiction is a set of where conditions on a field joined by AND that no value of the field satisfies.
+type Contradiction struct {
+	// Field is the path of Go names of the field
+	Field      string
+	Conditions []WhereCondition
+}
+
+// WithRejectContradictions makes parsing fail with ErrContradiction if the where conditions have contradictions
+// found by Contradictions, e.g. status=a&status__in=b,c.
+func WithRejectContradictions() Option {
+	return func(o *options) {
+		o.rejectContradictions = true
+	}
+}
+
+// Contradictions returns the contradictions of the where conditions like status eq "a" AND status eq "b"
+// or age gt 10 AND age lt 5 in the conditions joined by AND including the ones of nested groups. A group joined
+// by OR is contradictory if all its alternatives contradict the conditions joined with it by AND. Conditions eq
+// and in and the bounds of numbers and times are analyzed, strings are compared exactly. Negated groups
+// are not analyzed.
+func (e *SelectionCondition) Contradictions() []Contradiction {
+	if e == nil {
+		return nil
+	}
+	_, res := whereContradictions(e.Where)
+	return res
+}
+
+// Unsatisfiable reports whether no rows satisfy the where conditions by their contradictions, so a handler may
+// return an empty result without querying a database.
+func (e *SelectionCondition) Unsatisfiable() bool {
+	if e == nil {
+		return false
+	}
+	unsatisfiable, _ := whereContradictions(e.Where)
+	return unsatisfiable
+}
+
+// whereContradictions reports whether the where is unsatisfiable and returns the contradictions making it so.
+func whereContradictions(where interface{}) (bool, []Contradiction) {
+	switch w := where.(type) {
+	case WhereCondition:
+		return itemContradictions(w, nil)
+	case Where:
+		return groupContradictions(w.Group(), nil)
+	}
+	return false, nil
+}
+
+// itemContradictions analyzes the item with the inherited conditions joined with it by AND.
+func itemContradictions(item interface{}, inherited []WhereCondition) (bool, []Contradiction) {
+	switch c := item.(type) {
+	case WhereCondition:
+		conds := append(fieldConditions(inherited, simplifyFieldKey(c)), c)
+		if contradicts(conds) {
+			return true, []Contradiction{{Field: c.Field, Conditions: conds}}
+		}
+	case WhereConditionGroup:
+		return groupContradictions(c, inherited)
+	}
+	return false, nil
+}
+
+func groupContradictions(group WhereConditionGroup, inherited []WhereCondition) (bool, []Contradiction) {
+	if group.Not {
+		return false, nil
+	}
+
+	var res []Contradiction
+	if group.Logic == LogicOr {
+		// an empty group joined by OR selects nothing
+		for _, item := range group.Conditions {
+			unsatisfiable, contradictions := itemContradictions(item, inherited)
+			if !unsatisfiable {
+				return false, nil
+			}
+			res = append(res, contradictions...)
+		}
+		return true, res
+	}
+
+	// the conditions of the group are analyzed with the inherited ones and passed to the nested groups
+	conds := append([]WhereCondition(nil), inherited...)
+	var fields []string
+	own := make(map[string]bool)
+	for _, item := range group.Conditions {
+		if c, ok := item.(WhereCondition); ok {
+			key := simplifyFieldKey(c)
+			if !own[key] {
+				own[key] = true
+				fields = append(fields, key)
+			}
+			conds = append(conds, c)
+		}
+	}
+	for _, key := range fields {
+		if fieldConds := fieldConditions(conds, key); contradicts(fieldConds) {
+			res = append(res, Contradiction{Field: fieldConds[0].Field, Conditions: fieldConds})
+		}
+	}
+
+	unsatisfiable := len(res) > 0
+	for _, item := range group.Conditions {
+		if _, ok := item.(WhereConditionGroup); !ok {
+			continue
+		}
+		if nested, contradictions := itemContradictions(item, conds); nested {
+			unsatisfiable = true
+			res = append(res, contradictions...)
+		}
+	}
+	return unsatisfiable, res
+}
+
+// fieldConditions returns the conditions on the field by its key of simplifyFieldKey.
+func fieldConditions(conds []WhereCondition, key string) []WhereCondition {
+	var res []WhereCondition
+	for _, c := range conds {
+		if simplifyFieldKey(c) == key {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// bound is a lower or an upper bound of the values of a field.
+type bound struct {
+	value     interface{}
+	exclusive bool
+}
+
+// contradicts reports whether no value satisfies all the conditions on a field.
+func contradicts(conds []WhereCondition) bool {
+	// allowed are the only values satisfying eq and in if restricted
+	var allowed []interface{}
+	var restricted bool
+	var lower, upper *bound
+	for _, c := range conds {
+		switch c.Condition {
+		case ConditionEq:
+			// eq null is IS NULL
+			if c.Value != nil {
+				allowed, restricted = intersectValues(allowed, restricted, []interface{}{c.Value})
+			}
+		case ConditionIn:
+			if values, ok := conditionValues(c.Value); ok {
+				allowed, restricted = intersectValues(allowed, restricted, values)
+			}
+		case ConditionGt, ConditionGte:
+			lower = tighterBound(lower, &bound{value: c.Value, exclusive: c.Condition == ConditionGt}, 1)
+		case ConditionLt, ConditionLte:
+			upper = tighterBound(upper, &bound{value: c.Value, exclusive: c.Condition == ConditionLt}, -1)
+		case ConditionBt, ConditionBtExcl:
+			values, ok := conditionValues(c.Value)
+			if !ok || len(values) != 2 {
+				continue
+			}
+			exclusive := c.Condition == ConditionBtExcl
+			lower = tighterBound(lower, &bound{value: values[0], exclusive: exclusive}, 1)
+			upper = tighterBound(upper, &bound{value: values[1], exclusive: exclusive}, -1)
+		}
+	}
+
+	if restricted && len(allowed) == 0 {
+		return true
+	}
+	if lower != nil && upper != nil {
+		cmp, ok := compareConditionValues(lower.value, upper.value)
+		if ok && (cmp > 0 || (cmp == 0 && (lower.exclusive || upper.exclusive))) {
+			return true
+		}
+	}
+	if !restricted {
+		return false
+	}
+	for _, value := range allowed {
+		if withinBound(value, lower, 1) && withinBound(value, upper, -1) {
+			return false
+		}
+	}
+	return true
+}
+
+// intersectValues returns the values of both lists, values are the first list if it is not restricted.
+func intersectValues(allowed []interface{}, restricted bool, values []interface{}) ([]interface{}, bool) {
+	if !restricted {
+		return uniqueValues(values), true
+	}
+	res := make([]interface{}, 0, len(allowed))
+	for _, a := range allowed {
+		for _, v := range values {
+			if equalConditionValues(a, v) {
+				res = append(res, a)
+				break
+			}
+		}
+	}
+	return res, true
+}
+
+// tighterBound returns the tighter of the bounds of numbers and times, sign is 1 for lower bounds and -1
+// for upper ones. Other bounds are not analyzed.
+func tighterBound(current *bound, b *bound, sign int) *bound {
+	if !isOrderedBound(b.value) {
+		return current
+	}
+	if current == nil {
+		return b
+	}
+	cmp, ok := compareConditionValues(b.value, current.value)
+	if !ok {
+		return current
+	}
+	if cmp*sign > 0 || (cmp == 0 && b.exclusive) {
+		return b
+	}
+	return current
+}
+
+// withinBound reports whether the value satisfies the bound, sign is 1 for a lower bound and -1 for an upper one.
+// A value not comparable with the bound is taken as satisfying it.
+func withinBound(value interface{}, b *bound, sign int) bool {
+	if b == nil {
+		return true
+	}
+	cmp, ok := compareConditionValues(value, b.value)
+	if !ok {
+		return true
+	}
+	return cmp*sign > 0 || (cmp == 0 && !b.exclusive)
+}
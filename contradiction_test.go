@@ -0,0 +1,151 @@
+package selection_condition
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestContradictions(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	statusOpen := WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"}
+	statusNew := WhereCondition{Field: "Status", Condition: ConditionEq, Value: "new"}
+	amountGt := WhereCondition{Field: "Amount", Condition: ConditionGt, Value: 100.0}
+	amountLt := WhereCondition{Field: "Amount", Condition: ConditionLt, Value: 10.0}
+
+	tests := []struct {
+		name  string
+		where Where
+		// want are the fields of the contradictions, nil if the conditions are satisfiable
+		want []string
+	}{
+		{
+			name:  "nil",
+			where: nil,
+		},
+		{
+			name:  "different values of eq",
+			where: WhereConditions{statusOpen, statusNew},
+			want:  []string{"Status"},
+		},
+		{
+			name: "eq out of in",
+			where: WhereConditions{
+				statusOpen,
+				{Field: "Status", Condition: ConditionIn, Value: []interface{}{"new", "paid"}},
+			},
+			want: []string{"Status"},
+		},
+		{
+			name: "eq in in",
+			where: WhereConditions{
+				statusOpen,
+				{Field: "Status", Condition: ConditionIn, Value: []interface{}{"new", "open"}},
+			},
+		},
+		{
+			name:  "empty range",
+			where: WhereConditions{amountGt, amountLt},
+			want:  []string{"Amount"},
+		},
+		{
+			name: "exclusive bounds of a point",
+			where: WhereConditions{
+				{Field: "CreatedAt", Condition: ConditionGte, Value: day},
+				{Field: "CreatedAt", Condition: ConditionLt, Value: day},
+			},
+			want: []string{"CreatedAt"},
+		},
+		{
+			name: "inclusive bounds of a point",
+			where: WhereConditions{
+				{Field: "CreatedAt", Condition: ConditionGte, Value: day},
+				{Field: "CreatedAt", Condition: ConditionLte, Value: day},
+			},
+		},
+		{
+			name: "eq out of bt",
+			where: WhereConditions{
+				{Field: "Quantity", Condition: ConditionEq, Value: 10},
+				{Field: "Quantity", Condition: ConditionBt, Value: []interface{}{1, 5}},
+			},
+			want: []string{"Quantity"},
+		},
+		{
+			name: "nested group contradicting its parent",
+			where: WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{
+				statusOpen,
+				WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{statusNew}},
+			}},
+			want: []string{"Status"},
+		},
+		{
+			name: "all alternatives of or contradict",
+			where: WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{
+				statusOpen,
+				WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{statusNew, WhereCondition{Field: "Status", Condition: ConditionEq, Value: "paid"}}},
+			}},
+			want: []string{"Status", "Status"},
+		},
+		{
+			name: "an alternative of or is satisfiable",
+			where: WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{
+				statusOpen,
+				WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{statusNew, amountGt}},
+			}},
+		},
+		{
+			name: "negated group is not analyzed",
+			where: WhereConditionGroup{Logic: LogicAnd, Not: true, Conditions: []interface{}{
+				statusOpen,
+				statusNew,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := &SelectionCondition{Where: tt.where}
+			var fields []string
+			for _, c := range cond.Contradictions() {
+				fields = append(fields, c.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.want) {
+				t.Errorf("got contradictions on %v, want %v", fields, tt.want)
+			}
+			if got := cond.Unsatisfiable(); got != (tt.want != nil) {
+				t.Errorf("got unsatisfiable %v, want %v", got, tt.want != nil)
+			}
+		})
+	}
+}
+
+func TestWithRejectContradictions(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string][]string
+		err    error
+	}{
+		{
+			name:   "satisfiable",
+			params: map[string][]string{"status__in": {"new,open"}, "amount__gt": {"10"}},
+		},
+		{
+			name:   "eq out of in",
+			params: map[string][]string{"status": {"a"}, "status__in": {"b,c"}},
+			err:    ErrContradiction,
+		},
+		{
+			name:   "in a group",
+			params: map[string][]string{"amount__gt": {"100"}, "and": {"(amount__lt=10)"}},
+			err:    ErrContradiction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQueryParams(tt.params, &testOrder{}, WithRejectContradictions())
+			checkError(t, err, tt.err)
+		})
+	}
+}
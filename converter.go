@@ -0,0 +1,69 @@
+package selection_condition
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Converter parses a value of a param to a value of a field of the type it is registered for.
+type Converter func(strValue string) (interface{}, error)
+
+var (
+	convertersMu sync.RWMutex
+	// converters are the converters by the types of values of fields
+	converters = map[reflect.Type]Converter{}
+)
+
+// RegisterConverter registers the converter of values of fields of the type typ, so fields of any type
+// may be filtered, e.g.
+//
+//	sc.RegisterConverter(reflect.TypeOf(decimal.Decimal{}), func(s string) (interface{}, error) {
+//		return decimal.NewFromString(s)
+//	})
+//
+// A converter goes before the parsing of the package, a struct type having one is not a nested struct of paths.
+// It replaces a converter registered for the type before.
+func RegisterConverter(typ reflect.Type, converter Converter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters[typ] = converter
+}
+
+func converterByType(typ reflect.Type) (Converter, bool) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	converter, ok := converters[typ]
+	return converter, ok
+}
+
+// convert parses the value by the converter of the type, it returns false if there is no converter.
+func convert(strValue string, typ reflect.Type) (interface{}, bool, error) {
+	converter, ok := converterByType(typ)
+	if !ok {
+		return nil, false, nil
+	}
+	value, err := converter(strValue)
+	if err != nil {
+		return nil, true, err
+	}
+	return value, true, nil
+}
+
+// setConverted sets the value parsed by a converter to v converting it to the type of v if it is needed.
+func setConverted(v reflect.Value, value interface{}) error {
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if !val.Type().AssignableTo(v.Type()) {
+		if !val.Type().ConvertibleTo(v.Type()) {
+			return errors.Errorf("Converter of type %s returned a value of type %s", v.Type(), val.Type())
+		}
+		val = val.Convert(v.Type())
+	}
+	v.Set(val)
+	return nil
+}
@@ -0,0 +1,30 @@
+package selection_condition
+
+const (
+	// WithCountParamName is the param requesting the total number of the rows along with a page of them: with_count=true
+	WithCountParamName = "with_count"
+	// CountOnlyParamName is the param requesting the total number of the rows without the rows: count_only=true
+	CountOnlyParamName = "count_only"
+)
+
+// ForCount returns the condition of the query counting the rows selected by the condition: without the sort order,
+// the pagination and the relations, the projection is kept only for distinct rows.
+func (e *SelectionCondition) ForCount() *SelectionCondition {
+	res := e.Clone()
+	res.SortOrder = nil
+	res.Limit = 0
+	res.Offset = 0
+	res.Include = nil
+	res.WithCount = false
+	res.CountOnly = false
+	if !res.Distinct {
+		res.Fields = nil
+	}
+	return res
+}
+
+// IsGrouped reports whether rows of the condition are groups or distinct rows, so they are counted
+// by a subquery like SELECT COUNT(*) FROM (...) AS t.
+func (e *SelectionCondition) IsGrouped() bool {
+	return len(e.GroupBy) > 0 || len(e.Aggregates) > 0 || e.Distinct
+}
@@ -0,0 +1,277 @@
+package selection_condition
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const CursorParamName = "cursor"
+
+// cursorKey is a value of a sort field of the last row of a page.
+type cursorKey struct {
+	Field  string `json:"f"`
+	Direct string `json:"d"`
+	Value  string `json:"v"`
+}
+
+// NextCursor returns an opaque token for the page following the one with lastRow as its last row.
+// The token holds the values of the sort fields of lastRow, so cond must have a sort order and
+// the sort fields should identify a row uniquely, e.g. the last one should be the primary key.
+// The sort fields must have no modifiers of the placement of nulls and of the case-insensitive order.
+func NextCursor(cond *SelectionCondition, lastRow interface{}) (string, error) {
+	if cond == nil || len(cond.SortOrder) == 0 {
+		return "", errors.New("Cursor requires a sort order")
+	}
+
+	rowVal := reflect.Indirect(reflect.ValueOf(lastRow))
+	if rowVal.Kind() != reflect.Struct {
+		return "", fmt.Errorf("Parameter lastRow must be a struct or a pointer on a struct")
+	}
+
+	keys := make([]cursorKey, 0, len(cond.SortOrder))
+	for _, sortField := range cond.SortOrder {
+		if err := checkKeysetSortField(sortField); err != nil {
+			return "", err
+		}
+		fieldVal, ok := fieldValueByPath(rowVal, sortField.Field)
+		if !ok {
+			return "", errors.Errorf("Field %s not found in %s", sortField.Field, rowVal.Type())
+		}
+		fieldVal, ok = nonNullValue(fieldVal)
+		if !ok {
+			return "", errors.Errorf("Field %s of the last row is null", sortField.Field)
+		}
+		// the direction of a sort field made by hand may be empty for asc
+		direction := sortField.Direction
+		if direction == "" {
+			direction = SortOrderAsc
+		}
+		keys = append(keys, cursorKey{
+			Field:  sortField.Field,
+			Direct: direction,
+			Value:  val2string(fieldVal.Interface()),
+		})
+	}
+
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// nonNullValue dereferences pointers and unwraps database/sql Null* values, it returns false for a null value.
+func nonNullValue(v reflect.Value) (reflect.Value, bool) {
+	for {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+			continue
+		}
+		if _, ok := nullableValueType(v.Type()); ok {
+			if !v.Field(1).Bool() {
+				return v, false
+			}
+			v = v.Field(0)
+			continue
+		}
+		return v, true
+	}
+}
+
+func val2string(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+func decodeCursor(token string) ([]cursorKey, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid cursor")
+	}
+
+	var keys []cursorKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, errors.Wrap(err, "Invalid cursor")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("Invalid cursor: no keys")
+	}
+	return keys, nil
+}
+
+// applyCursor adds to conditions the where conditions selecting rows after the cursor.
+// The sort order of conditions is taken from the cursor if it is not set, otherwise they must be equal.
+// A client may make a token, so its fields are checked by the rules of the sort order and of the filters.
+func applyCursor(conditions *SelectionCondition, s *Schema, token string) (*WhereCondition, *WhereConditionGroup, error) {
+	keys, err := decodeCursor(token)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range keys {
+		if key.Direct != SortOrderAsc && key.Direct != SortOrderDesc {
+			return nil, nil, errors.Errorf("Invalid cursor: direction %q of field %s", key.Direct, key.Field)
+		}
+	}
+
+	if len(conditions.SortOrder) == 0 {
+		conditions.SortOrder = make([]SortField, 0, len(keys))
+		for _, key := range keys {
+			if f, ok := s.fieldByPath(key.Field); s.o.strictSort && (!ok || !f.sortable) {
+				return nil, nil, errors.Errorf("Invalid cursor: field %s is not sortable", key.Field)
+			}
+			conditions.SortOrder = append(conditions.SortOrder, SortField{Field: key.Field, Direction: key.Direct})
+		}
+	}
+	if !cursorMatchesSortOrder(keys, conditions.SortOrder) {
+		return nil, nil, errors.New("Cursor does not match the sort order")
+	}
+	for _, sortField := range conditions.SortOrder {
+		if err := checkKeysetSortField(sortField); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	conds := make([]WhereCondition, 0, len(keys))
+	for i, key := range keys {
+		f, ok := s.fieldByPath(key.Field)
+		if !ok {
+			return nil, nil, errors.Errorf("Invalid cursor: unknown field %s", key.Field)
+		}
+		if err := checkCursorField(s, key, f, i < len(keys)-1); err != nil {
+			return nil, nil, err
+		}
+		value, err := string2val(key.Value, f.typ, s.o)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Invalid cursor")
+		}
+
+		conds = append(conds, keysetCondition(key.Field, key.Direct, value))
+	}
+
+	if len(conds) == 1 {
+		return &conds[0], nil, nil
+	}
+	return nil, keysetGroup(conds), nil
+}
+
+// checkCursorField checks that the field of the key may be filtered by the conditions of the keyset,
+// the ones of the fields before the last one are compared by eq too.
+func checkCursorField(s *Schema, key cursorKey, f *schemaField, compared bool) error {
+	if s.o.strictFilters && !f.filterable {
+		return errors.Errorf("Invalid cursor: field %s is not filterable", key.Field)
+	}
+	conditions := []string{keysetCondition(key.Field, key.Direct, nil).Condition}
+	if compared {
+		conditions = append(conditions, ConditionEq)
+	}
+	for _, condition := range conditions {
+		if !s.isConditionAllowed(key.Field, f, condition) {
+			return errors.Errorf("Invalid cursor: condition %q is not allowed for field %s", condition, key.Field)
+		}
+	}
+	return nil
+}
+
+// KeysetCondition returns the where conditions selecting the rows following the last row of a page in the sort order
+// for the seek pagination, lastValues are the values of the sort fields of the last row by their paths of Go names.
+// The conditions are the lexicographic comparison of the fields by their directions, e.g. for "a,-b" it is
+// a > x OR (a = x AND b < y), the sort fields should identify a row uniquely and must have no modifiers
+// of the placement of nulls and of the case-insensitive order.
+func KeysetCondition(sortOrder []SortField, lastValues map[string]interface{}) (Where, error) {
+	if len(sortOrder) == 0 {
+		return nil, errors.New("Keyset pagination requires a sort order")
+	}
+
+	conds := make([]WhereCondition, 0, len(sortOrder))
+	for _, sortField := range sortOrder {
+		if err := checkKeysetSortField(sortField); err != nil {
+			return nil, err
+		}
+		value, ok := lastValues[sortField.Field]
+		if !ok || value == nil {
+			return nil, errors.Errorf("Value of sort field %s of the last row is missing", sortField.Field)
+		}
+		conds = append(conds, keysetCondition(sortField.Field, sortField.Direction, value))
+	}
+
+	if len(conds) == 1 {
+		return WhereConditions(conds), nil
+	}
+	return *keysetGroup(conds), nil
+}
+
+// checkKeysetSortField returns an error for the modifiers of the sort field, the keyset conditions compare values
+// as they are and select no nulls, so they would not follow the order with the modifiers.
+func checkKeysetSortField(sortField SortField) error {
+	if sortField.Nulls != "" || sortField.CaseInsensitive {
+		return errors.Errorf("Keyset pagination does not support the modifiers of sort field %s", sortField.Field)
+	}
+	return nil
+}
+
+// keysetCondition returns the condition selecting the values of the field following the value in the direction.
+func keysetCondition(field string, direction string, value interface{}) WhereCondition {
+	condition := ConditionGt
+	if direction == SortOrderDesc {
+		condition = ConditionLt
+	}
+	return WhereCondition{
+		Field:     field,
+		Condition: condition,
+		Value:     value,
+	}
+}
+
+// keysetGroup returns the group for the lexicographic comparison of the fields of conds,
+// e.g. for (a > x, b > y) it is (a > x) OR (a = x AND b > y).
+func keysetGroup(conds []WhereCondition) *WhereConditionGroup {
+	group := &WhereConditionGroup{
+		Logic:      LogicOr,
+		Conditions: make([]interface{}, 0, len(conds)),
+	}
+
+	for i, cond := range conds {
+		if i == 0 {
+			group.Conditions = append(group.Conditions, cond)
+			continue
+		}
+
+		and := WhereConditionGroup{
+			Logic:      LogicAnd,
+			Conditions: make([]interface{}, 0, i+1),
+		}
+		for _, prev := range conds[:i] {
+			and.Conditions = append(and.Conditions, WhereCondition{
+				Field:     prev.Field,
+				Condition: ConditionEq,
+				Value:     prev.Value,
+			})
+		}
+		and.Conditions = append(and.Conditions, cond)
+		group.Conditions = append(group.Conditions, and)
+	}
+	return group
+}
+
+func cursorMatchesSortOrder(keys []cursorKey, sortOrder []SortField) bool {
+	if len(keys) != len(sortOrder) {
+		return false
+	}
+
+	for i, key := range keys {
+		if sortOrder[i].Field != key.Field || sortOrder[i].Direction != key.Direct {
+			return false
+		}
+	}
+	return true
+}
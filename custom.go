@@ -0,0 +1,119 @@
+package selection_condition
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// CustomCondition is a condition registered by an application, e.g. within_fiscal_year:
+//
+//	err := sc.RegisterCondition(sc.CustomCondition{
+//		Name:  "within_fiscal_year",
+//		Arity: 1,
+//		Expand: func(field string, value interface{}) (sc.WhereCondition, error) {
+//			year := value.(int64)
+//			return sc.WhereCondition{Field: field, Condition: sc.ConditionBtExcl, Value: fiscalYearBounds(year)}, nil
+//		},
+//	})
+type CustomCondition struct {
+	// Name is the name of the condition in params: created_at__within_fiscal_year=2024
+	Name string
+	// Arity is the number of the values separated by the values separator, 0 means any number.
+	// The value of a condition of one value is the value itself, otherwise it is a list.
+	Arity int
+	// Parse parses the values for a field of the type typ, by default each of them is parsed as a value of the field.
+	Parse func(values []string, typ reflect.Type) (interface{}, error)
+	// Validate validates the parsed value, it is optional.
+	Validate func(value interface{}) error
+	// Expand returns the condition replacing the one on the field by its path of Go names with the parsed value,
+	// e.g. bt of the dates of a fiscal year, so the adapters can build it. If it is nil the condition is kept
+	// as it is and the adapters reject it.
+	Expand func(field string, value interface{}) (WhereCondition, error)
+}
+
+var conditionNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+var (
+	customConditionsMu sync.RWMutex
+	customConditions   = map[string]CustomCondition{}
+)
+
+// RegisterCondition registers the custom condition, its name must not be the one of a condition
+// of the package or of a condition registered before.
+func RegisterCondition(condition CustomCondition) error {
+	if !conditionNameRegexp.MatchString(condition.Name) {
+		return errors.Errorf("Name %q of a condition must be in lower case letters, digits and underscores", condition.Name)
+	}
+	if condition.Arity < 0 {
+		return errors.Errorf("Arity of condition %q must not be negative", condition.Name)
+	}
+	for _, variant := range ConditionVariants {
+		if variant == condition.Name {
+			return errors.Errorf("Condition %q is already defined", condition.Name)
+		}
+	}
+
+	customConditionsMu.Lock()
+	defer customConditionsMu.Unlock()
+	if _, ok := customConditions[condition.Name]; ok {
+		return errors.Errorf("Condition %q is already registered", condition.Name)
+	}
+	customConditions[condition.Name] = condition
+	return nil
+}
+
+func customConditionByName(name string) (CustomCondition, bool) {
+	customConditionsMu.RLock()
+	defer customConditionsMu.RUnlock()
+	condition, ok := customConditions[name]
+	return condition, ok
+}
+
+// conditionVariants returns the names of the conditions of the package and of the registered ones.
+func conditionVariants() []interface{} {
+	customConditionsMu.RLock()
+	defer customConditionsMu.RUnlock()
+	if len(customConditions) == 0 {
+		return ConditionVariants
+	}
+	variants := make([]interface{}, 0, len(ConditionVariants)+len(customConditions))
+	variants = append(variants, ConditionVariants...)
+	for name := range customConditions {
+		variants = append(variants, name)
+	}
+	return variants
+}
+
+// parse parses the value of the condition for a field of the type typ and validates it.
+func (c CustomCondition) parse(strValue string, typ reflect.Type, o *options) (interface{}, error) {
+	values := []string{strValue}
+	if c.Arity != 1 {
+		values = strings.Split(strValue, o.valuesSeparator)
+	}
+	if c.Arity > 0 && len(values) != c.Arity {
+		return nil, errors.Errorf("Condition %q requires %d values, got %d", c.Name, c.Arity, len(values))
+	}
+	if c.Arity == 0 && o.maxListValues > 0 && uint(len(values)) > o.maxListValues {
+		return nil, errors.Wrapf(ErrTooManyValues, "Condition %q accepts at most %d values, got %d", c.Name, o.maxListValues, len(values))
+	}
+
+	if c.Parse != nil {
+		return c.Parse(values, typ)
+	}
+	parsed := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		value, err := string2val(v, typ, o)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, value)
+	}
+	if c.Arity == 1 {
+		return parsed[0], nil
+	}
+	return parsed, nil
+}
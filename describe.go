@@ -0,0 +1,205 @@
+package selection_condition
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the values of conditions on sensitive fields in descriptions.
+const Redacted = "***"
+
+var (
+	sensitiveFieldsMu sync.RWMutex
+	// sensitiveFields are the paths and the Go names of the fields redacted by String
+	sensitiveFields = map[string]bool{}
+)
+
+// RegisterSensitiveFields registers the fields whose values String redacts by their paths of Go names like "Author.Email"
+// or by their Go names like "Email" for the fields of the name in any struct.
+func RegisterSensitiveFields(fields ...string) {
+	sensitiveFieldsMu.Lock()
+	defer sensitiveFieldsMu.Unlock()
+	for _, field := range fields {
+		sensitiveFields[field] = true
+	}
+}
+
+// String returns the description of the condition for logs with the values of the fields registered
+// by RegisterSensitiveFields redacted, see Describe.
+func (e *SelectionCondition) String() string {
+	sensitiveFieldsMu.RLock()
+	fields := make([]string, 0, len(sensitiveFields))
+	for field := range sensitiveFields {
+		fields = append(fields, field)
+	}
+	sensitiveFieldsMu.RUnlock()
+	return e.Describe(fields...)
+}
+
+// Describe returns the description of the condition for logs with the values of the conditions and of the having
+// conditions on the redacted fields replaced by Redacted, the fields are set by their paths of Go names or by their Go names, e.g.
+//
+//	where Age gte 18 and (Email eq *** or Name ilike "smith") sort CreatedAt desc limit 20 offset 40
+//	select Country, Amount distinct group by Country agg count(*), sum(Amount) having sum(Amount) gt 100
+func (e *SelectionCondition) Describe(redactedFields ...string) string {
+	if e == nil {
+		return "<nil>"
+	}
+	redacted := make(map[string]bool, len(redactedFields))
+	for _, field := range redactedFields {
+		redacted[field] = true
+	}
+
+	var parts []string
+	if len(e.Fields) > 0 {
+		parts = append(parts, "select "+strings.Join(e.Fields, ", "))
+	}
+	if e.Distinct {
+		parts = append(parts, "distinct")
+	}
+	if where := describeWhere(e.Where, redacted, true); where != "" {
+		parts = append(parts, "where "+where)
+	}
+	if len(e.Include) > 0 {
+		parts = append(parts, "include "+strings.Join(e.Include, ", "))
+	}
+	if len(e.GroupBy) > 0 {
+		parts = append(parts, "group by "+strings.Join(e.GroupBy, ", "))
+	}
+	if len(e.Aggregates) > 0 {
+		items := make([]string, 0, len(e.Aggregates))
+		for _, a := range e.Aggregates {
+			items = append(items, describeAggregate(a))
+		}
+		parts = append(parts, "agg "+strings.Join(items, ", "))
+	}
+	if len(e.Having) > 0 {
+		items := make([]string, 0, len(e.Having))
+		for _, h := range e.Having {
+			items = append(items, describeHaving(h, redacted))
+		}
+		parts = append(parts, "having "+strings.Join(items, " and "))
+	}
+	if len(e.SortOrder) > 0 {
+		items := make([]string, 0, len(e.SortOrder))
+		for _, f := range e.SortOrder {
+			item := f.Field + " " + f.Direction
+			if f.Nulls != "" {
+				item += " nulls " + f.Nulls
+			}
+			if f.CaseInsensitive {
+				item += " ci"
+			}
+			items = append(items, item)
+		}
+		parts = append(parts, "sort "+strings.Join(items, ", "))
+	}
+	if e.Limit > 0 {
+		parts = append(parts, "limit "+strconv.FormatUint(uint64(e.Limit), 10))
+	}
+	if e.Offset > 0 {
+		parts = append(parts, "offset "+strconv.FormatUint(uint64(e.Offset), 10))
+	}
+	if e.CountOnly {
+		parts = append(parts, "count only")
+	} else if e.WithCount {
+		parts = append(parts, "with count")
+	}
+	if len(parts) == 0 {
+		return "all"
+	}
+	return strings.Join(parts, " ")
+}
+
+// describeWhere returns the description of the where conditions, a group on the top is not enclosed in parentheses.
+func describeWhere(where interface{}, redacted map[string]bool, top bool) string {
+	var logic string
+	var items []interface{}
+	var not bool
+	switch w := where.(type) {
+	case nil:
+		return ""
+	case WhereCondition:
+		return describeCondition(w, redacted)
+	case WhereConditions:
+		logic = LogicAnd
+		for _, c := range w {
+			items = append(items, c)
+		}
+	case WhereConditionGroup:
+		logic, items, not = w.Logic, w.Conditions, w.Not
+	default:
+		return fmt.Sprintf("%v", where)
+	}
+
+	descriptions := make([]string, 0, len(items))
+	for _, item := range items {
+		if d := describeWhere(item, redacted, false); d != "" {
+			descriptions = append(descriptions, d)
+		}
+	}
+	if len(descriptions) == 0 {
+		return ""
+	}
+	res := strings.Join(descriptions, " "+logic+" ")
+	if not {
+		return "not (" + res + ")"
+	}
+	if !top && len(descriptions) > 1 {
+		return "(" + res + ")"
+	}
+	return res
+}
+
+func describeCondition(c WhereCondition, redacted map[string]bool) string {
+	field := c.Field
+	if c.JSONPath != "" {
+		field += "->" + c.JSONPath
+	}
+	if isRedacted(c.Field, redacted) {
+		return field + " " + c.Condition + " " + Redacted
+	}
+	return field + " " + c.Condition + " " + describeValue(c.Value)
+}
+
+// describeAggregate returns the function of the aggregate with its field, count of the rows is count(*).
+func describeAggregate(a Aggregate) string {
+	if a.Field == "" {
+		return a.Func + "(*)"
+	}
+	return a.Func + "(" + a.Field + ")"
+}
+
+func describeHaving(h HavingCondition, redacted map[string]bool) string {
+	if isRedacted(h.Field, redacted) {
+		return describeAggregate(h.Aggregate) + " " + h.Condition + " " + Redacted
+	}
+	return describeAggregate(h.Aggregate) + " " + h.Condition + " " + describeValue(h.Value)
+}
+
+// isRedacted reports whether the field is redacted by its path of Go names or by its Go name.
+func isRedacted(field string, redacted map[string]bool) bool {
+	goName := field[strings.LastIndex(field, FieldPathSeparator)+1:]
+	return redacted[field] || redacted[goName]
+}
+
+// describeValue returns the value with strings quoted and lists in brackets.
+func describeValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case Fuzzy, GeoNear, GeoBoundingBox:
+		return encodeValue(v)
+	}
+	if v := reflect.ValueOf(value); (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && !isUUIDType(v.Type()) {
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, describeValue(v.Index(i).Interface()))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return encodeValue(value)
+}
@@ -0,0 +1,376 @@
+package selection_condition
+
+import (
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EncodeQuery returns the query params which are parsed to the condition by the fields of the struct pointed by model,
+// e.g. to build links to the next page or without a filter. The options must be the ones of parsing.
+// Conditions on dates are encoded as the between conditions they are parsed to.
+func (e *SelectionCondition) EncodeQuery(model interface{}, opts ...Option) (url.Values, error) {
+	schema, err := NewParser(opts...).Schema(model)
+	if err != nil {
+		return nil, err
+	}
+	return schema.EncodeQuery(e)
+}
+
+// EncodeQuery returns the query params which are parsed to the condition by the schema.
+func (s *Schema) EncodeQuery(conditions *SelectionCondition) (url.Values, error) {
+	params, err := encodeQuery(s, conditions)
+	if err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	return params, nil
+}
+
+func encodeQuery(s *Schema, conditions *SelectionCondition) (url.Values, error) {
+	o := s.o
+	params := make(url.Values)
+
+	// the forced conditions are joined again by parsing and conditions on their fields are not allowed in params
+	if err := encodeWhere(s, params, withoutForcedConditions(conditions.Where, o, conditions.forcedFields)); err != nil {
+		return nil, err
+	}
+
+	if len(conditions.SortOrder) > 0 {
+		items := make([]string, 0, len(conditions.SortOrder))
+		for _, sortField := range conditions.SortOrder {
+			name, ok := s.paramNameByPath(sortField.Field)
+			if !ok {
+				return nil, newParamError(ErrUnknownField, sortField.Field, "Unknown field %s", sortField.Field)
+			}
+			var modifiers []string
+			if sortField.Direction == SortOrderDesc {
+				modifiers = append(modifiers, SortOrderDesc)
+			}
+			switch sortField.Nulls {
+			case NullsFirst:
+				modifiers = append(modifiers, SortNullsFirst)
+			case NullsLast:
+				modifiers = append(modifiers, SortNullsLast)
+			}
+			if sortField.CaseInsensitive {
+				modifiers = append(modifiers, SortCaseInsensitive)
+			}
+			if len(modifiers) > 0 {
+				name += o.conditionSeparator + strings.Join(modifiers, SortModifierSeparator)
+			}
+			items = append(items, name)
+		}
+		params.Set(SortOrderParamName, strings.Join(items, o.valuesSeparator))
+	}
+
+	if len(conditions.Fields) > 0 {
+		names := make([]string, 0, len(conditions.Fields))
+		for _, field := range conditions.Fields {
+			name, ok := s.paramNameByPath(field)
+			if !ok {
+				return nil, newParamError(ErrUnknownField, field, "Unknown field %s", field)
+			}
+			names = append(names, name)
+		}
+		params.Set(FieldsParamName, strings.Join(names, o.valuesSeparator))
+	}
+
+	if conditions.Distinct {
+		params.Set(DistinctParamName, strconv.FormatBool(true))
+	}
+
+	if conditions.WithCount {
+		params.Set(WithCountParamName, strconv.FormatBool(true))
+	}
+	if conditions.CountOnly {
+		params.Set(CountOnlyParamName, strconv.FormatBool(true))
+	}
+
+	if len(conditions.Include) > 0 {
+		names := make([]string, 0, len(conditions.Include))
+		for _, relation := range conditions.Include {
+			name, ok := s.paramNameByPath(relation)
+			if !ok {
+				return nil, newParamError(ErrUnknownRelation, relation, "Unknown relation %s", relation)
+			}
+			names = append(names, name)
+		}
+		params.Set(IncludeParamName, strings.Join(names, o.valuesSeparator))
+	}
+
+	if len(conditions.GroupBy) > 0 {
+		names := make([]string, 0, len(conditions.GroupBy))
+		for _, field := range conditions.GroupBy {
+			name, ok := s.paramNameByPath(field)
+			if !ok {
+				return nil, newParamError(ErrUnknownField, field, "Unknown field %s", field)
+			}
+			names = append(names, name)
+		}
+		params.Set(GroupByParamName, strings.Join(names, o.valuesSeparator))
+	}
+	if len(conditions.Aggregates) > 0 {
+		items := make([]string, 0, len(conditions.Aggregates))
+		for _, aggregate := range conditions.Aggregates {
+			if aggregate.Field == "" {
+				items = append(items, aggregate.Func)
+				continue
+			}
+			name, ok := s.paramNameByPath(aggregate.Field)
+			if !ok {
+				return nil, newParamError(ErrUnknownField, aggregate.Field, "Unknown field %s", aggregate.Field)
+			}
+			items = append(items, aggregate.Func+AggregateFieldSeparator+name)
+		}
+		params.Set(AggregateParamName, strings.Join(items, o.valuesSeparator))
+	}
+	if len(conditions.Having) > 0 {
+		items := make([]string, 0, len(conditions.Having))
+		for _, c := range conditions.Having {
+			key := c.Func
+			if c.Field != "" {
+				name, ok := s.paramNameByPath(c.Field)
+				if !ok {
+					return nil, newParamError(ErrUnknownField, c.Field, "Unknown field %s", c.Field)
+				}
+				key += AggregateFieldSeparator + name
+			}
+			value, err := encodeConditionValue(s, WhereCondition{Field: key, Condition: c.Condition, Value: c.Value}, true)
+			if err != nil {
+				return nil, err
+			}
+			if c.Condition != DefaultWhereCondition {
+				key += o.conditionSeparator + c.Condition
+			}
+			items = append(items, key+"="+value)
+		}
+		params.Set(HavingParamName, GroupOpening+strings.Join(items, GroupItemsSeparator)+GroupClosing)
+	}
+
+	if o.pagination == PaginationPage {
+		if conditions.Limit == 0 && conditions.Offset == 0 {
+			return params, nil
+		}
+		if conditions.Limit == 0 || conditions.Offset%conditions.Limit != 0 {
+			return nil, newParamError(ErrInvalidPagination, o.pageParamName, "Offset %d is not a multiple of limit %d", conditions.Offset, conditions.Limit)
+		}
+		params.Set(o.perPageParamName, strconv.FormatUint(uint64(conditions.Limit), 10))
+		params.Set(o.pageParamName, strconv.FormatUint(uint64(conditions.Offset/conditions.Limit+1), 10))
+		return params, nil
+	}
+	if conditions.Limit > 0 {
+		params.Set(o.limitParamName, strconv.FormatUint(uint64(conditions.Limit), 10))
+	}
+	if conditions.Offset > 0 {
+		params.Set(o.offsetParamName, strconv.FormatUint(uint64(conditions.Offset), 10))
+	}
+	return params, nil
+}
+
+// encodeWhere adds the params of where, the conditions joined by AND are separate params
+// unless there are several conditions on the same field and operator.
+func encodeWhere(s *Schema, params url.Values, where interface{}) error {
+	var items []interface{}
+	switch w := where.(type) {
+	case nil:
+		return nil
+	case WhereConditions:
+		for _, c := range w {
+			items = append(items, c)
+		}
+	case WhereCondition:
+		items = append(items, w)
+	case WhereConditionGroup:
+		if w.Logic != LogicAnd || w.Not {
+			return encodeGroupParam(s, params, w)
+		}
+		items = w.Conditions
+	default:
+		return errors.Errorf("Where must be WhereConditions or a WhereConditionGroup, got %T", where)
+	}
+
+	var rest []interface{}
+	for _, item := range items {
+		switch c := item.(type) {
+		case WhereCondition:
+			key, value, err := encodeCondition(s, c, false)
+			if err != nil {
+				return err
+			}
+			if params.Has(key) {
+				rest = append(rest, c)
+				continue
+			}
+			params.Set(key, value)
+		case WhereConditionGroup:
+			if err := encodeGroupParam(s, params, c); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("Condition of a group must be a WhereCondition or a WhereConditionGroup, got %T", item)
+		}
+	}
+
+	if len(rest) > 0 {
+		return encodeGroupParam(s, params, WhereConditionGroup{Logic: LogicAnd, Conditions: rest})
+	}
+	return nil
+}
+
+// encodeGroupParam adds the param of the group: and=(...), or=(...) or not=(...).
+func encodeGroupParam(s *Schema, params url.Values, group WhereConditionGroup) error {
+	key, items, err := encodeGroup(s, group)
+	if err != nil {
+		return err
+	}
+	params.Add(key, items)
+	return nil
+}
+
+// encodeGroup returns the logic operator or the negation of the group and its items enclosed in parentheses.
+func encodeGroup(s *Schema, group WhereConditionGroup) (string, string, error) {
+	if group.Not && group.Logic != LogicAnd {
+		// the negation joins its items by AND, so the group is inside: not=(or(...))
+		inner := group
+		inner.Not = false
+		key, items, err := encodeGroup(s, inner)
+		if err != nil {
+			return "", "", err
+		}
+		return Negation, GroupOpening + key + items + GroupClosing, nil
+	}
+
+	items := make([]string, 0, len(group.Conditions))
+	for _, item := range group.Conditions {
+		switch c := item.(type) {
+		case WhereCondition:
+			key, value, err := encodeCondition(s, c, true)
+			if err != nil {
+				return "", "", err
+			}
+			items = append(items, key+"="+value)
+		case WhereConditionGroup:
+			key, subitems, err := encodeGroup(s, c)
+			if err != nil {
+				return "", "", err
+			}
+			items = append(items, key+subitems)
+		default:
+			return "", "", errors.Errorf("Condition of a group must be a WhereCondition or a WhereConditionGroup, got %T", item)
+		}
+	}
+
+	key := group.Logic
+	if group.Not {
+		key = Negation
+	}
+	return key, GroupOpening + strings.Join(items, GroupItemsSeparator) + GroupClosing, nil
+}
+
+// encodeCondition returns the name and the value of the param of the condition.
+func encodeCondition(s *Schema, c WhereCondition, inGroup bool) (string, string, error) {
+	o := s.o
+	key, ok := s.paramNameByPath(c.Field)
+	if !ok {
+		return "", "", newParamError(ErrUnknownField, c.Field, "Unknown field %s", c.Field)
+	}
+	if c.JSONPath != "" {
+		key += FieldPathSeparator + c.JSONPath
+	}
+	if c.Condition != DefaultWhereCondition {
+		key += o.conditionSeparator + c.Condition
+	}
+
+	value, err := encodeConditionValue(s, c, inGroup)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// encodeConditionValue returns the value of the param of the condition, a value inside a group
+// is enclosed in parentheses if it contains a separator of items.
+func encodeConditionValue(s *Schema, c WhereCondition, inGroup bool) (string, error) {
+	o := s.o
+	var value string
+	switch {
+	case isListCondition(c.Condition):
+		v := reflect.ValueOf(c.Value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return "", errors.Errorf("Value of condition %s of field %s must be a list, got %T", c.Condition, c.Field, c.Value)
+		}
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			str := encodeValue(v.Index(i).Interface())
+			if strings.Contains(str, o.valuesSeparator) {
+				return "", errors.Errorf("Value %q of field %s cannot be in a list as it contains %q", str, c.Field, o.valuesSeparator)
+			}
+			values = append(values, str)
+		}
+		value = strings.Join(values, o.valuesSeparator)
+	case c.Condition == ConditionNear:
+		near, ok := c.Value.(GeoNear)
+		if !ok {
+			return "", errors.Errorf("Value of condition %s of field %s must be a GeoNear, got %T", c.Condition, c.Field, c.Value)
+		}
+		value = near.format(o.valuesSeparator)
+	case c.Condition == ConditionBBox:
+		box, ok := c.Value.(GeoBoundingBox)
+		if !ok {
+			return "", errors.Errorf("Value of condition %s of field %s must be a GeoBoundingBox, got %T", c.Condition, c.Field, c.Value)
+		}
+		value = box.format(o.valuesSeparator)
+	case c.Condition == ConditionJSONContains:
+		data, err := json.Marshal(c.Value)
+		if err != nil {
+			return "", errors.Wrapf(err, "Value of condition %s of field %s", c.Condition, c.Field)
+		}
+		value = string(data)
+	default:
+		value = encodeValue(c.Value)
+	}
+
+	if inGroup {
+		if strings.Count(value, GroupOpening) != strings.Count(value, GroupClosing) {
+			return "", errors.Errorf("Value %q of field %s cannot be in a group as its parentheses are unbalanced", value, c.Field)
+		}
+		if strings.ContainsAny(value, GroupItemsSeparator+GroupOpening+GroupClosing) {
+			value = GroupOpening + value + GroupClosing
+		}
+	}
+	return value, nil
+}
+
+// encodeValue returns the value as it is in a param.
+func encodeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case encoding.TextMarshaler:
+		if text, err := v.MarshalText(); err == nil {
+			return string(text)
+		}
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	if v := reflect.ValueOf(value); isUUIDType(v.Type()) {
+		b := make([]byte, 16)
+		reflect.Copy(reflect.ValueOf(b), v)
+		h := hex.EncodeToString(b)
+		return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+	}
+	return fmt.Sprint(value)
+}
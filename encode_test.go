@@ -0,0 +1,136 @@
+package selection_condition
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeQueryRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string][]string
+		opts   []Option
+	}{
+		{
+			name:   "empty",
+			params: map[string][]string{},
+		},
+		{
+			name: "conditions, sort and page",
+			params: map[string][]string{
+				"status__in":  {"new,open"},
+				"amount__gte": {"10.5"},
+				"paid":        {"true"},
+				"author.name": {"john"},
+				"sort_order":  {"created_at__desc_nullslast,author.name__ci,id"},
+				"limit":       {"20"},
+				"offset":      {"40"},
+			},
+		},
+		{
+			name:   "times and dates",
+			params: map[string][]string{"created_at__gt": {"2024-01-01T10:00:00Z"}, "created_at__lt": {"2024-02-01"}},
+		},
+		{
+			name: "groups",
+			params: map[string][]string{
+				"or":  {"(status=open,and(status=new,amount__gt=10))"},
+				"not": {"(author.name=(Smith, John),paid=true)"},
+			},
+		},
+		{
+			name:   "values with separators",
+			params: map[string][]string{"author.name": {"Smith, John"}, "or": {"(status=a(b),status=x=y)"}},
+		},
+		{
+			name:   "projection and count",
+			params: map[string][]string{"fields": {"id,status"}, "distinct": {"true"}, "with_count": {"true"}},
+		},
+		{
+			name:   "grouping",
+			params: map[string][]string{"group_by": {"status"}, "agg": {"count,sum:amount"}, "having": {"(count__gte=5,sum:amount__lt=100.5)"}},
+		},
+		{
+			name:   "forced conditions",
+			params: map[string][]string{"status": {"open"}},
+			opts:   []Option{WithForcedConditions(WhereCondition{Field: "Paid", Condition: ConditionEq, Value: true})},
+		},
+		{
+			name:   "OData",
+			params: map[string][]string{"$filter": {"status eq 'open' or amount gt 10"}, "$top": {"20"}},
+			opts:   []Option{WithOData()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithTimeLocation(time.UTC)}, tt.opts...)
+			cond, err := ParseQueryParams(tt.params, &testOrder{}, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			params, err := cond.EncodeQuery(&testOrder{}, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseQueryParams(params, &testOrder{}, opts...)
+			if err != nil {
+				t.Fatalf("encoded params %v: %v", params, err)
+			}
+			if !got.Equal(cond) {
+				t.Errorf("encoded params %v are parsed to %s, want %s", params, got.normalizedJSON(), cond.normalizedJSON())
+			}
+		})
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		cond *SelectionCondition
+		want url.Values
+		err  error
+	}{
+		{
+			name: "conditions",
+			cond: &SelectionCondition{
+				Where: WhereConditions{
+					{Field: "Status", Condition: ConditionEq, Value: "open"},
+					{Field: "Amount", Condition: ConditionBt, Value: []interface{}{10.0, 100.0}},
+					{Field: "Author.Name", Condition: ConditionIn, Value: []interface{}{"a", "b"}},
+				},
+				SortOrder: []SortField{{Field: "CreatedAt", Direction: SortOrderDesc}},
+				Limit:     20,
+			},
+			want: url.Values{
+				"status":           {"open"},
+				"amount__bt":       {"10,100"},
+				"author.name__in":  {"a,b"},
+				SortOrderParamName: {"created_at__desc"},
+				"limit":            {"20"},
+			},
+		},
+		{
+			name: "unknown field",
+			cond: &SelectionCondition{Where: WhereConditions{{Field: "Unknown", Condition: ConditionEq, Value: 1}}},
+			err:  ErrUnknownField,
+		},
+		{
+			name: "unknown sort field",
+			cond: &SelectionCondition{SortOrder: []SortField{{Field: "Unknown", Direction: SortOrderAsc}}},
+			err:  ErrUnknownField,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cond.EncodeQuery(&testOrder{})
+			checkError(t, err, tt.err)
+			if tt.err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
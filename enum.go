@@ -0,0 +1,60 @@
+package selection_condition
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// EnumTagName is the struct tag listing the only values of the field in conditions, e.g.
+//
+//	Status string `json:"status" enum:"draft,published,archived"`
+const EnumTagName = "enum"
+
+const enumTagSeparator = ","
+
+// tagEnum returns the only values of the field in conditions by its enum tag, nil means any.
+func tagEnum(field reflect.StructField) []string {
+	tag, ok := field.Tag.Lookup(EnumTagName)
+	if !ok {
+		return nil
+	}
+	values := strings.Split(tag, enumTagSeparator)
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+	return values
+}
+
+// isEnumCondition reports whether the values of the condition must be the ones of the enum of the field,
+// other conditions like gt or ilike take any values.
+func isEnumCondition(condition string) bool {
+	switch condition {
+	case ConditionEq, ConditionIEq, ConditionIn, ConditionAny, ConditionAll:
+		return true
+	}
+	return false
+}
+
+// checkEnum checks the value of the condition and each value of a list against the enum.
+func checkEnum(enum []string, condition string, value interface{}) error {
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+	for _, v := range values {
+		str := encodeValue(v)
+		var found bool
+		for _, item := range enum {
+			if item == str || (condition == ConditionIEq && strings.EqualFold(item, str)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.Errorf("Value %q must be one of %s", str, strings.Join(enum, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package selection_condition
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Errors of parsing, a returned error is checked by errors.Is(err, ErrUnknownField),
+// the name of the param is got by errors.As with a *ParamError or a *ErrBadValue.
+var (
+	ErrUnknownParam      = errors.New("unknown parameter")
+	ErrUnknownField      = errors.New("unknown field")
+	ErrInvalidParam      = errors.New("invalid parameter")
+	ErrInvalidOperator   = errors.New("invalid operator")
+	ErrNotFilterable     = errors.New("field is not filterable")
+	ErrNotSortable       = errors.New("field is not sortable")
+	ErrRequiredFilter    = errors.New("required filter is missing")
+	ErrTooManyConditions = errors.New("too many conditions")
+	ErrTooManyValues     = errors.New("too many values")
+	ErrInvalidGroup      = errors.New("invalid group")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidPagination = errors.New("invalid pagination")
+	ErrInvalidStruct     = errors.New("invalid struct")
+	ErrInvalidFilter     = errors.New("invalid filter")
+	ErrConflict          = errors.New("conflicting conditions")
+	ErrUnknownRelation   = errors.New("unknown relation")
+	ErrContradiction     = errors.New("contradictory conditions")
+)
+
+// ErrorCode is a machine-readable code of a parse error, e.g. to choose a translation of its message.
+type ErrorCode string
+
+const (
+	CodeUnknownParam      ErrorCode = "unknown_param"
+	CodeUnknownField      ErrorCode = "unknown_field"
+	CodeInvalidParam      ErrorCode = "invalid_param"
+	CodeInvalidOperator   ErrorCode = "invalid_operator"
+	CodeNotFilterable     ErrorCode = "not_filterable"
+	CodeNotSortable       ErrorCode = "not_sortable"
+	CodeRequiredFilter    ErrorCode = "required_filter"
+	CodeTooManyConditions ErrorCode = "too_many_conditions"
+	CodeTooManyValues     ErrorCode = "too_many_values"
+	CodeInvalidGroup      ErrorCode = "invalid_group"
+	CodeInvalidCursor     ErrorCode = "invalid_cursor"
+	CodeInvalidPagination ErrorCode = "invalid_pagination"
+	CodeInvalidStruct     ErrorCode = "invalid_struct"
+	CodeInvalidFilter     ErrorCode = "invalid_filter"
+	CodeConflict          ErrorCode = "conflict"
+	CodeUnknownRelation   ErrorCode = "unknown_relation"
+	CodeContradiction     ErrorCode = "contradiction"
+	CodeBadValue          ErrorCode = "bad_value"
+)
+
+var errorCodes = map[error]ErrorCode{
+	ErrUnknownParam:      CodeUnknownParam,
+	ErrUnknownField:      CodeUnknownField,
+	ErrInvalidParam:      CodeInvalidParam,
+	ErrInvalidOperator:   CodeInvalidOperator,
+	ErrNotFilterable:     CodeNotFilterable,
+	ErrNotSortable:       CodeNotSortable,
+	ErrRequiredFilter:    CodeRequiredFilter,
+	ErrTooManyConditions: CodeTooManyConditions,
+	ErrTooManyValues:     CodeTooManyValues,
+	ErrInvalidGroup:      CodeInvalidGroup,
+	ErrInvalidCursor:     CodeInvalidCursor,
+	ErrInvalidPagination: CodeInvalidPagination,
+	ErrInvalidStruct:     CodeInvalidStruct,
+	ErrInvalidFilter:     CodeInvalidFilter,
+	ErrConflict:          CodeConflict,
+	ErrUnknownRelation:   CodeUnknownRelation,
+	ErrContradiction:     CodeContradiction,
+}
+
+// CodedError is a parse error with a machine-readable code, it is a *ParamError or a *ErrBadValue.
+type CodedError interface {
+	error
+	Code() ErrorCode
+}
+
+// Translator returns the message of err in another language, an empty message keeps the default English one.
+// The message is built from the code and the fields of err, e.g.
+//
+//	func(err sc.CodedError) string {
+//		switch e := err.(type) {
+//		case *sc.ParamError:
+//			if e.Code() == sc.CodeUnknownField {
+//				return fmt.Sprintf("Unbekanntes Feld %s", e.Param)
+//			}
+//		case *sc.ErrBadValue:
+//			return fmt.Sprintf("Ungültiger Wert %q für %s", e.Raw, e.Field)
+//		}
+//		return ""
+//	}
+type Translator func(err CodedError) string
+
+// ParamError is an error of the param, Err is one of the Err* errors.
+type ParamError struct {
+	Param string
+	Err   error
+	// Args are the values in the default message, e.g. the condition or the maximum exceeded
+	Args []interface{}
+	msg  string
+}
+
+func newParamError(err error, param string, format string, args ...interface{}) *ParamError {
+	return &ParamError{
+		Param: param,
+		Err:   err,
+		Args:  args,
+		msg:   fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *ParamError) Error() string {
+	return e.msg
+}
+
+func (e *ParamError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ParamError) Code() ErrorCode {
+	return errorCodes[e.Err]
+}
+
+// ErrBadValue is an error of a value which cannot be parsed as the type of the field.
+type ErrBadValue struct {
+	// Field is the name of the param
+	Field string
+	// Raw is the value as it is in the param
+	Raw string
+	// Kind is the Go type the value is parsed as, e.g. int or time.Time
+	Kind string
+	Err  error
+	msg  string
+}
+
+func (e *ErrBadValue) Code() ErrorCode {
+	return CodeBadValue
+}
+
+func (e *ErrBadValue) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	if e.Err == nil {
+		return fmt.Sprintf("Invalid value %q of parameter %s", e.Raw, e.Field)
+	}
+	return fmt.Sprintf("Invalid value %q of parameter %s: %s", e.Raw, e.Field, e.Err)
+}
+
+func (e *ErrBadValue) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the list of all the errors of parsing made with WithAllErrors.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// errorCollector collects errors of parsing if all of them are to be returned.
+type errorCollector struct {
+	all  bool
+	errs Errors
+}
+
+// add returns false if parsing must stop on err.
+func (c *errorCollector) add(err error) bool {
+	if !c.all {
+		return false
+	}
+	c.errs = append(c.errs, err)
+	return true
+}
+
+func (c *errorCollector) err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+// translateError sets the messages of err and of the errors listed in it by the translator.
+func translateError(err error, translator Translator) error {
+	if translator == nil {
+		return err
+	}
+
+	switch e := err.(type) {
+	case Errors:
+		for _, item := range e {
+			translateError(item, translator)
+		}
+	case *ParamError:
+		if msg := translator(e); msg != "" {
+			e.msg = msg
+		}
+	case *ErrBadValue:
+		if msg := translator(e); msg != "" {
+			e.msg = msg
+		}
+	}
+	return err
+}
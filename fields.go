@@ -0,0 +1,34 @@
+package selection_condition
+
+import "strings"
+
+const (
+	// FieldsParamName is the param of the projection, the fields to be returned: fields=id,name,created_at
+	FieldsParamName = "fields"
+	// DistinctParamName is the param removing duplicates of the rows of the projection: fields=country&distinct=true
+	DistinctParamName = "distinct"
+)
+
+// parseFieldsParam parses the projection to the paths of Go names of the fields, a field listed twice is taken once.
+func parseFieldsParam(s *Schema, vals []string) ([]string, error) {
+	items := strings.Split(vals[0], s.o.valuesSeparator)
+	fields := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+
+	for _, item := range items {
+		paramName := strings.TrimSpace(item)
+		if paramName == "" {
+			return nil, newParamError(ErrInvalidParam, FieldsParamName, "Empty field in parameter %s", FieldsParamName)
+		}
+		fieldName, _, ok := s.fieldByParamName(paramName)
+		if !ok {
+			return nil, newParamError(ErrUnknownField, paramName, "Unknown field %s", paramName)
+		}
+		if seen[fieldName] {
+			continue
+		}
+		seen[fieldName] = true
+		fields = append(fields, fieldName)
+	}
+	return fields, nil
+}
@@ -0,0 +1,132 @@
+package selection_condition
+
+import "context"
+
+// ForcedConditionsFunc returns the conditions forced by the server for the request with ctx,
+// e.g. the condition on the tenant of the user authenticated by a previous middleware.
+type ForcedConditionsFunc func(ctx context.Context) ([]WhereCondition, error)
+
+// WithForcedConditions adds the conditions which are always joined by AND with the ones of the params and of filters
+// like the ones of AIP-160, RSQL and GraphQL, fields of the conditions are set by their paths of Go names. Params
+// with conditions on these fields make parsing fail, so a client can neither override nor remove them.
+// EncodeQuery leaves the forced conditions out as parsing joins them again.
+func WithForcedConditions(conditions ...WhereCondition) Option {
+	return func(o *options) {
+		o.forcedConditions = append(o.forcedConditions, conditions...)
+	}
+}
+
+// WithForcedConditionsFunc adds the conditions returned by fn for the context of parsing like WithForcedConditions,
+// the context is the one of the request in ParseRequest and Middleware and the one passed to the Context variants
+// of parsing like ParseContext and ParseRSQLContext, e.g.
+//
+//	sc.WithForcedConditionsFunc(func(ctx context.Context) ([]sc.WhereCondition, error) {
+//		tenantID, ok := auth.TenantID(ctx)
+//		if !ok {
+//			return nil, errors.New("no tenant")
+//		}
+//		return []sc.WhereCondition{{Field: "TenantID", Condition: sc.ConditionEq, Value: tenantID}}, nil
+//	})
+func WithForcedConditionsFunc(fn ForcedConditionsFunc) Option {
+	return func(o *options) {
+		o.forcedConditionsFuncs = append(o.forcedConditionsFuncs, fn)
+	}
+}
+
+// forcedConditions returns the conditions forced by the options for ctx.
+func forcedConditions(ctx context.Context, o *options) ([]WhereCondition, error) {
+	if len(o.forcedConditionsFuncs) == 0 {
+		return o.forcedConditions, nil
+	}
+
+	conditions := append([]WhereCondition{}, o.forcedConditions...)
+	for _, fn := range o.forcedConditionsFuncs {
+		forced, err := fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, forced...)
+	}
+	return conditions, nil
+}
+
+// usesField reports whether there is a condition on the field at any depth of the groups.
+func usesField(whereConditions []WhereCondition, whereGroups []WhereConditionGroup, field string) bool {
+	for _, whereCondition := range whereConditions {
+		if whereCondition.Field == field {
+			return true
+		}
+	}
+
+	for _, group := range whereGroups {
+		var conds []WhereCondition
+		var groups []WhereConditionGroup
+		for _, item := range group.Conditions {
+			switch c := item.(type) {
+			case WhereCondition:
+				conds = append(conds, c)
+			case WhereConditionGroup:
+				groups = append(groups, c)
+			}
+		}
+		if usesField(conds, groups, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// setForcedFields keeps the fields of the forced conditions, so they are left out by encoding.
+func (e *SelectionCondition) setForcedFields(forced []WhereCondition) {
+	if len(forced) == 0 {
+		return
+	}
+	e.forcedFields = make([]string, 0, len(forced))
+	for _, c := range forced {
+		e.forcedFields = append(e.forcedFields, c.Field)
+	}
+}
+
+// withoutForcedConditions returns where without the conditions on the forced fields, which are joined by AND
+// at its top level, the forced fields are the ones of the options and the ones kept by parsing.
+func withoutForcedConditions(where Where, o *options, forcedFields []string) Where {
+	fields := make(map[string]bool, len(o.forcedConditions)+len(forcedFields))
+	for _, c := range o.forcedConditions {
+		fields[c.Field] = true
+	}
+	for _, field := range forcedFields {
+		fields[field] = true
+	}
+	if len(fields) == 0 {
+		return where
+	}
+
+	switch w := where.(type) {
+	case WhereConditions:
+		res := make(WhereConditions, 0, len(w))
+		for _, c := range w {
+			if !fields[c.Field] {
+				res = append(res, c)
+			}
+		}
+		return res
+	case WhereCondition:
+		if fields[w.Field] {
+			return WhereConditions{}
+		}
+	case WhereConditionGroup:
+		if w.Logic != LogicAnd || w.Not {
+			return where
+		}
+		items := make([]interface{}, 0, len(w.Conditions))
+		for _, item := range w.Conditions {
+			if c, ok := item.(WhereCondition); ok && fields[c.Field] {
+				continue
+			}
+			items = append(items, item)
+		}
+		w.Conditions = items
+		return w
+	}
+	return where
+}
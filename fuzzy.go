@@ -0,0 +1,88 @@
+package selection_condition
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConditionFuzzy matches text fields similar to the value, the similarity threshold from 0 to 1
+	// may follow the value: name__fuzzy=jonh or name__fuzzy=jonh~0.5
+	ConditionFuzzy = "fuzzy"
+	// FuzzySimilaritySeparator separates the value of fuzzy from its similarity threshold.
+	FuzzySimilaritySeparator = "~"
+	// DefaultFuzzySimilarity is the similarity threshold of fuzzy by default, the one of the Postgres pg_trgm extension.
+	DefaultFuzzySimilarity = 0.3
+)
+
+// Fuzzy is the value of the condition fuzzy, Similarity is the threshold of the trigram similarity of text
+// and a value of the field, e.g. similarity(name, 'jonh') >= 0.3 in Postgres.
+type Fuzzy struct {
+	Text       string  `json:"text"`
+	Similarity float64 `json:"similarity"`
+}
+
+// String returns the value as it is in a param.
+func (f Fuzzy) String() string {
+	return f.Text + FuzzySimilaritySeparator + strconv.FormatFloat(f.Similarity, 'f', -1, 64)
+}
+
+// Fuzziness returns the maximum number of edits of a word of the text for the fuzzy queries of Elasticsearch,
+// the share of characters which may differ by the similarity, up to 2.
+func (f Fuzzy) Fuzziness() int {
+	edits := int((1 - f.Similarity) * float64(len([]rune(f.Text))))
+	return min(max(edits, 0), 2)
+}
+
+// parseFuzzyValue parses the value of fuzzy with an optional similarity threshold, text ending with
+// the separator and something else than a number is taken as a whole.
+func parseFuzzyValue(strValue string) (Fuzzy, error) {
+	fuzzy := Fuzzy{Text: strValue, Similarity: DefaultFuzzySimilarity}
+	if i := strings.LastIndex(strValue, FuzzySimilaritySeparator); i >= 0 {
+		if similarity, err := strconv.ParseFloat(strValue[i+len(FuzzySimilaritySeparator):], 64); err == nil {
+			if similarity <= 0 || similarity > 1 {
+				return Fuzzy{}, errors.Errorf("Similarity of condition %q must be greater than 0 and at most 1, got %v", ConditionFuzzy, similarity)
+			}
+			fuzzy.Text, fuzzy.Similarity = strValue[:i], similarity
+		}
+	}
+	if strings.TrimSpace(fuzzy.Text) == "" {
+		return Fuzzy{}, errors.Errorf("Condition %q requires a text", ConditionFuzzy)
+	}
+	return fuzzy, nil
+}
+
+// trigramSimilarity returns the similarity of two texts as the pg_trgm extension does it: the number of the trigrams
+// shared by them divided by the number of all their trigrams.
+func trigramSimilarity(a string, b string) float64 {
+	trigramsA, trigramsB := trigrams(a), trigrams(b)
+	if len(trigramsA) == 0 || len(trigramsB) == 0 {
+		return 0
+	}
+	var shared int
+	for t := range trigramsA {
+		if trigramsB[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(trigramsA)+len(trigramsB)-shared)
+}
+
+// trigrams returns the trigrams of the words of the text in lower case, a word is prefixed by two spaces
+// and suffixed by one.
+func trigrams(text string) map[string]bool {
+	res := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			res[string(runes[i:i+3])] = true
+		}
+	}
+	return res
+}
@@ -0,0 +1,230 @@
+package selection_condition
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConditionNear matches geo fields within the radius of the point set by its latitude and longitude,
+	// the radius is in meters, kilometers or miles: location__near=55.75,37.61,5km
+	ConditionNear = "near"
+	// ConditionBBox matches geo fields within the bounding box set by its south-west and north-east corners:
+	// location__bbox=55.7,37.5,55.8,37.7 as minLat,minLon,maxLat,maxLon
+	ConditionBBox = "bbox"
+)
+
+const (
+	DistanceMeters     = "m"
+	DistanceKilometers = "km"
+	DistanceMiles      = "mi"
+
+	// earthRadius is the mean radius of the Earth in meters
+	earthRadius = 6371008.8
+)
+
+var distanceUnits = map[string]float64{
+	DistanceMeters:     1,
+	DistanceKilometers: 1000,
+	DistanceMiles:      1609.344,
+}
+
+// GeoPoint is a point by its latitude and longitude in degrees.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// GeoNear is the value of the condition near, Radius is in meters.
+type GeoNear struct {
+	Point  GeoPoint `json:"point"`
+	Radius float64  `json:"radius"`
+}
+
+// String returns the value as it is in a param.
+func (n GeoNear) String() string {
+	return n.format(ValuesSeparator)
+}
+
+func (n GeoNear) format(separator string) string {
+	return formatFloat(n.Point.Lat) + separator + formatFloat(n.Point.Lon) + separator + formatFloat(n.Radius) + DistanceMeters
+}
+
+// RadiusRadians returns the radius as the angle at the center of the Earth, e.g. for $centerSphere of MongoDB.
+func (n GeoNear) RadiusRadians() float64 {
+	return n.Radius / earthRadius
+}
+
+// GeoBoundingBox is the value of the condition bbox, Min is the south-west corner and Max is the north-east one.
+type GeoBoundingBox struct {
+	Min GeoPoint `json:"min"`
+	Max GeoPoint `json:"max"`
+}
+
+// String returns the value as it is in a param.
+func (b GeoBoundingBox) String() string {
+	return b.format(ValuesSeparator)
+}
+
+func (b GeoBoundingBox) format(separator string) string {
+	return strings.Join([]string{formatFloat(b.Min.Lat), formatFloat(b.Min.Lon), formatFloat(b.Max.Lat), formatFloat(b.Max.Lon)}, separator)
+}
+
+// Contains reports whether the point is within the box, the edges included.
+func (b GeoBoundingBox) Contains(p GeoPoint) bool {
+	return b.Min.Lat <= p.Lat && p.Lat <= b.Max.Lat && b.Min.Lon <= p.Lon && p.Lon <= b.Max.Lon
+}
+
+// isGeoCondition reports whether the condition is for geo fields.
+func isGeoCondition(condition string) bool {
+	return condition == ConditionNear || condition == ConditionBBox
+}
+
+// isGeoType reports whether a field of type typ may hold a geo value, that is anything but booleans, numbers and times.
+func isGeoType(typ reflect.Type) bool {
+	if typ == timeType || isUUIDType(typ) || isNumericType(typ) {
+		return false
+	}
+	return typ.Kind() != reflect.Bool
+}
+
+// parseGeoNearValue parses the latitude, the longitude and the radius with an optional unit, meters by default.
+func parseGeoNearValue(strValue string, o *options) (GeoNear, error) {
+	items := strings.Split(strValue, o.valuesSeparator)
+	if len(items) != 3 {
+		return GeoNear{}, errors.Errorf("Condition %q requires a latitude, a longitude and a radius, got %d values", ConditionNear, len(items))
+	}
+	point, err := parseGeoPoint(items[0], items[1])
+	if err != nil {
+		return GeoNear{}, err
+	}
+
+	radius := strings.TrimSpace(items[2])
+	number, unit := radius, DistanceMeters
+	// meters go last as kilometers end with m too
+	for _, u := range []string{DistanceKilometers, DistanceMiles, DistanceMeters} {
+		if n, ok := strings.CutSuffix(radius, u); ok {
+			number, unit = n, u
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value <= 0 || math.IsInf(value, 0) {
+		return GeoNear{}, errors.Errorf("Radius %q must be a positive number with an optional unit %s, %s or %s", radius, DistanceMeters, DistanceKilometers, DistanceMiles)
+	}
+	return GeoNear{Point: point, Radius: value * distanceUnits[unit]}, nil
+}
+
+// parseGeoBoundingBoxValue parses the corners of the box as minLat,minLon,maxLat,maxLon, a box crossing
+// the antimeridian is not supported.
+func parseGeoBoundingBoxValue(strValue string, o *options) (GeoBoundingBox, error) {
+	items := strings.Split(strValue, o.valuesSeparator)
+	if len(items) != 4 {
+		return GeoBoundingBox{}, errors.Errorf("Condition %q requires minLat,minLon,maxLat,maxLon, got %d values", ConditionBBox, len(items))
+	}
+	southWest, err := parseGeoPoint(items[0], items[1])
+	if err != nil {
+		return GeoBoundingBox{}, err
+	}
+	northEast, err := parseGeoPoint(items[2], items[3])
+	if err != nil {
+		return GeoBoundingBox{}, err
+	}
+	if southWest.Lat > northEast.Lat || southWest.Lon > northEast.Lon {
+		return GeoBoundingBox{}, errors.Errorf("Minimum latitude and longitude of condition %q must not exceed the maximum ones", ConditionBBox)
+	}
+	return GeoBoundingBox{Min: southWest, Max: northEast}, nil
+}
+
+// parseGeoPoint parses the latitude and the longitude in degrees.
+func parseGeoPoint(lat string, lon string) (GeoPoint, error) {
+	latValue, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil || latValue < -90 || latValue > 90 {
+		return GeoPoint{}, errors.Errorf("Latitude %q must be a number from -90 to 90", lat)
+	}
+	lonValue, err := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+	if err != nil || lonValue < -180 || lonValue > 180 {
+		return GeoPoint{}, errors.Errorf("Longitude %q must be a number from -180 to 180", lon)
+	}
+	return GeoPoint{Lat: latValue, Lon: lonValue}, nil
+}
+
+// geoPointOf returns the point of a value of a geo field: a GeoPoint, a struct with the fields Lat or Latitude
+// and Lon, Lng or Longitude or a pair of numbers in the order of GeoJSON, the longitude first.
+func geoPointOf(v reflect.Value) (GeoPoint, bool) {
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return GeoPoint{}, false
+	}
+	if p, ok := v.Interface().(GeoPoint); ok {
+		return p, true
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		lat, ok := floatFieldByNames(v, "Lat", "Latitude")
+		if !ok {
+			return GeoPoint{}, false
+		}
+		lon, ok := floatFieldByNames(v, "Lon", "Lng", "Longitude")
+		if !ok {
+			return GeoPoint{}, false
+		}
+		return GeoPoint{Lat: lat, Lon: lon}, true
+	case reflect.Slice, reflect.Array:
+		if v.Len() != 2 || !v.Index(0).CanFloat() {
+			return GeoPoint{}, false
+		}
+		return GeoPoint{Lat: v.Index(1).Float(), Lon: v.Index(0).Float()}, true
+	}
+	return GeoPoint{}, false
+}
+
+// isGeoPointType reports whether geoPointOf gets a point from values of the type, e.g. to list the geo conditions
+// only for the fields of points in a description of params.
+func isGeoPointType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Struct:
+		return hasFloatField(typ, "Lat", "Latitude") && hasFloatField(typ, "Lon", "Lng", "Longitude")
+	case reflect.Slice, reflect.Array:
+		kind := typ.Elem().Kind()
+		return (typ.Kind() == reflect.Slice || typ.Len() == 2) &&
+			(kind == reflect.Float32 || kind == reflect.Float64)
+	}
+	return false
+}
+
+func hasFloatField(typ reflect.Type, names ...string) bool {
+	for _, name := range names {
+		if f, ok := typ.FieldByName(name); ok && (f.Type.Kind() == reflect.Float32 || f.Type.Kind() == reflect.Float64) {
+			return true
+		}
+	}
+	return false
+}
+
+func floatFieldByNames(v reflect.Value, names ...string) (float64, bool) {
+	for _, name := range names {
+		if f := v.FieldByName(name); f.IsValid() && f.CanFloat() {
+			return f.Float(), true
+		}
+	}
+	return 0, false
+}
+
+// distance returns the great-circle distance between the points in meters by the haversine formula.
+func distance(a GeoPoint, b GeoPoint) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
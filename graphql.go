@@ -0,0 +1,250 @@
+package selection_condition
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GraphQLFilterParamName is the argument of a filter in GraphQL, it is the name of the param in errors.
+const GraphQLFilterParamName = "where"
+
+const (
+	GraphQLAnd = "_and"
+	GraphQLOr  = "_or"
+	GraphQLNot = "_not"
+)
+
+// graphQLOperators maps comparison operators of a GraphQL filter to conditions, "_neq" is the negated eq
+// and "_nin" is the negated in.
+var graphQLOperators = map[string]string{
+	"_eq":  ConditionEq,
+	"_neq": ConditionEq,
+	"_gt":  ConditionGt,
+	"_gte": ConditionGte,
+	"_lt":  ConditionLt,
+	"_lte": ConditionLte,
+	"_in":  ConditionIn,
+	"_nin": ConditionIn,
+}
+
+// ParseGraphQLFilter converts the filter input of GraphQL in the style of Hasura by the fields of the struct
+// pointed by struc, e.g. the variables of a resolver decoded from
+//
+//	{"_or": [{"status": {"_eq": "open"}}, {"age": {"_gte": 18}}], "author": {"name": {"_in": ["a", "b"]}}}
+//
+// Fields of a nested struct are set by nested objects. Items of an object are joined by AND, "_not" negates
+// its object. It returns the condition with Where only, Where is WhereConditions if all conditions are
+// joined by AND otherwise it is a WhereConditionGroup.
+func ParseGraphQLFilter(filter map[string]interface{}, struc interface{}, opts ...Option) (*SelectionCondition, error) {
+	schema, err := NewParser(opts...).Schema(struc)
+	if err != nil {
+		return nil, err
+	}
+	return schema.ParseGraphQLFilter(filter)
+}
+
+// ParseGraphQLFilter converts the filter input of GraphQL by the fields of the schema.
+func (s *Schema) ParseGraphQLFilter(filter map[string]interface{}) (*SelectionCondition, error) {
+	return s.ParseGraphQLFilterContext(context.Background(), filter)
+}
+
+// ParseGraphQLFilterContext is ParseGraphQLFilter with ctx passed to the functions set by WithForcedConditionsFunc.
+func (s *Schema) ParseGraphQLFilterContext(ctx context.Context, filter map[string]interface{}) (*SelectionCondition, error) {
+	conditions, err := parseGraphQLFilter(s, filter)
+	if err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	errs := &errorCollector{all: s.o.allErrors}
+	if err := checkWhere(ctx, s, conditions, errs); err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	if err := errs.err(); err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	return conditions, nil
+}
+
+func parseGraphQLFilter(s *Schema, filter map[string]interface{}) (*SelectionCondition, error) {
+	if len(filter) == 0 {
+		return &SelectionCondition{Where: WhereConditions{}}, nil
+	}
+
+	items, err := graphQLObject(s, "", filter)
+	if err != nil {
+		return nil, err
+	}
+	return &SelectionCondition{Where: asWhere(aipWhere(aipGroup(LogicAnd, items)))}, nil
+}
+
+// graphQLObject returns the items of the object of the filter, prefix is the path of the object's field
+// with the trailing separator.
+func graphQLObject(s *Schema, prefix string, object map[string]interface{}) ([]interface{}, error) {
+	// keys are taken in order so the result is the same for the same filter
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		value := object[key]
+
+		switch key {
+		case GraphQLAnd, GraphQLOr:
+			list, ok := value.([]interface{})
+			if !ok || len(list) == 0 {
+				return nil, graphQLErrorf("%s must be a non-empty list of objects", prefix+key)
+			}
+			groupItems := make([]interface{}, 0, len(list))
+			for _, element := range list {
+				obj, ok := element.(map[string]interface{})
+				if !ok {
+					return nil, graphQLErrorf("%s must be a non-empty list of objects", prefix+key)
+				}
+				objItems, err := graphQLObject(s, prefix, obj)
+				if err != nil {
+					return nil, err
+				}
+				groupItems = append(groupItems, aipGroup(LogicAnd, objItems))
+			}
+			logic := LogicAnd
+			if key == GraphQLOr {
+				logic = LogicOr
+			}
+			items = append(items, aipGroup(logic, groupItems))
+			continue
+		case GraphQLNot:
+			obj, ok := value.(map[string]interface{})
+			if !ok || len(obj) == 0 {
+				return nil, graphQLErrorf("%s must be a non-empty object", prefix+key)
+			}
+			objItems, err := graphQLObject(s, prefix, obj)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, aipNot(aipGroup(LogicAnd, objItems)))
+			continue
+		}
+
+		obj, ok := value.(map[string]interface{})
+		if !ok || len(obj) == 0 {
+			return nil, graphQLErrorf("%s must be a non-empty object of operators or of fields", prefix+key)
+		}
+		fieldItems, err := graphQLField(s, prefix+key, obj)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, fieldItems...)
+	}
+	return items, nil
+}
+
+// graphQLField returns the conditions of the field by the object of its operators,
+// an object of fields of a nested struct is taken as a nested object of the filter.
+func graphQLField(s *Schema, field string, object map[string]interface{}) ([]interface{}, error) {
+	for key := range object {
+		if _, ok := graphQLOperators[key]; !ok {
+			return graphQLObject(s, field+FieldPathSeparator, object)
+		}
+	}
+
+	operators := make([]string, 0, len(object))
+	for operator := range object {
+		operators = append(operators, operator)
+	}
+	sort.Strings(operators)
+
+	items := make([]interface{}, 0, len(operators))
+	for _, operator := range operators {
+		condition := graphQLOperators[operator]
+		values, err := jsonValues(object[operator], condition == ConditionIn)
+		if err != nil {
+			return nil, graphQLErrorf("%s.%s: %s", field, operator, err)
+		}
+
+		key := field + s.o.conditionSeparator + condition
+		var cond *WhereCondition
+		var ok bool
+		if condition == ConditionIn {
+			// the items are converted apart as they may have the separator of values
+			cond, ok, err = parseWhereList(s, key, values)
+		} else {
+			cond, ok, err = parseWhereParam(s, key, values)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, newParamError(ErrUnknownField, field, "Unknown field %s", field)
+		}
+
+		if operator == "_neq" || operator == "_nin" {
+			items = append(items, aipNot(*cond))
+			continue
+		}
+		items = append(items, *cond)
+	}
+	return items, nil
+}
+
+// jsonValue returns the value decoded from JSON as it is in a param, a list is joined by the separator.
+func jsonValue(value interface{}, separator string, isList bool) (string, error) {
+	values, err := jsonValues(value, isList)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(values, separator), nil
+}
+
+// jsonValues returns the values decoded from JSON as they are in a param, a scalar is a single value.
+func jsonValues(value interface{}, isList bool) ([]string, error) {
+	if !isList {
+		str, err := jsonScalar(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{str}, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() == 0 {
+		return nil, errors.Errorf("a non-empty list expected, got %T", value)
+	}
+	values := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		str, err := jsonScalar(v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, str)
+	}
+	return values, nil
+}
+
+func jsonScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		// numbers decoded from JSON are float64, an integer is formatted without the exponent
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(v), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	return "", errors.Errorf("a scalar value expected, got %T", value)
+}
+
+func graphQLErrorf(format string, args ...interface{}) error {
+	return newParamError(ErrInvalidFilter, GraphQLFilterParamName, "Invalid filter: "+format, args...)
+}
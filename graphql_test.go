@@ -0,0 +1,119 @@
+package selection_condition
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseGraphQLFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		// filter is the JSON of the filter input
+		filter string
+		opts   []Option
+		// params are the query params of the same conditions
+		params map[string][]string
+		// want is set for conditions which cannot be in query params
+		want *SelectionCondition
+		err  error
+	}{
+		{
+			name:   "empty",
+			filter: `{}`,
+			params: map[string][]string{},
+		},
+		{
+			name:   "operators",
+			filter: `{"status": {"_eq": "open"}, "amount": {"_gte": 10, "_lt": 100}, "author": {"name": {"_eq": "john"}}}`,
+			params: map[string][]string{"status": {"open"}, "amount__gte": {"10"}, "amount__lt": {"100"}, "author.name": {"john"}},
+		},
+		{
+			name:   "or",
+			filter: `{"_or": [{"status": {"_eq": "open"}}, {"amount": {"_gt": 10}}], "quantity": {"_lt": 5}}`,
+			params: map[string][]string{"or": {"(status=open,amount__gt=10)"}, "quantity__lt": {"5"}},
+		},
+		{
+			name:   "and of objects",
+			filter: `{"_or": [{"status": {"_eq": "new"}, "quantity": {"_lt": 5}}, {"_and": [{"status": {"_eq": "open"}}, {"paid": {"_eq": true}}]}]}`,
+			params: map[string][]string{"or": {"(and(status=new,quantity__lt=5),and(status=open,paid=true))"}},
+		},
+		{
+			name:   "in",
+			filter: `{"status": {"_in": ["new", "open"]}, "id": {"_in": [1, 2]}}`,
+			params: map[string][]string{"status__in": {"new,open"}, "id__in": {"1,2"}},
+		},
+		{
+			name:   "in with a comma inside a value",
+			filter: `{"author": {"name": {"_in": ["Smith, John", "Doe"]}}}`,
+			want: &SelectionCondition{Where: WhereConditions{
+				{Field: "Author.Name", Condition: ConditionIn, Value: []interface{}{"Doe", "Smith, John"}},
+			}},
+		},
+		{
+			name:   "negations",
+			filter: `{"_not": {"status": {"_eq": "open"}, "paid": {"_eq": true}}, "id": {"_nin": [1, 2]}}`,
+			params: map[string][]string{"not": {"(status=open,paid=true)", "(id__in=(1,2))"}},
+		},
+		{
+			name:   "empty or",
+			filter: `{"_or": []}`,
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "field without operators",
+			filter: `{"status": "open"}`,
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "unknown field",
+			filter: `{"unknown": {"_eq": 1}}`,
+			err:    ErrUnknownField,
+		},
+		{
+			name:   "bad value",
+			filter: `{"quantity": {"_eq": "many"}}`,
+			err:    &ErrBadValue{},
+		},
+		{
+			name:   "max conditions",
+			filter: `{"status": {"_eq": "open"}, "amount": {"_gt": 10}}`,
+			opts:   []Option{WithMaxConditions(1)},
+			err:    ErrTooManyConditions,
+		},
+		{
+			name:   "required filter",
+			filter: `{"amount": {"_gt": 10}}`,
+			opts:   []Option{WithRequiredFilters("Status")},
+			err:    ErrRequiredFilter,
+		},
+		{
+			name:   "contradiction",
+			filter: `{"amount": {"_gt": 10, "_lt": 5}}`,
+			opts:   []Option{WithRejectContradictions()},
+			err:    ErrContradiction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.filter), &filter); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseGraphQLFilter(filter, &testOrder{}, tt.opts...)
+			checkError(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			want := tt.want
+			if want == nil {
+				if want, err = ParseQueryParams(tt.params, &testOrder{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !got.Equal(want) {
+				t.Errorf("got %s, want %s", got.normalizedJSON(), want.normalizedJSON())
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package selection_condition
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	LogicAnd = "and"
+	LogicOr  = "or"
+	Negation = "not"
+
+	GroupItemsSeparator = ","
+	GroupOpening        = "("
+	GroupClosing        = ")"
+)
+
+var LogicVariants = []interface{}{LogicAnd, LogicOr}
+
+// WhereConditionGroup joins its conditions with the logic operator, the result is negated if Not is set.
+// Each of the conditions is a WhereCondition or a WhereConditionGroup.
+//
+// In query params a group is set by a parameter named as the logic operator, e.g. "(a=1 OR b=2) AND c>3" is
+//
+//	or=(a=1,b=2)&c__gt=3
+//
+// Groups can be nested: or=(a=1,and(b=2,c__gt=3)). Inside a group a value enclosed in parentheses is taken
+// without them, so it may contain commas: or=(id__in=(1,2,3),name=(Smith, John)).
+//
+// A group negated by "not" joins its conditions with AND, e.g. "NOT (status=active AND plan=free)" is
+//
+//	not=(status=active,plan=free)
+//
+// and "NOT (a=1 OR b=2)" is not=(or(a=1,b=2)).
+type WhereConditionGroup struct {
+	Logic      string        `json:"logic"`
+	Not        bool          `json:"not,omitempty"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+func (g WhereConditionGroup) Validate() error {
+	return validation.ValidateStruct(&g,
+		validation.Field(&g.Logic, validation.Required, validation.In(LogicVariants...)),
+		validation.Field(&g.Conditions, validation.Required, validation.Each(validation.By(validateGroupCondition))),
+	)
+}
+
+func validateGroupCondition(value interface{}) error {
+	switch c := value.(type) {
+	case WhereCondition:
+		return c.Validate()
+	case WhereConditionGroup:
+		return c.Validate()
+	}
+	return errors.Errorf("Condition of a group must be a WhereCondition or a WhereConditionGroup, got %T", value)
+}
+
+func parseGroupParam(s *Schema, key string, vals []string) ([]WhereConditionGroup, bool, error) {
+	if key != LogicAnd && key != LogicOr && key != Negation {
+		return nil, false, nil
+	}
+	groups := make([]WhereConditionGroup, 0, len(vals))
+
+	for _, val := range vals {
+		group, err := parseGroup(s, key, val)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(group.Conditions) == 0 {
+			continue
+		}
+		groups = append(groups, *group)
+	}
+	return groups, true, nil
+}
+
+// parseGroup parses expr of the group set by the logic operator or the negation.
+func parseGroup(s *Schema, logic string, expr string) (*WhereConditionGroup, error) {
+	if !strings.HasPrefix(expr, GroupOpening) || !strings.HasSuffix(expr, GroupClosing) {
+		return nil, newParamError(ErrInvalidGroup, logic, "Group %q must be enclosed in parentheses", expr)
+	}
+
+	items, err := splitGroupItems(expr[len(GroupOpening) : len(expr)-len(GroupClosing)])
+	if err != nil {
+		return nil, newParamError(ErrInvalidGroup, logic, "%s", err)
+	}
+
+	group := &WhereConditionGroup{
+		Logic:      logic,
+		Conditions: make([]interface{}, 0, len(items)),
+	}
+	if logic == Negation {
+		group.Logic = LogicAnd
+		group.Not = true
+	}
+
+	for _, item := range items {
+		if itemLogic, ok := groupLogic(item); ok {
+			subgroup, err := parseGroup(s, itemLogic, item[len(itemLogic):])
+			if err != nil {
+				return nil, err
+			}
+			if len(subgroup.Conditions) > 0 {
+				group.Conditions = append(group.Conditions, *subgroup)
+			}
+			continue
+		}
+
+		i := strings.Index(item, "=")
+		if i < 0 {
+			return nil, newParamError(ErrInvalidGroup, logic, "Condition %q of a group must be in the form name=value", item)
+		}
+		value := item[i+1:]
+		if strings.HasPrefix(value, GroupOpening) && strings.HasSuffix(value, GroupClosing) {
+			value = value[len(GroupOpening) : len(value)-len(GroupClosing)]
+		}
+
+		interval, ok, err := parseIntervalParam(s, item[:i], value)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			group.Conditions = append(group.Conditions, *interval)
+			continue
+		}
+
+		whereCondition, ok, err := parseWhereParam(s, item[:i], []string{value})
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if s.o.strictParams {
+				return nil, newParamError(ErrUnknownParam, item[:i], "Unknown parameter %s in group %q", item[:i], expr)
+			}
+			continue
+		}
+		group.Conditions = append(group.Conditions, *whereCondition)
+	}
+	return group, nil
+}
+
+func groupLogic(item string) (string, bool) {
+	for _, logic := range []string{LogicAnd, LogicOr, Negation} {
+		if strings.HasPrefix(item, logic+GroupOpening) {
+			return logic, true
+		}
+	}
+	return "", false
+}
+
+func splitGroupItems(s string) ([]string, error) {
+	var items []string
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], GroupOpening):
+			depth++
+		case strings.HasPrefix(s[i:], GroupClosing):
+			depth--
+			if depth < 0 {
+				return nil, errors.Errorf("Unbalanced parentheses in group %q", s)
+			}
+		case depth == 0 && strings.HasPrefix(s[i:], GroupItemsSeparator):
+			items = append(items, s[start:i])
+			start = i + len(GroupItemsSeparator)
+		}
+	}
+	if depth != 0 {
+		return nil, errors.Errorf("Unbalanced parentheses in group %q", s)
+	}
+	items = append(items, s[start:])
+
+	for _, item := range items {
+		if item == "" {
+			return nil, errors.Errorf("Empty condition in group %q", s)
+		}
+	}
+	return items, nil
+}
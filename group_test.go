@@ -0,0 +1,104 @@
+package selection_condition
+
+import "testing"
+
+func TestParseGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string][]string
+		want   Where
+		err    error
+	}{
+		{
+			name:   "or",
+			params: map[string][]string{"or": {"(status=open,amount__gt=10)"}},
+			want: WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{
+				WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"},
+				WhereCondition{Field: "Amount", Condition: ConditionGt, Value: 10.0},
+			}},
+		},
+		{
+			name:   "or with a condition",
+			params: map[string][]string{"or": {"(status=open,status=new)"}, "quantity__lt": {"5"}},
+			want: WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{
+				WhereCondition{Field: "Quantity", Condition: ConditionLt, Value: 5},
+				WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{
+					WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"},
+					WhereCondition{Field: "Status", Condition: ConditionEq, Value: "new"},
+				}},
+			}},
+		},
+		{
+			name:   "nested",
+			params: map[string][]string{"or": {"(status=open,and(status=new,amount__gt=10))"}},
+			want: WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{
+				WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"},
+				WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{
+					WhereCondition{Field: "Status", Condition: ConditionEq, Value: "new"},
+					WhereCondition{Field: "Amount", Condition: ConditionGt, Value: 10.0},
+				}},
+			}},
+		},
+		{
+			name:   "not",
+			params: map[string][]string{"not": {"(status=open,paid=true)"}},
+			want: WhereConditionGroup{Logic: LogicAnd, Not: true, Conditions: []interface{}{
+				WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"},
+				WhereCondition{Field: "Paid", Condition: ConditionEq, Value: true},
+			}},
+		},
+		{
+			name:   "not or",
+			params: map[string][]string{"not": {"(or(status=open,status=new))"}},
+			want: WhereConditionGroup{Logic: LogicAnd, Not: true, Conditions: []interface{}{
+				WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{
+					WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"},
+					WhereCondition{Field: "Status", Condition: ConditionEq, Value: "new"},
+				}},
+			}},
+		},
+		{
+			name:   "values in parentheses",
+			params: map[string][]string{"or": {"(id__in=(1,2,3),author.name=(Smith, John))"}},
+			want: WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{
+				WhereCondition{Field: "ID", Condition: ConditionIn, Value: []interface{}{uint(1), uint(2), uint(3)}},
+				WhereCondition{Field: "Author.Name", Condition: ConditionEq, Value: "Smith, John"},
+			}},
+		},
+		{
+			name:   "unknown field is skipped",
+			params: map[string][]string{"or": {"(unknown=1,status=open)"}},
+			want:   WhereConditions{{Field: "Status", Condition: ConditionEq, Value: "open"}},
+		},
+		{
+			name:   "without parentheses",
+			params: map[string][]string{"or": {"status=open,status=new"}},
+			err:    ErrInvalidGroup,
+		},
+		{
+			name:   "unbalanced parentheses",
+			params: map[string][]string{"or": {"(status=open,and(status=new)"}},
+			err:    ErrInvalidGroup,
+		},
+		{
+			name:   "empty condition",
+			params: map[string][]string{"or": {"(status=open,)"}},
+			err:    ErrInvalidGroup,
+		},
+		{
+			name:   "condition without value",
+			params: map[string][]string{"or": {"(status,paid=true)"}},
+			err:    ErrInvalidGroup,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQueryParams(tt.params, &testOrder{})
+			checkError(t, err, tt.err)
+			if want := (&SelectionCondition{Where: tt.want}); tt.err == nil && !got.Equal(want) {
+				t.Errorf("got %s, want %s", got.normalizedJSON(), want.normalizedJSON())
+			}
+		})
+	}
+}
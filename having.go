@@ -0,0 +1,107 @@
+package selection_condition
+
+import (
+	"reflect"
+	"strings"
+)
+
+// HavingParamName is the param of the conditions on the aggregates of groups joined by AND,
+// a condition is set as a param with an aggregate instead of a field: having=(count__gte=5,sum:amount__gt=100)
+const HavingParamName = "having"
+
+var (
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+// HavingCondition is a condition on an aggregate of the rows of a group.
+type HavingCondition struct {
+	Aggregate
+	Condition string      `json:"condition"`
+	Value     interface{} `json:"value"`
+}
+
+// parseHavingParam parses the conditions on aggregates, a single condition may be set without parentheses.
+func parseHavingParam(s *Schema, vals []string) ([]HavingCondition, error) {
+	expr := vals[0]
+	items := []string{expr}
+	if strings.HasPrefix(expr, GroupOpening) && strings.HasSuffix(expr, GroupClosing) {
+		var err error
+		items, err = splitGroupItems(expr[len(GroupOpening) : len(expr)-len(GroupClosing)])
+		if err != nil {
+			return nil, newParamError(ErrInvalidGroup, HavingParamName, "%s", err)
+		}
+	}
+
+	conditions := make([]HavingCondition, 0, len(items))
+	for _, item := range items {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, newParamError(ErrInvalidParam, HavingParamName, "Condition %q of parameter %s must be in the form aggregate=value", item, HavingParamName)
+		}
+		if strings.HasPrefix(value, GroupOpening) && strings.HasSuffix(value, GroupClosing) {
+			value = value[len(GroupOpening) : len(value)-len(GroupClosing)]
+		}
+
+		condition, err := parseHavingCondition(s, key, value, nil)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, *condition)
+	}
+	return conditions, nil
+}
+
+// parseHavingCondition parses the condition like count__gte=5, a value of count is an int64, the one of sum and avg
+// is a float64 and the one of min and max is of the type of the field. Items are the values of in if they are
+// parsed apart, e.g. from a JSON body.
+func parseHavingCondition(s *Schema, key string, value string, items []string) (*HavingCondition, error) {
+	o := s.o
+	aggregateName, strCond, err := splitConditionParameterName(key, o)
+	if err != nil {
+		return nil, err
+	}
+	switch strCond {
+	case ConditionEq, ConditionGt, ConditionGte, ConditionLt, ConditionLte, ConditionIn, ConditionBt, ConditionBtExcl:
+	default:
+		return nil, newParamError(ErrInvalidOperator, key, "Condition %q is not allowed for aggregates", strCond)
+	}
+
+	aggregates, err := parseAggregateParam(s, []string{aggregateName})
+	if err != nil {
+		return nil, err
+	}
+	aggregate := aggregates[0]
+
+	typ := float64Type
+	switch aggregate.Func {
+	case AggregateCount:
+		typ = int64Type
+	case AggregateMin, AggregateMax:
+		f, _ := s.fieldByPath(aggregate.Field)
+		typ = f.typ
+	}
+
+	var v interface{}
+	if items != nil && strCond == ConditionIn {
+		v, err = string2vals(items, strCond, typ, o)
+	} else {
+		v, err = string2valByCondition(value, strCond, typ, o)
+	}
+	if err != nil {
+		return nil, &ErrBadValue{Field: aggregateName, Raw: value, Kind: typ.String(), Err: err}
+	}
+	return &HavingCondition{
+		Aggregate: aggregate,
+		Condition: strCond,
+		Value:     v,
+	}, nil
+}
+
+// checkHaving checks that the conditions on aggregates are set for grouped rows.
+func checkHaving(conditions *SelectionCondition) error {
+	if len(conditions.Having) == 0 || len(conditions.GroupBy) > 0 || len(conditions.Aggregates) > 0 {
+		return nil
+	}
+	return newParamError(ErrInvalidParam, HavingParamName, "Parameter %s requires parameter %s or %s", HavingParamName, GroupByParamName, AggregateParamName)
+}
@@ -0,0 +1,47 @@
+package selection_condition
+
+import "strings"
+
+const (
+	// IncludeParamName is the param of the relations to be loaded with the rows: include=author,comments.author
+	IncludeParamName = "include"
+	// ExpandParamName is another name of the param include: expand=author
+	ExpandParamName = "expand"
+)
+
+// WithRelations sets the relations allowed in the param include by their paths of Go names, e.g. "Author"
+// or "Comments.Author". Any relation in the param makes parsing fail if none is set.
+func WithRelations(paths ...string) Option {
+	return func(o *options) {
+		if o.relations == nil {
+			o.relations = make(map[string]bool, len(paths))
+		}
+		for _, path := range paths {
+			o.relations[path] = true
+		}
+	}
+}
+
+// parseIncludeParam parses the relations to the paths of Go names, a relation listed twice is taken once.
+func parseIncludeParam(s *Schema, key string, vals []string) ([]string, error) {
+	items := strings.Split(vals[0], s.o.valuesSeparator)
+	relations := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+
+	for _, item := range items {
+		paramName := strings.TrimSpace(item)
+		if paramName == "" {
+			return nil, newParamError(ErrInvalidParam, key, "Empty relation in parameter %s", key)
+		}
+		path, f, ok := s.fieldByParamName(paramName)
+		if !ok || f.nested == nil || !s.o.relations[path] {
+			return nil, newParamError(ErrUnknownRelation, paramName, "Unknown relation %s", paramName)
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		relations = append(relations, path)
+	}
+	return relations, nil
+}
@@ -0,0 +1,30 @@
+package naming
+
+import (
+	"strings"
+	"unicode"
+)
+
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	b.Grow(len(name) + 4)
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' && runes[i-1] != '.' &&
+				(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+					(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// AggregateAlias returns the name of the column of an aggregate of a column, e.g. "sum_amount" for SUM(amount).
+func AggregateAlias(fn string, column string) string {
+	return fn + "_" + strings.ReplaceAll(column, ".", "_")
+}
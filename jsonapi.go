@@ -0,0 +1,62 @@
+package selection_condition
+
+import "strings"
+
+const (
+	JSONAPIFilterParamName = "filter"
+	JSONAPISortParamName   = "sort"
+	JSONAPIPageParamName   = "page[number]"
+	JSONAPISizeParamName   = "page[size]"
+
+	// JSONAPIDescPrefix is the prefix of a field sorted in the descending order: sort=-created_at,name
+	JSONAPIDescPrefix = SortOrderDescPrefix
+)
+
+// jsonAPIParams translates the params in the syntax of JSON:API to the ones of the package:
+// filter[status][eq]=active to status__eq=active, filter[status]=active to status=active
+// and sort=-created_at,name to sort_order=-created_at,name. The other params are kept as is.
+func jsonAPIParams(params map[string][]string, o *options) map[string][]string {
+	res := make(map[string][]string, len(params))
+
+	for key, vals := range params {
+		switch {
+		case key == JSONAPISortParamName:
+			// the syntax of the sort is the one of sort_order
+			res[SortOrderParamName] = vals
+		case strings.HasPrefix(key, JSONAPIFilterParamName+"["):
+			name, ok := jsonAPIFilterName(key[len(JSONAPIFilterParamName):], o)
+			if !ok {
+				res[key] = vals
+				continue
+			}
+			res[name] = vals
+		default:
+			res[key] = vals
+		}
+	}
+	return res
+}
+
+// jsonAPIFilterName returns the name of the param for the brackets of a filter like [status][eq].
+func jsonAPIFilterName(brackets string, o *options) (string, bool) {
+	var names []string
+	for brackets != "" {
+		if !strings.HasPrefix(brackets, "[") {
+			return "", false
+		}
+		i := strings.Index(brackets, "]")
+		if i < 0 {
+			return "", false
+		}
+		names = append(names, brackets[1:i])
+		brackets = brackets[i+1:]
+	}
+
+	switch len(names) {
+	case 1:
+		return names[0], true
+	case 2:
+		return names[0] + o.conditionSeparator + names[1], true
+	}
+	return "", false
+}
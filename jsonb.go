@@ -0,0 +1,180 @@
+package selection_condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConditionJSONContains matches JSON fields containing the JSON document: metadata__jsonb_contains={"plan":"pro"}
+const ConditionJSONContains = "jsonb_contains"
+
+var (
+	jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
+	stringType         = reflect.TypeOf("")
+)
+
+// jsonPathKeyRegexp restricts the keys of JSON paths as they are put in queries as they are.
+var jsonPathKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// isJSONType reports whether a field of type typ holds a JSON document, that is a json.RawMessage,
+// a map by strings or an interface.
+func isJSONType(typ reflect.Type) bool {
+	return typ == jsonRawMessageType ||
+		(typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String) ||
+		typ.Kind() == reflect.Interface
+}
+
+// jsonFieldByParamName returns the path of Go names of the JSON field the param name starts with
+// and the path of the keys inside the document after it: metadata.plan.name gives Metadata and plan.name.
+func (s *Schema) jsonFieldByParamName(paramName string) (string, string, bool, error) {
+	names := strings.Split(paramName, FieldPathSeparator)
+	for i := 1; i < len(names); i++ {
+		fieldName, f, ok := s.fieldByParamName(strings.Join(names[:i], FieldPathSeparator))
+		if !ok || !isJSONType(f.typ) {
+			continue
+		}
+		for _, key := range names[i:] {
+			if !jsonPathKeyRegexp.MatchString(key) {
+				return "", "", false, newParamError(ErrInvalidParam, paramName, "Invalid key %q of JSON path %s", key, paramName)
+			}
+		}
+		return fieldName, strings.Join(names[i:], FieldPathSeparator), true, nil
+	}
+	return "", "", false, nil
+}
+
+// parseJSONDocument parses the value of jsonb_contains, it must be an object or an array.
+func parseJSONDocument(strValue string) (interface{}, error) {
+	value, err := unmarshalValue(json.RawMessage(strValue))
+	if err != nil {
+		return nil, err
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return value, nil
+	}
+	return nil, errors.Errorf("Condition %q requires a JSON object or array", ConditionJSONContains)
+}
+
+// FlattenJSON returns the scalar values of the object by the paths of their keys separated by dots,
+// e.g. {"plan": {"name": "pro"}} gives {"plan.name": "pro"}. It returns false if there are arrays in the object.
+func FlattenJSON(object map[string]interface{}) (map[string]interface{}, bool) {
+	res := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		switch v := value.(type) {
+		case []interface{}:
+			return nil, false
+		case map[string]interface{}:
+			nested, ok := FlattenJSON(v)
+			if !ok {
+				return nil, false
+			}
+			for nestedKey, nestedValue := range nested {
+				res[key+FieldPathSeparator+nestedKey] = nestedValue
+			}
+		default:
+			res[key] = value
+		}
+	}
+	return res, true
+}
+
+// jsonDocument returns the value of a JSON field as it is decoded from JSON.
+func jsonDocument(v reflect.Value) (interface{}, error) {
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	data, ok := v.Interface().(json.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(v.Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return unmarshalValue(data)
+}
+
+// jsonValueAt returns the value of the document by the path of keys separated by dots as a string
+// as ->> of Postgres does it, it returns false if there is no value.
+func jsonValueAt(document interface{}, jsonPath string) (string, bool) {
+	for _, key := range strings.Split(jsonPath, FieldPathSeparator) {
+		object, ok := document.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if document, ok = object[key]; !ok {
+			return "", false
+		}
+	}
+	switch v := document.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		return string(data), err == nil
+	}
+	return fmt.Sprint(document), true
+}
+
+// jsonContains reports whether the document contains the other one as @> of Postgres does it: an object contains
+// the keys of the other one with contained values and an array contains each of the elements of the other one.
+func jsonContains(document interface{}, other interface{}) bool {
+	switch o := other.(type) {
+	case map[string]interface{}:
+		d, ok := document.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, otherValue := range o {
+			value, ok := d[key]
+			if !ok || !jsonContains(value, otherValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		d, ok := document.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, elem := range o {
+			var found bool
+			for _, docElem := range d {
+				if jsonContains(docElem, elem) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(document, other)
+}
+
+// JSONPathExpression returns the Postgres expression of the value of the column at the JSON path as text,
+// e.g. metadata->'plan'->>'name' for the path plan.name.
+func JSONPathExpression(column string, jsonPath string) string {
+	keys := strings.Split(jsonPath, FieldPathSeparator)
+	var b strings.Builder
+	b.WriteString(column)
+	for i, key := range keys {
+		if i == len(keys)-1 {
+			b.WriteString("->>")
+		} else {
+			b.WriteString("->")
+		}
+		b.WriteString("'" + strings.ReplaceAll(key, "'", "''") + "'")
+	}
+	return b.String()
+}
@@ -0,0 +1,308 @@
+package selection_condition
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONBodyParamName is the name of the param in errors of a JSON body which cannot be decoded.
+const JSONBodyParamName = "body"
+
+// JSONBody is the JSON shape of a selection for endpoints like POST /search where filters are too large
+// for a query string:
+//
+//	{
+//		"where": [{"field": "age", "op": "gte", "value": 18}, {"field": "status", "op": "in", "value": ["new", "open"]}],
+//		"sort": [{"field": "created_at", "order": "desc", "nulls": "last"}, {"field": "id"}],
+//		"limit": 50,
+//		"offset": 100,
+//		"fields": ["id", "name"],
+//		"distinct": true,
+//		"with_count": true,
+//		"include": ["author"],
+//		"group_by": ["country"],
+//		"agg": ["count", "sum:amount"],
+//		"having": [{"field": "sum:amount", "op": "gt", "value": 100}],
+//		"q": "smith"
+//	}
+//
+// Conditions of where are joined by AND, op is eq by default, the value of in and bt is a list, a null bound
+// of bt makes it half-open: [18, null] is gte 18.
+// Conditions of having are the same with aggregates instead of fields.
+// Limit and offset are the ones of PaginationLimitOffset, limit and page are the ones of PaginationPage.
+type JSONBody struct {
+	Where     []JSONBodyCondition `json:"where"`
+	Sort      []JSONBodySort      `json:"sort"`
+	Limit     *uint               `json:"limit"`
+	Offset    *uint               `json:"offset"`
+	Page      *uint               `json:"page"`
+	Fields    []string            `json:"fields"`
+	Distinct  bool                `json:"distinct"`
+	WithCount bool                `json:"with_count"`
+	CountOnly bool                `json:"count_only"`
+	Include   []string            `json:"include"`
+	GroupBy   []string            `json:"group_by"`
+	Agg       []string            `json:"agg"`
+	Having    []JSONBodyCondition `json:"having"`
+	Q         string              `json:"q"`
+}
+
+type JSONBodyCondition struct {
+	Field string      `json:"field"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+type JSONBodySort struct {
+	Field string `json:"field"`
+	// Order is asc or desc, asc by default
+	Order string `json:"order"`
+	// Nulls is first or last
+	Nulls           string `json:"nulls"`
+	CaseInsensitive bool   `json:"case_insensitive"`
+}
+
+// ParseJSONBody parses the selection in the shape of JSONBody read from r by the fields of the struct pointed by model.
+func ParseJSONBody(r io.Reader, model interface{}, opts ...Option) (*SelectionCondition, error) {
+	return NewParser(opts...).ParseJSONBody(r, model)
+}
+
+// ParseJSONBodyContext is ParseJSONBody with ctx passed to the functions set by WithForcedConditionsFunc.
+func ParseJSONBodyContext(ctx context.Context, r io.Reader, model interface{}, opts ...Option) (*SelectionCondition, error) {
+	return NewParser(opts...).ParseJSONBodyContext(ctx, r, model)
+}
+
+// ParseJSONBody parses the selection in the shape of JSONBody read from r by the fields of the struct pointed by model.
+// Unknown keys of the body are an error if the parser is made with WithStrictParams.
+func (p *Parser) ParseJSONBody(r io.Reader, model interface{}) (*SelectionCondition, error) {
+	return p.ParseJSONBodyContext(context.Background(), r, model)
+}
+
+// ParseJSONBodyContext is ParseJSONBody with ctx passed to the functions set by WithForcedConditionsFunc,
+// e.g. the context of the request of the body.
+func (p *Parser) ParseJSONBodyContext(ctx context.Context, r io.Reader, model interface{}) (*SelectionCondition, error) {
+	var body JSONBody
+	dec := json.NewDecoder(r)
+	if p.o.strictParams {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(&body); err != nil {
+		return nil, translateError(newParamError(ErrInvalidParam, JSONBodyParamName, "Invalid JSON body: %s", err), p.o.translator)
+	}
+
+	schema, err := p.Schema(model)
+	if err != nil {
+		return nil, err
+	}
+	where, having, err := jsonBodyConditions(schema, &body)
+	if err != nil {
+		return nil, translateError(err, p.o.translator)
+	}
+	params, err := jsonBodyParams(&body, p.o)
+	if err != nil {
+		return nil, translateError(err, p.o.translator)
+	}
+	conditions, err := parseSelection(ctx, params, schema, where, having)
+	if err != nil {
+		return nil, translateError(err, p.o.translator)
+	}
+	return conditions, nil
+}
+
+// jsonBodyConditions parses the where conditions and the conditions of having of the body, the values of lists
+// are parsed apart, so they may have the separator of values.
+func jsonBodyConditions(s *Schema, body *JSONBody) (WhereConditions, []HavingCondition, error) {
+	o := s.o
+	where := make(WhereConditions, 0, len(body.Where))
+	keys := make(map[string]bool, len(body.Where))
+	for _, cond := range body.Where {
+		if cond.Field == "" {
+			return nil, nil, newParamError(ErrInvalidFilter, JSONBodyParamName, "Field of a condition is missing")
+		}
+		key, value, items, err := jsonBodyCondition(cond, o)
+		if err != nil {
+			return nil, nil, err
+		}
+		if keys[key] {
+			return nil, nil, newParamError(ErrInvalidFilter, key, "Duplicate condition %s %s", cond.Field, cond.Op)
+		}
+		keys[key] = true
+
+		var whereCondition *WhereCondition
+		var ok bool
+		if items != nil {
+			whereCondition, ok, err = parseWhereList(s, key, items)
+		} else {
+			whereCondition, ok, err = parseWhereParam(s, key, []string{value})
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			// unknown fields are skipped like unknown params
+			if o.strictParams {
+				return nil, nil, newParamError(ErrUnknownParam, cond.Field, "Unknown parameters: %s", cond.Field)
+			}
+			continue
+		}
+		where = append(where, *whereCondition)
+	}
+
+	var having []HavingCondition
+	for _, cond := range body.Having {
+		key, value, items, err := jsonBodyCondition(cond, o)
+		if err != nil {
+			return nil, nil, err
+		}
+		condition, err := parseHavingCondition(s, key, value, items)
+		if err != nil {
+			return nil, nil, err
+		}
+		having = append(having, *condition)
+	}
+	return where, having, nil
+}
+
+// jsonBodyCondition returns the key of the condition of the body as the one of a param and its value as it is
+// in a param, items are the values of a list condition like in which are parsed apart. A null bound of bt is taken
+// as the half-open range like the param price__bt=100, is: [10, null] is gte 10 and [null, 10] is lte 10.
+func jsonBodyCondition(cond JSONBodyCondition, o *options) (string, string, []string, error) {
+	op := cond.Op
+	var value string
+	var items []string
+	var err error
+
+	// a scalar value of in is taken as a list of one value
+	v := reflect.ValueOf(cond.Value)
+	isList := isListCondition(op) && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array)
+	switch {
+	case isList && (op == ConditionBt || op == ConditionBtExcl) && isHalfOpenBounds(cond.Value):
+		bounds := cond.Value.([]interface{})
+		lower, upper := ConditionGte, ConditionLte
+		if op == ConditionBtExcl {
+			lower, upper = ConditionGt, ConditionLt
+		}
+		if bounds[1] == nil {
+			op = lower
+			value, err = jsonScalar(bounds[0])
+		} else {
+			op = upper
+			value, err = jsonScalar(bounds[1])
+		}
+	case isList && (op == ConditionBt || op == ConditionBtExcl):
+		// bounds are parsed as a param since dates of them are whole days
+		value, err = jsonValue(cond.Value, o.valuesSeparator, true)
+	case isList:
+		items, err = jsonValues(cond.Value, true)
+		value = strings.Join(items, o.valuesSeparator)
+	default:
+		value, err = jsonScalar(cond.Value)
+	}
+	if err != nil {
+		return "", "", nil, &ErrBadValue{Field: cond.Field, Raw: jsonRaw(cond.Value), Kind: "JSON", Err: err}
+	}
+
+	key := cond.Field
+	if op != "" {
+		key += o.conditionSeparator + op
+	}
+	return key, value, items, nil
+}
+
+// isHalfOpenBounds reports whether the value decoded from JSON is a list of two bounds with one of them null.
+func isHalfOpenBounds(value interface{}) bool {
+	bounds, ok := value.([]interface{})
+	return ok && len(bounds) == 2 && (bounds[0] == nil) != (bounds[1] == nil)
+}
+
+// jsonBodyParams translates the body to the params of the package but the where conditions and the conditions
+// of having parsed by jsonBodyConditions, so the body is parsed by the same rules.
+func jsonBodyParams(body *JSONBody, o *options) (map[string][]string, error) {
+	params := make(map[string][]string, 4)
+
+	if len(body.Sort) > 0 {
+		items := make([]string, 0, len(body.Sort))
+		for _, item := range body.Sort {
+			var modifiers []string
+			if item.Order != "" {
+				modifiers = append(modifiers, strings.ToLower(item.Order))
+			}
+			if item.Nulls != "" {
+				modifiers = append(modifiers, "nulls"+strings.ToLower(item.Nulls))
+			}
+			if item.CaseInsensitive {
+				modifiers = append(modifiers, SortCaseInsensitive)
+			}
+			if len(modifiers) == 0 {
+				items = append(items, item.Field)
+				continue
+			}
+			items = append(items, item.Field+o.conditionSeparator+strings.Join(modifiers, SortModifierSeparator))
+		}
+		params[SortOrderParamName] = []string{strings.Join(items, o.valuesSeparator)}
+	}
+
+	if len(body.Fields) > 0 {
+		params[FieldsParamName] = []string{strings.Join(body.Fields, o.valuesSeparator)}
+	}
+
+	if body.Distinct {
+		params[DistinctParamName] = []string{strconv.FormatBool(true)}
+	}
+	if body.WithCount {
+		params[WithCountParamName] = []string{strconv.FormatBool(true)}
+	}
+	if body.CountOnly {
+		params[CountOnlyParamName] = []string{strconv.FormatBool(true)}
+	}
+	if len(body.Include) > 0 {
+		params[IncludeParamName] = []string{strings.Join(body.Include, o.valuesSeparator)}
+	}
+
+	if len(body.GroupBy) > 0 {
+		params[GroupByParamName] = []string{strings.Join(body.GroupBy, o.valuesSeparator)}
+	}
+	if len(body.Agg) > 0 {
+		params[AggregateParamName] = []string{strings.Join(body.Agg, o.valuesSeparator)}
+	}
+
+	if body.Q != "" {
+		params[SearchParamName] = []string{body.Q}
+	}
+
+	limitParamName, offsetParamName := o.limitParamName, o.offsetParamName
+	if o.pagination == PaginationPage {
+		limitParamName, offsetParamName = o.perPageParamName, ""
+	}
+	for _, p := range []struct {
+		name  string
+		key   string
+		value *uint
+	}{
+		{"limit", limitParamName, body.Limit},
+		{"offset", offsetParamName, body.Offset},
+		{"page", o.pageParamName, body.Page},
+	} {
+		if p.value == nil {
+			continue
+		}
+		if p.key == "" || (p.name == "page" && o.pagination != PaginationPage) {
+			return nil, newParamError(ErrInvalidPagination, p.name, "Parameter %s is not allowed by the pagination", p.name)
+		}
+		params[p.key] = []string{strconv.FormatUint(uint64(*p.value), 10)}
+	}
+	return params, nil
+}
+
+// jsonRaw returns the value encoded in JSON for messages.
+func jsonRaw(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
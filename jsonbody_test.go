@@ -0,0 +1,137 @@
+package selection_condition
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseJSONBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		opts []Option
+		// params are the query params of the same selection
+		params map[string][]string
+		// want is set for a selection which cannot be in query params
+		want *SelectionCondition
+		err  error
+	}{
+		{
+			name:   "empty",
+			body:   `{}`,
+			params: map[string][]string{},
+		},
+		{
+			name: "selection",
+			body: `{
+				"where": [{"field": "status", "value": "open"}, {"field": "amount", "op": "gte", "value": 10}, {"field": "author.name", "value": "john"}],
+				"sort": [{"field": "created_at", "order": "desc", "nulls": "last"}, {"field": "id"}],
+				"limit": 20,
+				"offset": 40,
+				"fields": ["id", "status"],
+				"distinct": true,
+				"with_count": true
+			}`,
+			params: map[string][]string{
+				"status":      {"open"},
+				"amount__gte": {"10"},
+				"author.name": {"john"},
+				"sort_order":  {"created_at__desc_nullslast,id"},
+				"limit":       {"20"},
+				"offset":      {"40"},
+				"fields":      {"id,status"},
+				"distinct":    {"true"},
+				"with_count":  {"true"},
+			},
+		},
+		{
+			name:   "in",
+			body:   `{"where": [{"field": "status", "op": "in", "value": ["new", "open"]}, {"field": "id", "op": "in", "value": 1}]}`,
+			params: map[string][]string{"status__in": {"new,open"}, "id__in": {"1"}},
+		},
+		{
+			name: "in with a comma inside a value",
+			body: `{"where": [{"field": "author.name", "op": "in", "value": ["Smith, John", "Doe"]}]}`,
+			want: &SelectionCondition{Where: WhereConditions{
+				{Field: "Author.Name", Condition: ConditionIn, Value: []interface{}{"Doe", "Smith, John"}},
+			}},
+		},
+		{
+			name:   "bt",
+			body:   `{"where": [{"field": "amount", "op": "bt", "value": [10, 100]}]}`,
+			params: map[string][]string{"amount__bt": {"10,100"}},
+		},
+		{
+			name:   "bt of dates",
+			body:   `{"where": [{"field": "created_at", "op": "bt", "value": ["2024-01-01", "2024-01-31"]}]}`,
+			params: map[string][]string{"created_at__bt": {"2024-01-01,2024-01-31"}},
+		},
+		{
+			name:   "half-open bt",
+			body:   `{"where": [{"field": "amount", "op": "bt", "value": [10, null]}, {"field": "quantity", "op": "bt_excl", "value": [null, 5]}]}`,
+			params: map[string][]string{"amount__gte": {"10"}, "quantity__lt": {"5"}},
+		},
+		{
+			name:   "grouping",
+			body:   `{"group_by": ["status"], "agg": ["count", "sum:amount"], "having": [{"field": "count", "op": "gte", "value": 5}, {"field": "sum:amount", "op": "bt", "value": [100, null]}]}`,
+			params: map[string][]string{"group_by": {"status"}, "agg": {"count,sum:amount"}, "having": {"(count__gte=5,sum:amount__gte=100)"}},
+		},
+		{
+			name:   "unknown field is skipped",
+			body:   `{"where": [{"field": "unknown", "value": 1}]}`,
+			params: map[string][]string{},
+		},
+		{
+			name: "unknown field",
+			body: `{"where": [{"field": "unknown", "value": 1}]}`,
+			opts: []Option{WithStrictParams()},
+			err:  ErrUnknownParam,
+		},
+		{
+			name: "invalid JSON",
+			body: `{"where": `,
+			err:  ErrInvalidParam,
+		},
+		{
+			name: "missing field",
+			body: `{"where": [{"value": 1}]}`,
+			err:  ErrInvalidFilter,
+		},
+		{
+			name: "duplicate condition",
+			body: `{"where": [{"field": "status", "value": "open"}, {"field": "status", "value": "new"}]}`,
+			err:  ErrInvalidFilter,
+		},
+		{
+			name: "bad value",
+			body: `{"where": [{"field": "quantity", "value": "many"}]}`,
+			err:  &ErrBadValue{},
+		},
+		{
+			name: "page without the pagination",
+			body: `{"page": 2}`,
+			err:  ErrInvalidPagination,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithTimeLocation(time.UTC)}, tt.opts...)
+			got, err := ParseJSONBody(strings.NewReader(tt.body), &testOrder{}, opts...)
+			checkError(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			want := tt.want
+			if want == nil {
+				if want, err = ParseQueryParams(tt.params, &testOrder{}, WithTimeLocation(time.UTC)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !got.Equal(want) {
+				t.Errorf("got %s, want %s", got.normalizedJSON(), want.normalizedJSON())
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package selection_condition
+
+import "reflect"
+
+// JSONSchemaDialect is the version of JSON Schema of ParamsJSONSchema.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema of a value, it is the schema of a parameter of OpenAPI too.
+type JSONSchema struct {
+	Dialect     string        `json:"$schema,omitempty"`
+	Type        string        `json:"type,omitempty"`
+	Format      string        `json:"format,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Items       *JSONSchema   `json:"items,omitempty"`
+	MinItems    *int          `json:"minItems,omitempty"`
+	MaxItems    *int          `json:"maxItems,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+}
+
+// ParamsJSONSchema returns the JSON Schema of the params accepted by parsing by the fields of the struct pointed
+// by model, e.g. for form builders and contract tests. The options must be the ones of parsing.
+func ParamsJSONSchema(model interface{}, opts ...Option) (*JSONSchema, error) {
+	schema, err := NewParser(opts...).Schema(model)
+	if err != nil {
+		return nil, err
+	}
+	return schema.ParamsJSONSchema(), nil
+}
+
+// ParamsJSONSchema returns the JSON Schema of an object of the params accepted by parsing by the schema,
+// the ones of OpenAPIParameters by their names. The values are typed as they are before they are put in a query:
+// numbers, booleans and arrays of the values of list conditions. Other properties are not allowed
+// if parsing is made with WithStrictParams.
+func (s *Schema) ParamsJSONSchema() *JSONSchema {
+	params := s.OpenAPIParameters()
+	res := &JSONSchema{
+		Dialect:    JSONSchemaDialect,
+		Type:       "object",
+		Properties: make(map[string]*JSONSchema, len(params)),
+	}
+	for _, param := range params {
+		property := *param.Schema
+		property.Description = param.Description
+		res.Properties[param.Name] = &property
+	}
+	if s.o.strictParams {
+		additional := false
+		res.AdditionalProperties = &additional
+	}
+	return res
+}
+
+// conditionSchema returns the schema of the value of the condition on the field, the values of list conditions
+// are an array if the values separator is the one of arrays of OpenAPI, otherwise they are a string.
+func (s *Schema) conditionSchema(condition string, f *schemaField) *JSONSchema {
+	typ := f.typ
+	switch condition {
+	case ConditionNear, ConditionBBox, ConditionJSONContains, ConditionFuzzy:
+		return &JSONSchema{Type: "string"}
+	case ConditionAny, ConditionAll, ConditionOverlaps:
+		typ = valueType(typ.Elem())
+	}
+	var enum []string
+	// ieq takes the values of the enum in any case
+	if isEnumCondition(condition) && condition != ConditionIEq {
+		enum = f.enum
+	}
+	if !isListCondition(condition) {
+		return valueSchema(typ, enum)
+	}
+	if s.o.valuesSeparator != ValuesSeparator {
+		return &JSONSchema{Type: "string"}
+	}
+
+	schema := &JSONSchema{Type: "array", Items: valueSchema(typ, enum)}
+	switch condition {
+	case ConditionBt, ConditionBtExcl, ConditionOverlaps:
+		two := 2
+		schema.MinItems, schema.MaxItems = &two, &two
+	default:
+		if s.o.maxListValues > 0 {
+			maxItems := int(s.o.maxListValues)
+			schema.MaxItems = &maxItems
+		}
+	}
+	return schema
+}
+
+// valueSchema returns the schema of a value of the type typ, enum lists the only values of the field.
+func valueSchema(typ reflect.Type, enum []string) *JSONSchema {
+	schema := &JSONSchema{Type: "string"}
+	switch {
+	case typ == timeType:
+		schema.Format = "date-time"
+	case isUUIDType(typ):
+		schema.Format = "uuid"
+	default:
+		if _, ok := converterByType(typ); ok || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+			break
+		}
+		switch typ.Kind() {
+		case reflect.Bool:
+			schema.Type = "boolean"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			schema.Type = "integer"
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			var zero float64
+			schema.Type, schema.Minimum = "integer", &zero
+		case reflect.Float32, reflect.Float64:
+			schema.Type = "number"
+		}
+	}
+	// values of the enum tag are strings, they are listed for text values only
+	if schema.Type == "string" {
+		for _, v := range enum {
+			schema.Enum = append(schema.Enum, v)
+		}
+	}
+	return schema
+}
@@ -0,0 +1,246 @@
+package selection_condition
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// selectionConditionJSON has the fields of SelectionCondition without its methods of encoding.
+type selectionConditionJSON SelectionCondition
+
+// MarshalJSON encodes the condition, Where must be nil, WhereConditions or a WhereConditionGroup.
+func (e SelectionCondition) MarshalJSON() ([]byte, error) {
+	switch e.Where.(type) {
+	case nil, WhereConditions, WhereConditionGroup:
+	default:
+		return nil, errors.Errorf("Where must be WhereConditions or a WhereConditionGroup, got %T", e.Where)
+	}
+	return json.Marshal(selectionConditionJSON(e))
+}
+
+// UnmarshalJSON decodes the condition encoded by MarshalJSON, Where is decoded as WhereConditions
+// or as a WhereConditionGroup. Values of conditions are decoded as JSON values: a string, a bool,
+// an int64 for an integer number, a float64 for another number or a []interface{} for in and bt,
+// so a time is a string in the RFC 3339 format.
+func (e *SelectionCondition) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		selectionConditionJSON
+		Where json.RawMessage `json:"where"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	where, err := unmarshalWhere(raw.Where)
+	if err != nil {
+		return err
+	}
+	*e = SelectionCondition(raw.selectionConditionJSON)
+	e.Where = where
+	return nil
+}
+
+func unmarshalWhere(data json.RawMessage) (Where, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	if data[0] == '[' {
+		var conditions WhereConditions
+		if err := json.Unmarshal(data, &conditions); err != nil {
+			return nil, err
+		}
+		return conditions, nil
+	}
+
+	var group WhereConditionGroup
+	if err := json.Unmarshal(data, &group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// UnmarshalJSON decodes the field of the sort order also in the former form {"CreatedAt": "desc"},
+// so saved filters encoded before are decoded.
+func (f *SortField) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	_, hasField := raw["field"]
+	_, hasDirection := raw["direction"]
+	if hasField || hasDirection || len(raw) != 1 {
+		// sortFieldJSON has the fields of SortField without this method
+		type sortFieldJSON SortField
+		var sortField sortFieldJSON
+		if err := json.Unmarshal(data, &sortField); err != nil {
+			return err
+		}
+		*f = SortField(sortField)
+		return nil
+	}
+	for field, value := range raw {
+		var direction string
+		if err := json.Unmarshal(value, &direction); err != nil {
+			return err
+		}
+		*f = SortField{Field: field, Direction: direction}
+	}
+	return nil
+}
+
+func (s *WhereCondition) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Field     string          `json:"field"`
+		Condition string          `json:"condition"`
+		Value     json.RawMessage `json:"value"`
+		JSONPath  string          `json:"json_path"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	value, err := unmarshalConditionValue(raw.Condition, raw.Value)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid value of field %s", raw.Field)
+	}
+	*s = WhereCondition{
+		Field:     raw.Field,
+		Condition: raw.Condition,
+		Value:     value,
+		JSONPath:  raw.JSONPath,
+	}
+	return nil
+}
+
+func (c *HavingCondition) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Aggregate
+		Condition string          `json:"condition"`
+		Value     json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	value, err := unmarshalValue(raw.Value)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid value of aggregate %s", raw.Func)
+	}
+	*c = HavingCondition{
+		Aggregate: raw.Aggregate,
+		Condition: raw.Condition,
+		Value:     value,
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the group, an item with the key "logic" is decoded as a WhereConditionGroup
+// and another one as a WhereCondition.
+func (g *WhereConditionGroup) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Logic      string            `json:"logic"`
+		Not        bool              `json:"not"`
+		Conditions []json.RawMessage `json:"conditions"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	group := WhereConditionGroup{
+		Logic:      raw.Logic,
+		Not:        raw.Not,
+		Conditions: make([]interface{}, 0, len(raw.Conditions)),
+	}
+	for _, item := range raw.Conditions {
+		var probe struct {
+			Logic *string `json:"logic"`
+		}
+		if err := json.Unmarshal(item, &probe); err != nil {
+			return err
+		}
+
+		if probe.Logic != nil {
+			var subgroup WhereConditionGroup
+			if err := json.Unmarshal(item, &subgroup); err != nil {
+				return err
+			}
+			group.Conditions = append(group.Conditions, subgroup)
+			continue
+		}
+
+		var condition WhereCondition
+		if err := json.Unmarshal(item, &condition); err != nil {
+			return err
+		}
+		group.Conditions = append(group.Conditions, condition)
+	}
+	*g = group
+	return nil
+}
+
+// unmarshalConditionValue decodes the value of the condition, the one of fuzzy is decoded as a Fuzzy
+// the one of near as a GeoNear and the one of bbox as a GeoBoundingBox.
+func unmarshalConditionValue(condition string, data json.RawMessage) (interface{}, error) {
+	switch condition {
+	case ConditionFuzzy:
+		var fuzzy Fuzzy
+		if err := json.Unmarshal(data, &fuzzy); err != nil {
+			return nil, err
+		}
+		return fuzzy, nil
+	case ConditionNear:
+		var near GeoNear
+		if err := json.Unmarshal(data, &near); err != nil {
+			return nil, err
+		}
+		return near, nil
+	case ConditionBBox:
+		var box GeoBoundingBox
+		if err := json.Unmarshal(data, &box); err != nil {
+			return nil, err
+		}
+		return box, nil
+	}
+	return unmarshalValue(data)
+}
+
+// unmarshalValue decodes the value keeping integers exact.
+func unmarshalValue(data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return jsonNumbers(value), nil
+}
+
+// jsonNumbers replaces the json.Number values by an int64 or a float64.
+func jsonNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonNumbers(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = jsonNumbers(v[k])
+		}
+	}
+	return value
+}
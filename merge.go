@@ -0,0 +1,231 @@
+package selection_condition
+
+import (
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// MergePolicy decides how conditions of the base and the overlay on the same field are merged.
+type MergePolicy int
+
+const (
+	// MergeOverride replaces the conditions of the base on a field by the ones of the overlay on it.
+	MergeOverride MergePolicy = iota
+	// MergeKeepBase drops the conditions of the overlay on a field the base has conditions on.
+	MergeKeepBase
+	// MergeAnd joins all the conditions by AND.
+	MergeAnd
+	// MergeReject makes merging fail if the base and the overlay have different conditions on a field.
+	MergeReject
+)
+
+// Merge returns the conditions of the base combined with the ones of the overlay, e.g. the defaults of a handler
+// with the filters of a client:
+//
+//	cond, err := sc.Merge(defaults, clientCond, sc.MergeOverride)
+//
+// Conditions joined by AND are merged by their fields according to the policy, groups of conditions are joined by AND.
+// The sort order of the overlay goes first followed by the fields of the base it lacks, limit and offset of the overlay
+// take precedence if they are set as well as its fields and its grouping, the relations to be included are joined.
+// Rows are distinct and counted if either of the conditions makes them so. With MergeKeepBase the base takes precedence instead.
+// The arguments are not changed.
+func Merge(base, overlay *SelectionCondition, policy MergePolicy) (*SelectionCondition, error) {
+	if base == nil {
+		return overlay.Clone(), nil
+	}
+	if overlay == nil {
+		return base.Clone(), nil
+	}
+	base, overlay = base.Clone(), overlay.Clone()
+
+	baseConditions, baseGroups, err := splitWhere(base.Where)
+	if err != nil {
+		return nil, err
+	}
+	overlayConditions, overlayGroups, err := splitWhere(overlay.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions WhereConditions
+	switch policy {
+	case MergeOverride:
+		conditions = append(withoutFields(baseConditions, overlayConditions), overlayConditions...)
+	case MergeKeepBase:
+		conditions = append(baseConditions, withoutFields(overlayConditions, baseConditions)...)
+	case MergeAnd:
+		conditions = append(baseConditions, overlayConditions...)
+	case MergeReject:
+		if field, ok := conflictingField(baseConditions, overlayConditions); ok {
+			return nil, newParamError(ErrConflict, field, "Conditions on field %s are conflicting", field)
+		}
+		conditions = append(baseConditions, overlayConditions...)
+	default:
+		return nil, errors.Errorf("Unknown merge policy %d", policy)
+	}
+	conditions = uniqueConditions(conditions)
+
+	first, second := overlay, base
+	if policy == MergeKeepBase {
+		first, second = base, overlay
+	}
+
+	res := &SelectionCondition{
+		Where:     joinWhere(conditions, append(baseGroups, overlayGroups...)),
+		SortOrder: mergeSortOrder(first.SortOrder, second.SortOrder),
+		Limit:     first.Limit,
+		Offset:    first.Offset,
+		Fields:    first.Fields,
+		Distinct:  first.Distinct || second.Distinct,
+		WithCount: first.WithCount || second.WithCount,
+		CountOnly: first.CountOnly || second.CountOnly,
+		Include:   mergeInclude(first.Include, second.Include),
+	}
+	// the grouping, its aggregates and the conditions on them are taken together as they make sense only together
+	res.GroupBy, res.Aggregates, res.Having = first.GroupBy, first.Aggregates, first.Having
+	if len(res.GroupBy) == 0 && len(res.Aggregates) == 0 {
+		res.GroupBy, res.Aggregates, res.Having = second.GroupBy, second.Aggregates, second.Having
+	}
+	if res.Limit == 0 {
+		res.Limit = second.Limit
+	}
+	if res.Offset == 0 {
+		res.Offset = second.Offset
+	}
+	if len(res.Fields) == 0 {
+		res.Fields = second.Fields
+	}
+	return res, nil
+}
+
+// splitWhere returns the conditions and the groups joined by AND in where.
+func splitWhere(where interface{}) (WhereConditions, []WhereConditionGroup, error) {
+	switch w := where.(type) {
+	case nil:
+		return WhereConditions{}, nil, nil
+	case WhereConditions:
+		return w, nil, nil
+	case WhereCondition:
+		return WhereConditions{w}, nil, nil
+	case WhereConditionGroup:
+		if w.Logic != LogicAnd || w.Not {
+			return WhereConditions{}, []WhereConditionGroup{w}, nil
+		}
+
+		conditions := make(WhereConditions, 0, len(w.Conditions))
+		var groups []WhereConditionGroup
+		for _, item := range w.Conditions {
+			switch c := item.(type) {
+			case WhereCondition:
+				conditions = append(conditions, c)
+			case WhereConditionGroup:
+				groups = append(groups, c)
+			default:
+				return nil, nil, errors.Errorf("Condition of a group must be a WhereCondition or a WhereConditionGroup, got %T", item)
+			}
+		}
+		return conditions, groups, nil
+	}
+	return nil, nil, errors.Errorf("Where must be WhereConditions or a WhereConditionGroup, got %T", where)
+}
+
+// withoutFields returns the conditions on the fields which have no conditions in others.
+func withoutFields(conditions, others WhereConditions) WhereConditions {
+	fields := make(map[string]bool, len(others))
+	for _, c := range others {
+		fields[c.Field] = true
+	}
+
+	res := make(WhereConditions, 0, len(conditions))
+	for _, c := range conditions {
+		if !fields[c.Field] {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// conflictingField returns a field which has different conditions in a and in b.
+func conflictingField(a, b WhereConditions) (string, bool) {
+	keys := func(conditions WhereConditions) map[string]map[string]bool {
+		res := make(map[string]map[string]bool)
+		for _, c := range conditions {
+			if res[c.Field] == nil {
+				res[c.Field] = make(map[string]bool)
+			}
+			res[c.Field][normalizationKey(normalizeCondition(c))] = true
+		}
+		return res
+	}
+
+	aKeys, bKeys := keys(a), keys(b)
+	// fields are taken in the order of a so the error is the same for the same conditions
+	for _, c := range a {
+		field := c.Field
+		aFieldKeys := aKeys[field]
+		bFieldKeys, ok := bKeys[field]
+		if !ok {
+			continue
+		}
+		if len(aFieldKeys) != len(bFieldKeys) {
+			return field, true
+		}
+		for key := range aFieldKeys {
+			if !bFieldKeys[key] {
+				return field, true
+			}
+		}
+	}
+	return "", false
+}
+
+// uniqueConditions removes the conditions equal to the previous ones.
+func uniqueConditions(conditions WhereConditions) WhereConditions {
+	seen := make(map[string]bool, len(conditions))
+	res := make(WhereConditions, 0, len(conditions))
+	for _, c := range conditions {
+		key := normalizationKey(normalizeCondition(c))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		res = append(res, c)
+	}
+	return res
+}
+
+// mergeInclude returns the relations of first followed by the ones of second which are not in first.
+func mergeInclude(first, second []string) []string {
+	if len(second) == 0 {
+		return first
+	}
+
+	res := append([]string{}, first...)
+	for _, relation := range second {
+		if !slices.Contains(first, relation) {
+			res = append(res, relation)
+		}
+	}
+	return res
+}
+
+// mergeSortOrder returns the sort order of first followed by the fields of second which are not in first.
+func mergeSortOrder(first, second []SortField) []SortField {
+	if len(first) == 0 {
+		return second
+	}
+
+	fields := make(map[string]bool, len(first))
+	for _, sortField := range first {
+		fields[sortField.Field] = true
+	}
+
+	res := append([]SortField{}, first...)
+	for _, sortField := range second {
+		if !fields[sortField.Field] {
+			res = append(res, sortField)
+		}
+	}
+	return res
+}
@@ -0,0 +1,146 @@
+package selection_condition
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	statusOpen := WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"}
+	statusNew := WhereCondition{Field: "Status", Condition: ConditionEq, Value: "new"}
+	paid := WhereCondition{Field: "Paid", Condition: ConditionEq, Value: true}
+	amountGt := WhereCondition{Field: "Amount", Condition: ConditionGt, Value: 10.0}
+	orGroup := WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{statusOpen, amountGt}}
+
+	base := &SelectionCondition{
+		Where:     WhereConditions{statusOpen, paid},
+		SortOrder: []SortField{{Field: "CreatedAt", Direction: SortOrderDesc}, {Field: "ID", Direction: SortOrderAsc}},
+		Limit:     20,
+		Include:   []string{"Author"},
+	}
+	overlay := &SelectionCondition{
+		Where:     WhereConditions{statusNew},
+		SortOrder: []SortField{{Field: "ID", Direction: SortOrderDesc}},
+		Offset:    40,
+		WithCount: true,
+	}
+
+	tests := []struct {
+		name    string
+		base    *SelectionCondition
+		overlay *SelectionCondition
+		policy  MergePolicy
+		want    *SelectionCondition
+		err     error
+	}{
+		{
+			name:    "override",
+			base:    base,
+			overlay: overlay,
+			policy:  MergeOverride,
+			want: &SelectionCondition{
+				Where:     WhereConditions{paid, statusNew},
+				SortOrder: []SortField{{Field: "ID", Direction: SortOrderDesc}, {Field: "CreatedAt", Direction: SortOrderDesc}},
+				Limit:     20,
+				Offset:    40,
+				WithCount: true,
+				Include:   []string{"Author"},
+			},
+		},
+		{
+			name:    "keep base",
+			base:    base,
+			overlay: overlay,
+			policy:  MergeKeepBase,
+			want: &SelectionCondition{
+				Where:     WhereConditions{statusOpen, paid},
+				SortOrder: []SortField{{Field: "CreatedAt", Direction: SortOrderDesc}, {Field: "ID", Direction: SortOrderAsc}},
+				Limit:     20,
+				Offset:    40,
+				WithCount: true,
+				Include:   []string{"Author"},
+			},
+		},
+		{
+			name:    "and",
+			base:    base,
+			overlay: overlay,
+			policy:  MergeAnd,
+			want: &SelectionCondition{
+				Where:     WhereConditions{statusOpen, paid, statusNew},
+				SortOrder: []SortField{{Field: "ID", Direction: SortOrderDesc}, {Field: "CreatedAt", Direction: SortOrderDesc}},
+				Limit:     20,
+				Offset:    40,
+				WithCount: true,
+				Include:   []string{"Author"},
+			},
+		},
+		{
+			name:    "reject conflicting",
+			base:    base,
+			overlay: overlay,
+			policy:  MergeReject,
+			err:     ErrConflict,
+		},
+		{
+			name:    "reject equal",
+			base:    &SelectionCondition{Where: WhereConditions{statusOpen, paid}},
+			overlay: &SelectionCondition{Where: WhereConditions{statusOpen, amountGt}},
+			policy:  MergeReject,
+			want:    &SelectionCondition{Where: WhereConditions{statusOpen, paid, amountGt}},
+		},
+		{
+			name:    "groups are joined",
+			base:    &SelectionCondition{Where: WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{paid, orGroup}}},
+			overlay: &SelectionCondition{Where: WhereConditionGroup{Logic: LogicAnd, Not: true, Conditions: []interface{}{statusNew}}},
+			policy:  MergeOverride,
+			want: &SelectionCondition{Where: WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{
+				paid,
+				orGroup,
+				WhereConditionGroup{Logic: LogicAnd, Not: true, Conditions: []interface{}{statusNew}},
+			}}},
+		},
+		{
+			name:    "grouping is taken together",
+			base:    &SelectionCondition{GroupBy: []string{"Status"}, Aggregates: []Aggregate{{Func: AggregateCount}}},
+			overlay: &SelectionCondition{Fields: []string{"Status"}, Distinct: true},
+			policy:  MergeOverride,
+			want: &SelectionCondition{
+				Fields:     []string{"Status"},
+				Distinct:   true,
+				GroupBy:    []string{"Status"},
+				Aggregates: []Aggregate{{Func: AggregateCount}},
+			},
+		},
+		{
+			name:    "nil base",
+			overlay: overlay,
+			policy:  MergeOverride,
+			want:    overlay,
+		},
+		{
+			name:   "nil overlay",
+			base:   base,
+			policy: MergeOverride,
+			want:   base,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseHash, overlayHash := hashOrNil(tt.base), hashOrNil(tt.overlay)
+			got, err := Merge(tt.base, tt.overlay, tt.policy)
+			checkError(t, err, tt.err)
+			if tt.err == nil && !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got.normalizedJSON(), tt.want.normalizedJSON())
+			}
+			if hashOrNil(tt.base) != baseHash || hashOrNil(tt.overlay) != overlayHash {
+				t.Error("arguments are changed")
+			}
+		})
+	}
+}
+
+func hashOrNil(cond *SelectionCondition) string {
+	if cond == nil {
+		return ""
+	}
+	return cond.Hash()
+}
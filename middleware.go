@@ -0,0 +1,51 @@
+package selection_condition
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// ErrorHandler writes the response to a request which params cannot be parsed.
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// DefaultErrorHandler writes the message of err with the status 400 Bad Request.
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
+// NewContext returns a copy of ctx holding cond.
+func NewContext(ctx context.Context, cond *SelectionCondition) context.Context {
+	return context.WithValue(ctx, contextKey{}, cond)
+}
+
+// FromContext returns the condition held by ctx, e.g. the one parsed by Middleware.
+func FromContext(ctx context.Context) (*SelectionCondition, bool) {
+	cond, ok := ctx.Value(contextKey{}).(*SelectionCondition)
+	return cond, ok
+}
+
+// Middleware parses and validates the query params of a request by the fields of the struct pointed by model
+// and passes the condition to the next handler in the request context, it is got by FromContext.
+// If the params are invalid the error handler set by WithErrorHandler writes the response.
+func Middleware(model interface{}, opts ...Option) func(http.Handler) http.Handler {
+	return NewParser(opts...).Middleware(model)
+}
+
+// Middleware is like the function Middleware with the options of the parser.
+func (p *Parser) Middleware(model interface{}) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cond, err := p.ParseRequest(r, model)
+			if err == nil {
+				err = cond.Validate()
+			}
+			if err != nil {
+				p.o.errorHandler(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), cond)))
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package selection_condition
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Normalize brings the condition to the canonical form, so the same logical conditions are equal:
+// conditions of a group are ordered and deduplicated, nested groups of the same logic are flattened,
+// values of in are ordered and deduplicated and times are in UTC. The sort order is kept as it is.
+func (e *SelectionCondition) Normalize() {
+	e.Where = asWhere(normalizeWhere(e.Where))
+}
+
+// Hash returns the key of the normalized condition, e.g. for caching of results. The condition itself is not changed.
+func (e *SelectionCondition) Hash() string {
+	sum := sha256.Sum256(e.normalizedJSON())
+	return hex.EncodeToString(sum[:])
+}
+
+// Equal reports whether the conditions are the same after normalization, values are compared by their JSON,
+// so int 18 is equal to int64 18.
+func (e *SelectionCondition) Equal(other *SelectionCondition) bool {
+	if e == nil || other == nil {
+		return e == other
+	}
+	return bytes.Equal(e.normalizedJSON(), other.normalizedJSON())
+}
+
+// normalizedJSON returns the normalized condition in JSON, the condition itself is not changed.
+func (e *SelectionCondition) normalizedJSON() []byte {
+	normalized := *e
+	normalized.Normalize()
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		// Where of an unknown type is taken as it is printed
+		data = []byte(fmt.Sprintf("%#v", normalized))
+	}
+	return data
+}
+
+func normalizeWhere(where interface{}) interface{} {
+	var group WhereConditionGroup
+	switch w := where.(type) {
+	case nil:
+		return WhereConditions{}
+	case WhereConditions:
+		group = conditionsGroup(w)
+	case WhereCondition:
+		group = conditionsGroup([]WhereCondition{w})
+	case WhereConditionGroup:
+		group = w
+	default:
+		return where
+	}
+	return aipWhere(normalizeGroup(group))
+}
+
+func conditionsGroup(conditions []WhereCondition) WhereConditionGroup {
+	group := WhereConditionGroup{
+		Logic:      LogicAnd,
+		Conditions: make([]interface{}, 0, len(conditions)),
+	}
+	for _, c := range conditions {
+		group.Conditions = append(group.Conditions, c)
+	}
+	return group
+}
+
+// normalizeGroup returns the normalized group, a group of a single item which is not negated is returned as the item.
+func normalizeGroup(group WhereConditionGroup) interface{} {
+	items := make([]interface{}, 0, len(group.Conditions))
+	for _, item := range group.Conditions {
+		switch c := item.(type) {
+		case WhereCondition:
+			items = append(items, normalizeCondition(c))
+		case WhereConditionGroup:
+			normalized := normalizeGroup(c)
+			// a AND (b AND c) is a AND b AND c
+			if g, ok := normalized.(WhereConditionGroup); ok && g.Logic == group.Logic && !g.Not {
+				items = append(items, g.Conditions...)
+				continue
+			}
+			items = append(items, normalized)
+		default:
+			items = append(items, item)
+		}
+	}
+	items = sortUnique(items)
+
+	if len(items) == 1 && !group.Not {
+		return items[0]
+	}
+	return WhereConditionGroup{
+		Logic:      group.Logic,
+		Not:        group.Not,
+		Conditions: items,
+	}
+}
+
+func normalizeCondition(c WhereCondition) WhereCondition {
+	v := reflect.ValueOf(c.Value)
+	if v.Kind() != reflect.Slice {
+		c.Value = normalizeValue(c.Value)
+		return c
+	}
+
+	values := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		values = append(values, normalizeValue(v.Index(i).Interface()))
+	}
+	// the order of bounds of bt matters
+	if c.Condition == ConditionIn || c.Condition == ConditionAny || c.Condition == ConditionAll {
+		values = sortUnique(values)
+	}
+
+	// the values are kept in a slice of the type they are in
+	res := reflect.MakeSlice(v.Type(), 0, len(values))
+	for _, value := range values {
+		if value == nil {
+			res = reflect.Append(res, reflect.Zero(v.Type().Elem()))
+			continue
+		}
+		res = reflect.Append(res, reflect.ValueOf(value))
+	}
+	c.Value = res.Interface()
+	return c
+}
+
+func normalizeValue(value interface{}) interface{} {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC()
+	}
+	return value
+}
+
+// sortUnique orders the items by their JSON and removes the equal ones.
+func sortUnique(items []interface{}) []interface{} {
+	keys := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		keys[normalizationKey(item)] = item
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	res := make([]interface{}, 0, len(sorted))
+	for _, key := range sorted {
+		res = append(res, keys[key])
+	}
+	return res
+}
+
+func normalizationKey(item interface{}) string {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Sprintf("%#v", item)
+	}
+	return string(data)
+}
@@ -0,0 +1,247 @@
+package selection_condition
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	ODataFilterParamName  = "$filter"
+	ODataOrderByParamName = "$orderby"
+	ODataTopParamName     = "$top"
+	ODataSkipParamName    = "$skip"
+)
+
+// odataOperators maps comparison operators of OData to conditions, "ne" is the negated eq.
+var odataOperators = map[string]string{
+	"eq": ConditionEq,
+	"ne": ConditionEq,
+	"gt": ConditionGt,
+	"ge": ConditionGte,
+	"lt": ConditionLt,
+	"le": ConditionLte,
+	"in": ConditionIn,
+}
+
+// parseODataFilter parses the value of $filter, it returns a WhereCondition or a WhereConditionGroup.
+// Comparisons, "in" lists, "and", "or", "not" and parentheses are supported, functions are not:
+//
+//	price gt 10 and (status eq 'open' or status in ('new','draft'))
+func parseODataFilter(s *Schema, filter string) (interface{}, error) {
+	p := &odataParser{schema: s, filter: filter, tokens: odataTokens(filter)}
+	if len(p.tokens) == 0 {
+		return nil, p.errorf("empty filter")
+	}
+
+	where, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.end() {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+	return where, nil
+}
+
+type odataToken struct {
+	text string
+	// quoted is set for a string literal
+	quoted bool
+}
+
+func odataTokens(filter string) []odataToken {
+	var tokens []odataToken
+	runes := []rune(filter)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, odataToken{text: string(r)})
+			i++
+		case r == '\'':
+			// a quote inside a string literal is doubled: 'O''Neil'
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == '\'' {
+					if j+1 < len(runes) && runes[j+1] == '\'' {
+						b.WriteRune('\'')
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteRune(runes[j])
+			}
+			tokens = append(tokens, odataToken{text: b.String(), quoted: true})
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("(),'", runes[j]) {
+				j++
+			}
+			tokens = append(tokens, odataToken{text: string(runes[i:j])})
+			i = j
+		}
+	}
+	return tokens
+}
+
+// odataParser parses the tokens by the precedence of OData: not, and, or.
+type odataParser struct {
+	schema *Schema
+	filter string
+	tokens []odataToken
+	pos    int
+}
+
+func (p *odataParser) end() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *odataParser) peek() odataToken {
+	return p.tokens[p.pos]
+}
+
+func (p *odataParser) next() (odataToken, error) {
+	if p.end() {
+		return odataToken{}, p.errorf("unexpected end")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *odataParser) keyword(word string) bool {
+	if p.end() || p.peek().quoted || p.peek().text != word {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *odataParser) errorf(format string, args ...interface{}) error {
+	return newParamError(ErrInvalidFilter, ODataFilterParamName, "Invalid filter %q: "+format, append([]interface{}{p.filter}, args...)...)
+}
+
+func (p *odataParser) or() (interface{}, error) {
+	var items []interface{}
+	for {
+		item, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.keyword("or") {
+			return aipGroup(LogicOr, items), nil
+		}
+	}
+}
+
+func (p *odataParser) and() (interface{}, error) {
+	var items []interface{}
+	for {
+		item, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.keyword("and") {
+			return aipGroup(LogicAnd, items), nil
+		}
+	}
+}
+
+func (p *odataParser) unary() (interface{}, error) {
+	if p.keyword("not") {
+		item, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return aipNot(item), nil
+	}
+
+	if p.keyword("(") {
+		item, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword(")") {
+			return nil, p.errorf("missing %q", ")")
+		}
+		return item, nil
+	}
+	return p.comparison()
+}
+
+func (p *odataParser) comparison() (interface{}, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if field.quoted {
+		return nil, p.errorf("field expected, got '%s'", field.text)
+	}
+
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	condition, ok := odataOperators[operator.text]
+	if !ok || operator.quoted {
+		return nil, p.errorf("unknown operator %q", operator.text)
+	}
+
+	key := field.text + p.schema.o.conditionSeparator + condition
+	var cond *WhereCondition
+	if condition == ConditionIn {
+		var values []string
+		if values, err = p.list(); err != nil {
+			return nil, err
+		}
+		// the literals are converted apart as they may have the separator of values
+		cond, ok, err = parseWhereList(p.schema, key, values)
+	} else {
+		var token odataToken
+		if token, err = p.next(); err != nil {
+			return nil, err
+		}
+		cond, ok, err = parseWhereParam(p.schema, key, []string{token.text})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, newParamError(ErrUnknownField, field.text, "Unknown field %s", field.text)
+	}
+
+	if operator.text == "ne" {
+		return aipNot(*cond), nil
+	}
+	return *cond, nil
+}
+
+// list parses the values of "in" like ('a','b').
+func (p *odataParser) list() ([]string, error) {
+	if !p.keyword("(") {
+		return nil, p.errorf("list of values expected")
+	}
+
+	var values []string
+	for {
+		token, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, token.text)
+
+		if p.keyword(")") {
+			return values, nil
+		}
+		if !p.keyword(",") {
+			return nil, p.errorf("%q or %q expected in list of values", ",", ")")
+		}
+	}
+}
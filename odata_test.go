@@ -0,0 +1,92 @@
+package selection_condition
+
+import "testing"
+
+func TestParseOData(t *testing.T) {
+	tests := []struct {
+		name  string
+		odata map[string][]string
+		// params are the query params of the same selection
+		params map[string][]string
+		// want is set for a selection which cannot be in query params
+		want *SelectionCondition
+		err  error
+	}{
+		{
+			name:   "comparisons",
+			odata:  map[string][]string{"$filter": {"status eq 'open' and amount ge 10 and quantity lt 5 and author.name eq 'john'"}},
+			params: map[string][]string{"status": {"open"}, "amount__gte": {"10"}, "quantity__lt": {"5"}, "author.name": {"john"}},
+		},
+		{
+			name:   "or and parentheses",
+			odata:  map[string][]string{"$filter": {"(status eq 'open' or amount gt 10) and quantity lt 5"}},
+			params: map[string][]string{"or": {"(status=open,amount__gt=10)"}, "quantity__lt": {"5"}},
+		},
+		{
+			name:   "and binds tighter than or",
+			odata:  map[string][]string{"$filter": {"status eq 'open' or status eq 'new' and quantity lt 5"}},
+			params: map[string][]string{"or": {"(status=open,and(status=new,quantity__lt=5))"}},
+		},
+		{
+			name:   "in",
+			odata:  map[string][]string{"$filter": {"status in ('new','open') and id in (1,2)"}},
+			params: map[string][]string{"status__in": {"new,open"}, "id__in": {"1,2"}},
+		},
+		{
+			name:  "in with a comma inside a value",
+			odata: map[string][]string{"$filter": {"author.name in ('Smith, John','O''Neil')"}},
+			want: &SelectionCondition{Where: WhereConditions{
+				{Field: "Author.Name", Condition: ConditionIn, Value: []interface{}{"O'Neil", "Smith, John"}},
+			}},
+		},
+		{
+			name:   "not and ne",
+			odata:  map[string][]string{"$filter": {"not (status eq 'open' and paid eq true) and status ne 'archived'"}},
+			params: map[string][]string{"not": {"(status=open,paid=true)", "(status=archived)"}},
+		},
+		{
+			name:   "order by and page",
+			odata:  map[string][]string{"$orderby": {"created_at desc,id"}, "$top": {"20"}, "$skip": {"40"}},
+			params: map[string][]string{"sort_order": {"created_at__desc,id"}, "limit": {"20"}, "offset": {"40"}},
+		},
+		{
+			name:  "unknown operator",
+			odata: map[string][]string{"$filter": {"status like 'open'"}},
+			err:   ErrInvalidFilter,
+		},
+		{
+			name:  "missing parenthesis",
+			odata: map[string][]string{"$filter": {"(status eq 'open'"}},
+			err:   ErrInvalidFilter,
+		},
+		{
+			name:  "unexpected token",
+			odata: map[string][]string{"$filter": {"status eq 'open' 'new'"}},
+			err:   ErrInvalidFilter,
+		},
+		{
+			name:  "bad value",
+			odata: map[string][]string{"$filter": {"quantity eq 'many'"}},
+			err:   &ErrBadValue{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQueryParams(tt.odata, &testOrder{}, WithOData())
+			checkError(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			want := tt.want
+			if want == nil {
+				if want, err = ParseQueryParams(tt.params, &testOrder{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !got.Equal(want) {
+				t.Errorf("got %s, want %s", got.normalizedJSON(), want.normalizedJSON())
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package selection_condition
+
+import (
+	"strconv"
+	"strings"
+)
+
+// OpenAPIParameter is a parameter of an operation of OpenAPI 3.
+type OpenAPIParameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Style       string      `json:"style,omitempty"`
+	Explode     *bool       `json:"explode,omitempty"`
+	Schema      *JSONSchema `json:"schema"`
+}
+
+// conditionDescriptions describe the conditions after the name of a field.
+var conditionDescriptions = map[string]string{
+	ConditionEq:           "equal to the value",
+	ConditionGt:           "greater than the value",
+	ConditionGte:          "greater than or equal to the value",
+	ConditionLt:           "less than the value",
+	ConditionLte:          "less than or equal to the value",
+	ConditionIn:           "equal to one of the values",
+	ConditionBt:           "between the two values, the bounds included, one of them may be empty",
+	ConditionBtExcl:       "between the two values, the bounds excluded, one of them may be empty",
+	ConditionTS:           "containing the words of the value",
+	ConditionILike:        "containing the value ignoring the case",
+	ConditionFuzzy:        "similar to the value, a similarity threshold from 0 to 1 may follow " + FuzzySimilaritySeparator,
+	ConditionIEq:          "equal to the value ignoring the case",
+	ConditionAny:          "containing any of the values",
+	ConditionAll:          "containing all of the values",
+	ConditionJSONContains: "containing the JSON object or array",
+	ConditionNear:         "within the radius of the point as lat,lon,radius, the radius in m, km or mi",
+	ConditionBBox:         "within the box as minLat,minLon,maxLat,maxLon",
+	ConditionOverlaps:     "overlapping the interval of the two values",
+}
+
+// OpenAPIParameters returns the query parameters of OpenAPI 3 accepted by parsing by the fields of the struct
+// pointed by model, e.g. for docs of an API. The options must be the ones of parsing.
+func OpenAPIParameters(model interface{}, opts ...Option) ([]OpenAPIParameter, error) {
+	schema, err := NewParser(opts...).Schema(model)
+	if err != nil {
+		return nil, err
+	}
+	return schema.OpenAPIParameters(), nil
+}
+
+// OpenAPIParameters returns the query parameters of OpenAPI 3 accepted by parsing by the schema: a parameter
+// for each condition on each field, the sort order, the search and the pagination. Conditions of list values
+// are arrays in the form style with commas unless WithValuesSeparator sets another separator.
+func (s *Schema) OpenAPIParameters() []OpenAPIParameter {
+	o := s.o
+	var params []OpenAPIParameter
+	for _, fp := range s.flatFields() {
+		if o.strictFilters && !fp.field.filterable {
+			continue
+		}
+		for _, condition := range s.fieldConditions(fp.path, fp.field) {
+			param := OpenAPIParameter{
+				Name:        s.filterParamName(fp.name, condition),
+				In:          "query",
+				Description: fp.name + " " + conditionDescription(condition),
+				Schema:      s.conditionSchema(condition, fp.field),
+			}
+			if param.Schema.Type == "array" {
+				explode := false
+				param.Style, param.Explode = "form", &explode
+			}
+			params = append(params, param)
+		}
+	}
+
+	if len(s.searchFields) > 0 {
+		params = append(params, OpenAPIParameter{
+			Name:        SearchParamName,
+			In:          "query",
+			Description: "Text searched in " + strings.Join(s.searchFields, ", ") + " ignoring the case",
+			Schema:      &JSONSchema{Type: "string"},
+		})
+	}
+
+	sortParamName := SortOrderParamName
+	if o.jsonAPI {
+		sortParamName = JSONAPISortParamName
+	}
+	params = append(params, OpenAPIParameter{
+		Name: sortParamName,
+		In:   "query",
+		Description: "Fields of the sort order separated by " + strconv.Quote(o.valuesSeparator) + ", a field is descending with the prefix " +
+			strconv.Quote(SortOrderDescPrefix) + ": " + strings.Join(s.sortFieldNames(), ", "),
+		Schema: &JSONSchema{Type: "string"},
+	})
+
+	var zero, one float64 = 0, 1
+	limit := &JSONSchema{Type: "integer", Minimum: &zero}
+	if o.defaultLimit > 0 {
+		limit.Default = o.defaultLimit
+	}
+	if o.maxLimit > 0 {
+		maxLimit := float64(o.maxLimit)
+		limit.Maximum = &maxLimit
+	}
+	switch o.pagination {
+	case PaginationPage:
+		params = append(params,
+			OpenAPIParameter{Name: o.pageParamName, In: "query", Description: "Number of the page from 1", Schema: &JSONSchema{Type: "integer", Minimum: &one}},
+			OpenAPIParameter{Name: o.perPageParamName, In: "query", Description: "Number of the rows of a page", Schema: limit},
+		)
+	default:
+		params = append(params,
+			OpenAPIParameter{Name: o.limitParamName, In: "query", Description: "Maximum number of the rows", Schema: limit},
+			OpenAPIParameter{Name: o.offsetParamName, In: "query", Description: "Number of the rows skipped", Schema: &JSONSchema{Type: "integer", Minimum: &zero}},
+		)
+	}
+	params = append(params,
+		OpenAPIParameter{Name: o.cursorParamName, In: "query", Description: "Cursor of the next page", Schema: &JSONSchema{Type: "string"}},
+		OpenAPIParameter{Name: WithCountParamName, In: "query", Description: "Request the total number of the rows along with them", Schema: &JSONSchema{Type: "boolean"}},
+		OpenAPIParameter{Name: CountOnlyParamName, In: "query", Description: "Request only the total number of the rows", Schema: &JSONSchema{Type: "boolean"}},
+	)
+	return params
+}
+
+// filterParamName returns the name of the param of the condition on the field by its param name.
+func (s *Schema) filterParamName(name string, condition string) string {
+	if s.o.jsonAPI {
+		if condition == DefaultWhereCondition {
+			return JSONAPIFilterParamName + "[" + name + "]"
+		}
+		return JSONAPIFilterParamName + "[" + name + "][" + condition + "]"
+	}
+	if condition == DefaultWhereCondition {
+		return name
+	}
+	return name + s.o.conditionSeparator + condition
+}
+
+// sortFieldNames returns the param names of the fields the rows may be sorted by.
+func (s *Schema) sortFieldNames() []string {
+	var names []string
+	for _, fp := range s.flatFields() {
+		if !isScalarType(fp.field.typ) || (s.o.strictSort && !fp.field.sortable) {
+			continue
+		}
+		names = append(names, fp.name)
+	}
+	return names
+}
+
+func conditionDescription(condition string) string {
+	if description, ok := conditionDescriptions[condition]; ok {
+		return description
+	}
+	return "by the condition " + condition
+}
@@ -0,0 +1,337 @@
+package selection_condition
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type PaginationMode int
+
+const (
+	// PaginationLimitOffset is set by limit and offset params: limit=25&offset=50
+	PaginationLimitOffset PaginationMode = iota
+	// PaginationPage is set by page and per_page params, pages are numbered from 1: page=3&per_page=25
+	PaginationPage
+)
+
+type LimitPolicy int
+
+const (
+	// LimitClamp reduces a limit exceeding the maximum to the maximum.
+	LimitClamp LimitPolicy = iota
+	// LimitReject makes parsing fail if a limit exceeds the maximum.
+	LimitReject
+)
+
+// DefaultTagName is the struct tag giving names of the fields in query params.
+const DefaultTagName = "json"
+
+type options struct {
+	tagName            string
+	conditionSeparator string
+	valuesSeparator    string
+	aliases            map[string]string
+	strictFilters      bool
+	strictSort         bool
+	fieldConditions    map[string][]string
+	strictParams       bool
+	requiredFilters    []string
+	maxConditions      uint
+	maxListValues      uint
+	allErrors          bool
+	translator         Translator
+	errorHandler       ErrorHandler
+	orderBy            bool
+	jsonAPI            bool
+	odata              bool
+	pagination         PaginationMode
+	limitParamName     string
+	offsetParamName    string
+	pageParamName      string
+	perPageParamName   string
+	cursorParamName    string
+	rangeHeader        bool
+	defaultLimit       uint
+	maxLimit           uint
+	limitPolicy        LimitPolicy
+	timeLayouts        []string
+	timeLocation       *time.Location
+	now                func() time.Time
+
+	// forcedConditions are joined by AND with the conditions of params along with the ones of forcedConditionsFuncs
+	forcedConditions      []WhereCondition
+	forcedConditionsFuncs []ForcedConditionsFunc
+	// defaultSortOrder is in the syntax of the param sort_order
+	defaultSortOrder string
+	repeatedParams   RepeatedParamsPolicy
+	// rejectContradictions makes parsing fail if the where conditions have contradictions
+	rejectContradictions bool
+	// relations are the paths of Go names of the relations allowed in the param include
+	relations map[string]bool
+	// searchFields are the paths of Go names of the fields searched by the param q
+	searchFields []string
+	// intervals are the paths of Go names of the start and end fields by param names for the condition overlaps
+	intervals map[string][2]string
+	// valueTransformers are applied to values of conditions on all the fields before the ones of fieldValueTransformers
+	valueTransformers      []ValueTransformer
+	fieldValueTransformers map[string][]ValueTransformer
+	// filterRules are the ozzo-validation rules of values of conditions by the paths of Go names of the fields
+	filterRules map[string][]validation.Rule
+}
+
+type Option func(*options)
+
+// WithTagName sets the struct tag giving names of the fields in query params, e.g. "db" or "query", default is json.
+// A field without the tag is named as in Go.
+func WithTagName(tag string) Option {
+	return func(o *options) {
+		o.tagName = tag
+	}
+}
+
+// WithAliases adds aliases of fields in query params, an alias maps a param name to the path of Go names
+// of a field, e.g. "created" to "CreatedAt" or "author" to "Author.Name".
+func WithAliases(aliases map[string]string) Option {
+	return func(o *options) {
+		if o.aliases == nil {
+			o.aliases = make(map[string]string, len(aliases))
+		}
+		for alias, field := range aliases {
+			o.aliases[alias] = field
+		}
+	}
+}
+
+// WithStrictFilters allows conditions only on the fields tagged selection:"filter",
+// a condition on another field or on an unknown one makes parsing fail.
+func WithStrictFilters() Option {
+	return func(o *options) {
+		o.strictFilters = true
+	}
+}
+
+// WithStrictSort allows sorting only by the fields tagged selection:"sort",
+// sorting by another field or by an unknown one makes parsing fail.
+func WithStrictSort() Option {
+	return func(o *options) {
+		o.strictSort = true
+	}
+}
+
+// WithFieldConditions sets the only conditions allowed on fields by their paths of Go names,
+// e.g. {"Status": {"eq", "in"}}. It overrides the conditions listed in the selection tag of a field.
+func WithFieldConditions(conditions map[string][]string) Option {
+	return func(o *options) {
+		if o.fieldConditions == nil {
+			o.fieldConditions = make(map[string][]string, len(conditions))
+		}
+		for field, fieldConditions := range conditions {
+			o.fieldConditions[field] = fieldConditions
+		}
+	}
+}
+
+// WithStrictParams makes parsing fail on unknown params listing their names, by default they are skipped.
+func WithStrictParams() Option {
+	return func(o *options) {
+		o.strictParams = true
+	}
+}
+
+// WithRequiredFilters makes parsing fail if there are no conditions on the fields by their paths of Go names,
+// e.g. "TenantID". Conditions inside OR and negated groups do not count.
+func WithRequiredFilters(fields ...string) Option {
+	return func(o *options) {
+		o.requiredFilters = append(o.requiredFilters, fields...)
+	}
+}
+
+// WithDefaultSortOrder sets the sort order in the syntax of the param sort_order used if params have no sort order,
+// e.g. "created_at__desc,id" so the order of rows is always deterministic.
+func WithDefaultSortOrder(sortOrder string) Option {
+	return func(o *options) {
+		o.defaultSortOrder = sortOrder
+	}
+}
+
+// WithMaxConditions sets the maximum number of where conditions including the ones inside groups,
+// parsing fails if it is exceeded. Default is 0, it means no maximum.
+func WithMaxConditions(max uint) Option {
+	return func(o *options) {
+		o.maxConditions = max
+	}
+}
+
+// WithMaxListValues sets the maximum number of values of the condition "in", parsing fails if it is exceeded.
+// Default is 0, it means no maximum.
+func WithMaxListValues(max uint) Option {
+	return func(o *options) {
+		o.maxListValues = max
+	}
+}
+
+// WithAllErrors makes parsing go on after an error and return all the errors as Errors,
+// by default the first error is returned.
+func WithAllErrors() Option {
+	return func(o *options) {
+		o.allErrors = true
+	}
+}
+
+// WithTranslator sets the translator of the messages of parse errors.
+func WithTranslator(translator Translator) Option {
+	return func(o *options) {
+		o.translator = translator
+	}
+}
+
+// WithConditionSeparator sets the separator of a field and a condition in the name of a param, default is "__".
+func WithConditionSeparator(sep string) Option {
+	return func(o *options) {
+		o.conditionSeparator = sep
+	}
+}
+
+// WithValuesSeparator sets the separator of values of in and bt conditions and of fields of the sort order, default is ",".
+func WithValuesSeparator(sep string) Option {
+	return func(o *options) {
+		o.valuesSeparator = sep
+	}
+}
+
+// WithErrorHandler sets the handler writing the response to a request with invalid params in Middleware,
+// default is DefaultErrorHandler.
+func WithErrorHandler(handler ErrorHandler) Option {
+	return func(o *options) {
+		o.errorHandler = handler
+	}
+}
+
+// WithOrderBy enables the param order_by in the syntax of AIP-132 along with sort_order:
+//
+//	order_by=name desc, created_at
+func WithOrderBy() Option {
+	return func(o *options) {
+		o.orderBy = true
+	}
+}
+
+// WithJSONAPI enables the syntax of JSON:API, it sets the pagination by page[number] and page[size]:
+//
+//	filter[status][eq]=active&filter[age][gte]=18&sort=-created_at&page[size]=20&page[number]=2
+func WithJSONAPI() Option {
+	return func(o *options) {
+		o.jsonAPI = true
+		o.pagination = PaginationPage
+		o.pageParamName = JSONAPIPageParamName
+		o.perPageParamName = JSONAPISizeParamName
+	}
+}
+
+// WithOData enables the syntax of OData, it sets the pagination by $top and $skip:
+//
+//	$filter=price gt 10 and status eq 'open'&$orderby=price desc,name&$top=20&$skip=40
+func WithOData() Option {
+	return func(o *options) {
+		o.odata = true
+		o.pagination = PaginationLimitOffset
+		o.limitParamName = ODataTopParamName
+		o.offsetParamName = ODataSkipParamName
+	}
+}
+
+func WithLimitParamName(name string) Option {
+	return func(o *options) {
+		o.limitParamName = name
+	}
+}
+
+func WithOffsetParamName(name string) Option {
+	return func(o *options) {
+		o.offsetParamName = name
+	}
+}
+
+func WithPagination(mode PaginationMode) Option {
+	return func(o *options) {
+		o.pagination = mode
+	}
+}
+
+func WithPageParamName(name string) Option {
+	return func(o *options) {
+		o.pageParamName = name
+	}
+}
+
+func WithPerPageParamName(name string) Option {
+	return func(o *options) {
+		o.perPageParamName = name
+	}
+}
+
+func WithCursorParamName(name string) Option {
+	return func(o *options) {
+		o.cursorParamName = name
+	}
+}
+
+// WithDefaultLimit sets the limit used when a request has none.
+func WithDefaultLimit(limit uint) Option {
+	return func(o *options) {
+		o.defaultLimit = limit
+	}
+}
+
+// WithMaxLimit sets the maximum of a limit and the policy for a limit exceeding it.
+// A request without a limit gets the maximum one unless there is a default limit.
+func WithMaxLimit(limit uint, policy LimitPolicy) Option {
+	return func(o *options) {
+		o.maxLimit = limit
+		o.limitPolicy = policy
+	}
+}
+
+// WithTimeLayouts adds layouts accepted for values of time.Time fields, RFC3339 is accepted anyway.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(o *options) {
+		o.timeLayouts = append(o.timeLayouts, layouts...)
+	}
+}
+
+// WithTimeLocation sets the location of date-only values of time.Time fields, default is UTC.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.timeLocation = loc
+	}
+}
+
+// WithClock sets the function returning the current time for relative time values like "now-7d", default is time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		tagName:            DefaultTagName,
+		conditionSeparator: ConditionSeparator,
+		valuesSeparator:    ValuesSeparator,
+		pagination:         PaginationLimitOffset,
+		limitParamName:     LimitParamName,
+		offsetParamName:    OffsetParamName,
+		pageParamName:      PageParamName,
+		perPageParamName:   PerPageParamName,
+		cursorParamName:    CursorParamName,
+		timeLayouts:        append([]string(nil), DefaultTimeLayouts...),
+		timeLocation:       time.UTC,
+		now:                time.Now,
+		errorHandler:       DefaultErrorHandler,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,103 @@
+package selection_condition
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ConditionOverlaps matches intervals having common points with the interval of two values, the bounds included:
+// booked__overlaps=2024-05-01,2024-05-10. It is for range fields, arrays of two bounds like [2]time.Time,
+// and for intervals of a start and an end field set by WithIntervals.
+const ConditionOverlaps = "overlaps"
+
+// WithIntervals adds intervals of a start and an end field for the condition overlaps, an interval maps
+// a param name to the paths of Go names of the fields, e.g. {"booked": {"StartAt", "EndAt"}}.
+func WithIntervals(intervals map[string][2]string) Option {
+	return func(o *options) {
+		if o.intervals == nil {
+			o.intervals = make(map[string][2]string, len(intervals))
+		}
+		for name, fields := range intervals {
+			o.intervals[name] = fields
+		}
+	}
+}
+
+// isRangeType reports whether a field of type typ is a range of two bounds.
+func isRangeType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Len() == 2
+}
+
+// parseIntervalParam parses the condition overlaps on an interval set by WithIntervals to the group
+// of the conditions start <= to AND end >= from, it returns false if the param is not an interval.
+func parseIntervalParam(s *Schema, key string, value string) (*WhereConditionGroup, bool, error) {
+	o := s.o
+	if len(o.intervals) == 0 {
+		return nil, false, nil
+	}
+	paramName, strCond, err := splitConditionParameterName(key, o)
+	if err != nil {
+		return nil, false, err
+	}
+	interval, ok := o.intervals[paramName]
+	if !ok {
+		return nil, false, nil
+	}
+	if strCond != ConditionOverlaps {
+		return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q is not allowed for interval %s", strCond, paramName)
+	}
+
+	start, ok := s.fieldByPath(interval[0])
+	if !ok {
+		return nil, false, errors.Errorf("Unknown start field %s of interval %s", interval[0], paramName)
+	}
+	if _, ok := s.fieldByPath(interval[1]); !ok {
+		return nil, false, errors.Errorf("Unknown end field %s of interval %s", interval[1], paramName)
+	}
+
+	bounds, err := parseBounds(value, start.typ, o)
+	if err != nil {
+		return nil, false, &ErrBadValue{Field: paramName, Raw: value, Kind: start.typ.String(), Err: err}
+	}
+	return &WhereConditionGroup{
+		Logic: LogicAnd,
+		Conditions: []interface{}{
+			WhereCondition{Field: interval[0], Condition: ConditionLte, Value: bounds[1]},
+			WhereCondition{Field: interval[1], Condition: ConditionGte, Value: bounds[0]},
+		},
+	}, true, nil
+}
+
+// parseBounds parses two bounds as the ones of bt, date-only values of a time field cover whole days.
+func parseBounds(strValue string, typ reflect.Type, o *options) ([]interface{}, error) {
+	if typ == timeType {
+		value, _, ok, err := parseDateCondition(strValue, ConditionBt, o)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return value.([]interface{}), nil
+		}
+	}
+	value, err := string2valByCondition(strValue, ConditionBt, typ, o)
+	if err != nil {
+		return nil, err
+	}
+	return value.([]interface{}), nil
+}
+
+// PostgresRangeFunction returns the function making a Postgres range of bounds of the type of the bound,
+// e.g. tstzrange for a time.Time. It returns false if there is no range type for it.
+func PostgresRangeFunction(bound interface{}) (string, bool) {
+	switch bound.(type) {
+	case time.Time:
+		return "tstzrange", true
+	case int64, uint64:
+		return "int8range", true
+	case float64:
+		return "numrange", true
+	}
+	return "", false
+}
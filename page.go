@@ -0,0 +1,89 @@
+package selection_condition
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Page is the pagination metadata of a page of rows, e.g. for a response body. Total is 0 if it is unknown.
+type Page struct {
+	Total   uint `json:"total"`
+	Limit   uint `json:"limit"`
+	Offset  uint `json:"offset"`
+	HasNext bool `json:"has_next"`
+}
+
+// NewPage returns the metadata of the page selected by cond from total rows.
+func NewPage(cond *SelectionCondition, total uint) Page {
+	page := Page{Total: total}
+	if cond != nil {
+		page.Limit, page.Offset = cond.Limit, cond.Offset
+	}
+	page.HasNext = page.Limit > 0 && page.Offset+page.Limit < total
+	return page
+}
+
+// Links returns the URLs of the first, the previous, the next and the last pages by the relations "first", "prev",
+// "next" and "last", u is the URL of the request of the page. The URLs are the one of the request with other params
+// of pagination, they are relative if u is. There are no links if the page has no limit, the last one is missing
+// if the total is unknown. The options must be the ones of parsing.
+func (p Page) Links(u *url.URL, opts ...Option) map[string]string {
+	links := make(map[string]string, 4)
+	if p.Limit == 0 {
+		return links
+	}
+	o := newOptions(opts)
+
+	links["first"] = pageURL(u, p.Limit, 0, o)
+	if p.Offset > 0 {
+		prev := uint(0)
+		if p.Offset > p.Limit {
+			prev = p.Offset - p.Limit
+		}
+		links["prev"] = pageURL(u, p.Limit, prev, o)
+	}
+	if p.HasNext {
+		links["next"] = pageURL(u, p.Limit, p.Offset+p.Limit, o)
+	}
+	if p.Total > 0 {
+		links["last"] = pageURL(u, p.Limit, (p.Total-1)/p.Limit*p.Limit, o)
+	}
+	return links
+}
+
+// LinkHeader returns the value of the header Link of RFC 8288 (formerly RFC 5988) with the links of Links, e.g.
+//
+//	</orders?limit=20&offset=40>; rel="next", </orders?limit=20&offset=100>; rel="last"
+func (p Page) LinkHeader(u *url.URL, opts ...Option) string {
+	links := p.Links(u, opts...)
+	items := make([]string, 0, len(links))
+	for _, rel := range []string{"first", "prev", "next", "last"} {
+		if link, ok := links[rel]; ok {
+			items = append(items, "<"+link+`>; rel="`+rel+`"`)
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+// pageURL returns u with the params of the page of the limit and the offset instead of the ones of pagination
+// and of the cursor.
+func pageURL(u *url.URL, limit uint, offset uint, o *options) string {
+	params := u.Query()
+	params.Del(o.cursorParamName)
+	if o.pagination == PaginationPage {
+		// the offset of a page is a multiple of the limit
+		params.Set(o.perPageParamName, strconv.FormatUint(uint64(limit), 10))
+		params.Set(o.pageParamName, strconv.FormatUint(uint64(offset/limit+1), 10))
+	} else {
+		params.Set(o.limitParamName, strconv.FormatUint(uint64(limit), 10))
+		params.Del(o.offsetParamName)
+		if offset > 0 {
+			params.Set(o.offsetParamName, strconv.FormatUint(uint64(offset), 10))
+		}
+	}
+
+	res := *u
+	res.RawQuery = params.Encode()
+	return res.String()
+}
@@ -0,0 +1,65 @@
+package selection_condition
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// Parser parses query params by its options, so endpoints may have different policies, e.g.
+//
+//	var ordersParser = sc.NewParser(sc.WithStrictFilters(), sc.WithMaxLimit(100, sc.LimitClamp))
+//
+//	cond, err := ordersParser.Parse(r.URL.Query(), &Order{})
+//
+// A Parser is safe for concurrent use.
+type Parser struct {
+	o *options
+	// schemas holds the compiled schemas by the types of structs
+	schemas sync.Map
+}
+
+func NewParser(opts ...Option) *Parser {
+	return &Parser{
+		o: newOptions(opts),
+	}
+}
+
+// Parse parses params by the fields of the struct pointed by struc.
+func (p *Parser) Parse(params map[string][]string, struc interface{}) (*SelectionCondition, error) {
+	return p.ParseContext(context.Background(), params, struc)
+}
+
+// ParseContext parses params by the fields of the struct pointed by struc, ctx is passed to the functions
+// set by WithForcedConditionsFunc.
+func (p *Parser) ParseContext(ctx context.Context, params map[string][]string, struc interface{}) (*SelectionCondition, error) {
+	schema, err := p.Schema(struc)
+	if err != nil {
+		return nil, err
+	}
+	return schema.ParseContext(ctx, params)
+}
+
+// Schema returns the schema of the struct pointed by struc compiled by the options of the parser,
+// it is compiled once for each type of struct.
+func (p *Parser) Schema(struc interface{}) (*Schema, error) {
+	structType, err := getTypeOfAStruct(struc)
+	if err != nil {
+		return nil, translateError(err, p.o.translator)
+	}
+	if schema, ok := p.schemas.Load(structType); ok {
+		return schema.(*Schema), nil
+	}
+
+	schema, err := compileSchema(struc, p.o)
+	if err != nil {
+		return nil, translateError(err, p.o.translator)
+	}
+	actual, _ := p.schemas.LoadOrStore(structType, schema)
+	return actual.(*Schema), nil
+}
+
+// Parse parses params by the fields of the struct T, e.g. sc.Parse[Order](r.URL.Query())
+func Parse[T any](params url.Values, opts ...Option) (*SelectionCondition, error) {
+	return ParseQueryParams(params, new(T), opts...)
+}
@@ -0,0 +1,46 @@
+package selection_condition
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldPathSeparator separates names of nested struct fields, e.g. author.name
+const FieldPathSeparator = "."
+
+// nestedStructType returns the struct type for the path traversal through a field of type typ,
+// typ may be a struct, a pointer on a struct or a slice of them.
+func nestedStructType(typ reflect.Type) (reflect.Type, bool) {
+	typ = valueType(typ)
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = valueType(typ.Elem())
+	}
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return nil, false
+	}
+	if _, ok := converterByType(typ); ok {
+		return nil, false
+	}
+	return typ, true
+}
+
+// fieldValueByPath returns the value of the field by its path of Go names like Author.Name, it returns false
+// if the field is not found or a struct on the path is nil.
+func fieldValueByPath(structVal reflect.Value, path string) (reflect.Value, bool) {
+	for _, name := range strings.Split(path, FieldPathSeparator) {
+		structVal = reflect.Indirect(structVal)
+		if structVal.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+		field, ok := structVal.Type().FieldByName(name)
+		if !ok {
+			return reflect.Value{}, false
+		}
+		// FieldByIndexErr does not panic on a nil pointer on an embedded struct
+		var err error
+		if structVal, err = structVal.FieldByIndexErr(field.Index); err != nil {
+			return reflect.Value{}, false
+		}
+	}
+	return structVal, true
+}
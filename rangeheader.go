@@ -0,0 +1,110 @@
+package selection_condition
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// RangeHeaderName is the header of the request of a range of rows: Range: items=0-49
+	RangeHeaderName = "Range"
+	// ContentRangeHeaderName is the header of the response with the range of the rows: Content-Range: items 0-49/200
+	ContentRangeHeaderName = "Content-Range"
+	// RangeUnit is the unit of ranges of rows in the headers.
+	RangeUnit = "items"
+)
+
+// WithRangeHeader makes ParseRequest accept the header Range like "items=0-49" or "items=50-" for the pagination
+// as an alternative to its params, which take precedence over the header. The header is ignored for other units
+// of ranges, the responses should have the status 206 Partial Content with the header of Page.ContentRange.
+func WithRangeHeader() Option {
+	return func(o *options) {
+		o.rangeHeader = true
+	}
+}
+
+// ContentRange returns the value of the header Content-Range of the page with rows returned, e.g. "items 0-49/200",
+// the total is "*" if it is unknown. It is "items */200" without rows and "" without rows when the total is unknown.
+func (p Page) ContentRange(rows uint) string {
+	if rows == 0 {
+		if p.Total == 0 {
+			return ""
+		}
+		return RangeUnit + " */" + strconv.FormatUint(uint64(p.Total), 10)
+	}
+
+	total := "*"
+	if p.Total > 0 {
+		total = strconv.FormatUint(uint64(p.Total), 10)
+	}
+	return RangeUnit + " " + strconv.FormatUint(uint64(p.Offset), 10) + "-" +
+		strconv.FormatUint(uint64(p.Offset+rows-1), 10) + "/" + total
+}
+
+// rangeParams returns params with the params of the pagination of the value of the header Range unless params have
+// any of them.
+func rangeParams(header string, params url.Values, o *options) (url.Values, error) {
+	unit, spec, ok := strings.Cut(strings.TrimSpace(header), "=")
+	if !ok || strings.TrimSpace(unit) != RangeUnit {
+		return params, nil
+	}
+	for _, name := range []string{o.limitParamName, o.offsetParamName, o.pageParamName, o.perPageParamName} {
+		if params.Has(name) {
+			return params, nil
+		}
+	}
+
+	first, last, err := parseRange(strings.TrimSpace(spec))
+	if err != nil {
+		return nil, newParamError(ErrInvalidPagination, RangeHeaderName, "Header %s %q must be in the form %s=first-last: %s", RangeHeaderName, header, RangeUnit, err)
+	}
+
+	res := make(url.Values, len(params)+2)
+	for key, vals := range params {
+		res[key] = vals
+	}
+	if o.pagination == PaginationPage {
+		// a page has the offset of a multiple of its size
+		if last < 0 || first%(last-first+1) != 0 {
+			return nil, newParamError(ErrInvalidPagination, RangeHeaderName, "Header %s %q must be a whole page", RangeHeaderName, header)
+		}
+		size := last - first + 1
+		res.Set(o.perPageParamName, strconv.FormatInt(size, 10))
+		res.Set(o.pageParamName, strconv.FormatInt(first/size+1, 10))
+		return res, nil
+	}
+	if first > 0 {
+		res.Set(o.offsetParamName, strconv.FormatInt(first, 10))
+	}
+	if last >= 0 {
+		res.Set(o.limitParamName, strconv.FormatInt(last-first+1, 10))
+	}
+	return res, nil
+}
+
+// parseRange returns the first and the last positions of the range like "0-49", the last one is -1 for an open range
+// like "50-".
+func parseRange(spec string) (int64, int64, error) {
+	if strings.Contains(spec, ",") {
+		return 0, 0, errors.New("multiple ranges are not supported")
+	}
+	strFirst, strLast, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, errors.New("no separator -")
+	}
+	first, err := strconv.ParseInt(strFirst, 10, 64)
+	if err != nil || first < 0 {
+		return 0, 0, errors.New("the first position must be a non-negative integer")
+	}
+	if strLast == "" {
+		return first, -1, nil
+	}
+	last, err := strconv.ParseInt(strLast, 10, 64)
+	if err != nil || last < first {
+		return 0, 0, errors.New("the last position must be an integer not less than the first one")
+	}
+	return first, last, nil
+}
@@ -0,0 +1,135 @@
+package selection_condition
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ArrayParamSuffix marks a param in the array syntax of PHP and Rails: tags[]=a&tags[]=b
+const ArrayParamSuffix = "[]"
+
+// RepeatedParamsPolicy decides how a condition param repeated in a query like status=new&status=open is parsed.
+type RepeatedParamsPolicy int
+
+const (
+	// RepeatedParamsCombine joins the values of eq and in params to one condition "in": status=new&status=open
+	// is status in (new, open), the values of other conditions become conditions joined by AND:
+	// age__gt=18&age__gt=21 is age > 18 AND age > 21.
+	RepeatedParamsCombine RepeatedParamsPolicy = iota
+	// RepeatedParamsFirst takes the first value and skips the others.
+	RepeatedParamsFirst
+	// RepeatedParamsReject makes parsing fail if a param is repeated.
+	RepeatedParamsReject
+)
+
+// WithRepeatedParams sets the policy for repeated condition params, default is RepeatedParamsCombine.
+func WithRepeatedParams(policy RepeatedParamsPolicy) Option {
+	return func(o *options) {
+		o.repeatedParams = policy
+	}
+}
+
+// parseRepeatedWhereParam parses the condition param with all its values, it returns the conditions and groups
+// joined by AND.
+func parseRepeatedWhereParam(s *Schema, key string, vals []string) ([]interface{}, bool, error) {
+	o := s.o
+	if len(vals) > 1 {
+		switch o.repeatedParams {
+		case RepeatedParamsFirst:
+			vals = vals[:1]
+		case RepeatedParamsReject:
+			return nil, false, newParamError(ErrInvalidParam, key, "Parameter %s must not be repeated", key)
+		case RepeatedParamsCombine:
+		default:
+			return nil, false, errors.Errorf("Unknown policy of repeated parameters %d", o.repeatedParams)
+		}
+	}
+
+	// the conditions on an interval are groups joined by AND
+	groups := make([]interface{}, 0, len(vals))
+	for _, val := range vals {
+		group, ok, err := parseIntervalParam(s, key, val)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			break
+		}
+		groups = append(groups, *group)
+	}
+	if len(groups) > 0 {
+		return groups, true, nil
+	}
+	return combineWhereParam(s, key, vals, false)
+}
+
+// parseArrayWhereParam parses the param in the array syntax of PHP and Rails: tags[]=a&tags[]=b is tags in (a, b)
+// whatever the policy for repeated params is.
+func parseArrayWhereParam(s *Schema, key string, vals []string) ([]interface{}, bool, error) {
+	o := s.o
+	key = strings.TrimSuffix(key, ArrayParamSuffix)
+	if _, strCond, err := splitConditionParameterName(key, o); err != nil {
+		return nil, false, err
+	} else if strCond != ConditionEq && strCond != ConditionIn {
+		return nil, false, newParamError(ErrInvalidOperator, key, "Condition %q is not allowed for parameter %s%s", strCond, key, ArrayParamSuffix)
+	}
+	return combineWhereParam(s, key, vals, true)
+}
+
+// combineWhereParam parses the values of the param, the values of eq and in are joined to one condition "in"
+// which is made even for a single value if inList is true, the values of other conditions are joined by AND.
+func combineWhereParam(s *Schema, key string, vals []string, inList bool) ([]interface{}, bool, error) {
+	o := s.o
+	conditions := make([]WhereCondition, 0, len(vals))
+	for _, val := range vals {
+		whereCondition, ok, err := parseWhereParam(s, key, []string{val})
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+		conditions = append(conditions, *whereCondition)
+	}
+	if len(conditions) == 1 && !inList {
+		return []interface{}{conditions[0]}, true, nil
+	}
+
+	paramName, strCond, _ := splitConditionParameterName(key, o)
+	if strCond != ConditionEq && strCond != ConditionIn {
+		items := make([]interface{}, 0, len(conditions))
+		for _, c := range conditions {
+			items = append(items, c)
+		}
+		return items, true, nil
+	}
+
+	values := make([]interface{}, 0, len(conditions))
+	for _, c := range conditions {
+		switch c.Condition {
+		case ConditionEq:
+			values = append(values, c.Value)
+		case ConditionIn:
+			values = append(values, c.Value.([]interface{})...)
+		default:
+			// a date is parsed to a range, so the ranges of the dates are joined by OR
+			group := WhereConditionGroup{Logic: LogicOr, Conditions: make([]interface{}, 0, len(conditions))}
+			for _, c := range conditions {
+				group.Conditions = append(group.Conditions, c)
+			}
+			return []interface{}{group}, true, nil
+		}
+	}
+	// the field of a JSON path is the JSON one, so it is got by the path of the condition
+	if field, ok := s.fieldByPath(conditions[0].Field); ok && !s.isConditionAllowed(conditions[0].Field, field, ConditionIn) {
+		return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q is not allowed for field %s", ConditionIn, paramName)
+	}
+	if o.maxListValues > 0 && uint(len(values)) > o.maxListValues {
+		return nil, false, newParamError(ErrTooManyValues, paramName, "Condition %q accepts at most %d values, got %d", ConditionIn, o.maxListValues, len(values))
+	}
+	sliceSort(values)
+	return []interface{}{WhereCondition{
+		Field:     conditions[0].Field,
+		Condition: ConditionIn,
+		Value:     values,
+		JSONPath:  conditions[0].JSONPath,
+	}}, true, nil
+}
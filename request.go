@@ -0,0 +1,29 @@
+package selection_condition
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// ParseRequest parses the query params of the request by the fields of the struct pointed by model.
+func ParseRequest(r *http.Request, model interface{}, opts ...Option) (*SelectionCondition, error) {
+	return NewParser(opts...).ParseRequest(r, model)
+}
+
+// ParseURLValues parses v by the fields of the struct pointed by model.
+func ParseURLValues(v url.Values, model interface{}, opts ...Option) (*SelectionCondition, error) {
+	return ParseQueryParams(v, model, opts...)
+}
+
+// ParseRequest parses the query params of the request by the fields of the struct pointed by model,
+// the header Range is parsed too if the parser is made with WithRangeHeader.
+func (p *Parser) ParseRequest(r *http.Request, model interface{}) (*SelectionCondition, error) {
+	params := r.URL.Query()
+	if p.o.rangeHeader {
+		var err error
+		if params, err = rangeParams(r.Header.Get(RangeHeaderName), params, p.o); err != nil {
+			return nil, translateError(err, p.o.translator)
+		}
+	}
+	return p.ParseContext(r.Context(), params, model)
+}
@@ -0,0 +1,60 @@
+package selection_condition
+
+// Rewrite returns a copy of cond with each where condition including the ones inside groups replaced by the result
+// of fn, e.g. to rename a field or to downgrade a condition for a former version of an API. A condition is removed
+// if fn returns false and a group left without conditions is removed too. Removing a condition joined by AND widens
+// the selection and removing one joined by OR narrows it, inside a negated group it is the other way round.
+// cond itself is not changed.
+func Rewrite(cond *SelectionCondition, fn func(WhereCondition) (WhereCondition, bool)) *SelectionCondition {
+	if cond == nil {
+		return nil
+	}
+	res := cond.Clone()
+	res.Where = rewriteWhere(res.Where, fn)
+	return res
+}
+
+func rewriteWhere(where Where, fn func(WhereCondition) (WhereCondition, bool)) Where {
+	switch w := where.(type) {
+	case WhereConditions:
+		res := make(WhereConditions, 0, len(w))
+		for _, c := range w {
+			if c, ok := fn(c); ok {
+				res = append(res, c)
+			}
+		}
+		return res
+	case WhereCondition:
+		if c, ok := fn(w); ok {
+			return c
+		}
+		return WhereConditions{}
+	case WhereConditionGroup:
+		if group, ok := rewriteGroup(w, fn); ok {
+			return group
+		}
+		return WhereConditions{}
+	}
+	return where
+}
+
+// rewriteGroup rewrites the conditions of the group, it returns false if no conditions are left.
+func rewriteGroup(group WhereConditionGroup, fn func(WhereCondition) (WhereCondition, bool)) (WhereConditionGroup, bool) {
+	items := make([]interface{}, 0, len(group.Conditions))
+	for _, item := range group.Conditions {
+		switch c := item.(type) {
+		case WhereCondition:
+			if c, ok := fn(c); ok {
+				items = append(items, c)
+			}
+		case WhereConditionGroup:
+			if nested, ok := rewriteGroup(c, fn); ok {
+				items = append(items, nested)
+			}
+		default:
+			items = append(items, item)
+		}
+	}
+	group.Conditions = items
+	return group, len(items) > 0
+}
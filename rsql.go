@@ -0,0 +1,293 @@
+package selection_condition
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// RSQLFilterParamName is the param of a filter in the RSQL syntax, it is the name of the param in errors.
+const RSQLFilterParamName = "filter"
+
+// rsqlOperators maps comparison operators of RSQL to conditions, "!=" is the negated eq and "=out=" is the negated in.
+var rsqlOperators = map[string]string{
+	"==":    ConditionEq,
+	"!=":    ConditionEq,
+	"=lt=":  ConditionLt,
+	"<":     ConditionLt,
+	"=le=":  ConditionLte,
+	"<=":    ConditionLte,
+	"=gt=":  ConditionGt,
+	">":     ConditionGt,
+	"=ge=":  ConditionGte,
+	">=":    ConditionGte,
+	"=in=":  ConditionIn,
+	"=out=": ConditionIn,
+}
+
+// rsqlReserved are the characters which cannot be in a field or in a value which is not quoted.
+const rsqlReserved = "\"'();,=!~<> "
+
+// ParseRSQL parses the filter in the syntax of RSQL/FIQL by the fields of the struct pointed by struc, e.g.
+//
+//	status=in=(open,new);age=gt=30,name=="John Smith"
+//
+// ";" is AND, "," is OR and binds weaker than AND. It returns the condition with Where only, Where is
+// WhereConditions if all conditions are joined by AND otherwise it is a WhereConditionGroup.
+// Wildcards of values like name==jo* are not supported, a value with * fails with ErrInvalidFilter unless
+// the star is escaped by a backslash in a quoted value: name=="jo\*" is jo*.
+func ParseRSQL(filter string, struc interface{}, opts ...Option) (*SelectionCondition, error) {
+	schema, err := NewParser(opts...).Schema(struc)
+	if err != nil {
+		return nil, err
+	}
+	return schema.ParseRSQL(filter)
+}
+
+// ParseRSQL parses the filter in the syntax of RSQL/FIQL by the fields of the schema.
+func (s *Schema) ParseRSQL(filter string) (*SelectionCondition, error) {
+	return s.ParseRSQLContext(context.Background(), filter)
+}
+
+// ParseRSQLContext is ParseRSQL with ctx passed to the functions set by WithForcedConditionsFunc.
+func (s *Schema) ParseRSQLContext(ctx context.Context, filter string) (*SelectionCondition, error) {
+	conditions, err := parseRSQL(s, filter)
+	if err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	errs := &errorCollector{all: s.o.allErrors}
+	if err := checkWhere(ctx, s, conditions, errs); err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	if err := errs.err(); err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	return conditions, nil
+}
+
+func parseRSQL(s *Schema, filter string) (*SelectionCondition, error) {
+	p := &rsqlParser{schema: s, filter: filter, runes: []rune(filter)}
+	if p.skipSpaces(); p.end() {
+		return &SelectionCondition{Where: WhereConditions{}}, nil
+	}
+
+	where, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if !p.end() {
+		return nil, p.errorf("unexpected %q", string(p.runes[p.pos]))
+	}
+	return &SelectionCondition{Where: asWhere(aipWhere(where))}, nil
+}
+
+// rsqlParser parses the filter by the grammar of RSQL:
+//
+//	or         = and {"," and}
+//	and        = constraint {";" constraint}
+//	constraint = "(" or ")" | selector operator (value | "(" value {"," value} ")")
+type rsqlParser struct {
+	schema *Schema
+	filter string
+	runes  []rune
+	pos    int
+}
+
+func (p *rsqlParser) end() bool {
+	return p.pos >= len(p.runes)
+}
+
+func (p *rsqlParser) skipSpaces() {
+	for !p.end() && unicode.IsSpace(p.runes[p.pos]) {
+		p.pos++
+	}
+}
+
+// char skips the character r and the spaces around it, it returns false if there is no r.
+func (p *rsqlParser) char(r rune) bool {
+	p.skipSpaces()
+	if p.end() || p.runes[p.pos] != r {
+		return false
+	}
+	p.pos++
+	p.skipSpaces()
+	return true
+}
+
+func (p *rsqlParser) errorf(format string, args ...interface{}) error {
+	return newParamError(ErrInvalidFilter, RSQLFilterParamName, "Invalid filter %q: "+format, append([]interface{}{p.filter}, args...)...)
+}
+
+func (p *rsqlParser) or() (interface{}, error) {
+	var items []interface{}
+	for {
+		item, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.char(',') {
+			return aipGroup(LogicOr, items), nil
+		}
+	}
+}
+
+func (p *rsqlParser) and() (interface{}, error) {
+	var items []interface{}
+	for {
+		item, err := p.constraint()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if !p.char(';') {
+			return aipGroup(LogicAnd, items), nil
+		}
+	}
+}
+
+func (p *rsqlParser) constraint() (interface{}, error) {
+	if p.char('(') {
+		item, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.char(')') {
+			return nil, p.errorf("missing %q", ")")
+		}
+		return item, nil
+	}
+	return p.comparison()
+}
+
+func (p *rsqlParser) comparison() (interface{}, error) {
+	field := p.unreserved()
+	if field == "" {
+		return nil, p.errorf("field expected at %d", p.pos)
+	}
+
+	operator := p.operator()
+	condition, ok := rsqlOperators[operator]
+	if !ok {
+		return nil, p.errorf("unknown operator %q after %q", operator, field)
+	}
+
+	var values []string
+	if p.char('(') {
+		for {
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if p.char(')') {
+				break
+			}
+			if !p.char(',') {
+				return nil, p.errorf("%q or %q expected in list of values of %q", ",", ")", field)
+			}
+		}
+	} else {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if condition != ConditionIn && len(values) > 1 {
+		return nil, p.errorf("operator %q of %q takes a single value", operator, field)
+	}
+
+	key := field + p.schema.o.conditionSeparator + condition
+	var cond *WhereCondition
+	var err error
+	if condition == ConditionIn {
+		// the values are converted apart as they may have the separator of values
+		cond, ok, err = parseWhereList(p.schema, key, values)
+	} else {
+		cond, ok, err = parseWhereParam(p.schema, key, values)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, newParamError(ErrUnknownField, field, "Unknown field %s", field)
+	}
+
+	if operator == "!=" || operator == "=out=" {
+		return aipNot(*cond), nil
+	}
+	return *cond, nil
+}
+
+// unreserved returns the characters up to a reserved one.
+func (p *rsqlParser) unreserved() string {
+	p.skipSpaces()
+	start := p.pos
+	for !p.end() && !strings.ContainsRune(rsqlReserved, p.runes[p.pos]) && !unicode.IsSpace(p.runes[p.pos]) {
+		p.pos++
+	}
+	return string(p.runes[start:p.pos])
+}
+
+// operator returns the comparison operator like "==", "<=" or "=gt=".
+func (p *rsqlParser) operator() string {
+	p.skipSpaces()
+	start := p.pos
+	switch {
+	case p.end():
+		return ""
+	case p.runes[p.pos] == '=':
+		// == or =name=
+		p.pos++
+		for !p.end() && unicode.IsLetter(p.runes[p.pos]) {
+			p.pos++
+		}
+		if !p.end() && p.runes[p.pos] == '=' {
+			p.pos++
+		}
+	case strings.ContainsRune("!<>", p.runes[p.pos]):
+		p.pos++
+		if !p.end() && p.runes[p.pos] == '=' {
+			p.pos++
+		}
+	}
+	return string(p.runes[start:p.pos])
+}
+
+func (p *rsqlParser) value() (string, error) {
+	p.skipSpaces()
+	if p.end() {
+		return "", p.errorf("unexpected end")
+	}
+
+	quote := p.runes[p.pos]
+	if quote != '"' && quote != '\'' {
+		value := p.unreserved()
+		if value == "" {
+			return "", p.errorf("value expected at %d", p.pos)
+		}
+		if strings.Contains(value, "*") {
+			return "", p.errorf("wildcards are not supported in %q, a literal * is escaped by a backslash in a quoted value", value)
+		}
+		return value, nil
+	}
+
+	var b strings.Builder
+	for p.pos++; !p.end() && p.runes[p.pos] != quote; p.pos++ {
+		switch {
+		case p.runes[p.pos] == '\\' && p.pos+1 < len(p.runes):
+			p.pos++
+		case p.runes[p.pos] == '*':
+			// a star is a wildcard in RSQL, so it is not taken as a literal one
+			return "", p.errorf("wildcards are not supported, a literal * is escaped by a backslash")
+		}
+		b.WriteRune(p.runes[p.pos])
+	}
+	if p.end() {
+		return "", p.errorf("unterminated string")
+	}
+	p.pos++
+	return b.String(), nil
+}
@@ -0,0 +1,116 @@
+package selection_condition
+
+import "testing"
+
+func TestParseRSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		opts   []Option
+		// params are the query params of the same conditions
+		params map[string][]string
+		// want is set for conditions which cannot be in query params
+		want *SelectionCondition
+		err  error
+	}{
+		{
+			name:   "empty",
+			filter: " ",
+			params: map[string][]string{},
+		},
+		{
+			name:   "comparisons",
+			filter: `status==open;amount=ge=10;quantity<5;author.name=="John Smith"`,
+			params: map[string][]string{"status": {"open"}, "amount__gte": {"10"}, "quantity__lt": {"5"}, "author.name": {"John Smith"}},
+		},
+		{
+			name:   "and binds tighter than or",
+			filter: `status==open,status==new;quantity=lt=5`,
+			params: map[string][]string{"or": {"(status=open,and(status=new,quantity__lt=5))"}},
+		},
+		{
+			name:   "parentheses",
+			filter: `(status==open,amount=gt=10);quantity=lt=5`,
+			params: map[string][]string{"or": {"(status=open,amount__gt=10)"}, "quantity__lt": {"5"}},
+		},
+		{
+			name:   "in",
+			filter: `status=in=(new,open);id=in=(1,2)`,
+			params: map[string][]string{"status__in": {"new,open"}, "id__in": {"1,2"}},
+		},
+		{
+			name:   "quoted comma in a list",
+			filter: `author.name=in=("Smith, John",Doe)`,
+			want: &SelectionCondition{Where: WhereConditions{
+				{Field: "Author.Name", Condition: ConditionIn, Value: []interface{}{"Doe", "Smith, John"}},
+			}},
+		},
+		{
+			name:   "negations",
+			filter: `status!=archived;id=out=(1,2)`,
+			params: map[string][]string{"not": {"(status=archived)", "(id__in=(1,2))"}},
+		},
+		{
+			name:   "escaped star",
+			filter: `author.name=="jo\*"`,
+			params: map[string][]string{"author.name": {"jo*"}},
+		},
+		{
+			name:   "wildcard",
+			filter: `author.name==jo*`,
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "unknown operator",
+			filter: `status=like=open`,
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "unbalanced parentheses",
+			filter: `(status==open`,
+			err:    ErrInvalidFilter,
+		},
+		{
+			name:   "bad value",
+			filter: `quantity==many`,
+			err:    &ErrBadValue{},
+		},
+		{
+			name:   "max conditions",
+			filter: `status==open;amount=gt=10`,
+			opts:   []Option{WithMaxConditions(1)},
+			err:    ErrTooManyConditions,
+		},
+		{
+			name:   "required filter",
+			filter: `amount=gt=10`,
+			opts:   []Option{WithRequiredFilters("Status")},
+			err:    ErrRequiredFilter,
+		},
+		{
+			name:   "contradiction",
+			filter: `amount=gt=10;amount=lt=5`,
+			opts:   []Option{WithRejectContradictions()},
+			err:    ErrContradiction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRSQL(tt.filter, &testOrder{}, tt.opts...)
+			checkError(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			want := tt.want
+			if want == nil {
+				if want, err = ParseQueryParams(tt.params, &testOrder{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !got.Equal(want) {
+				t.Errorf("got %s, want %s", got.normalizedJSON(), want.normalizedJSON())
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package selection_condition
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pkg/errors"
+)
+
+// FilterRulesProvider is implemented by models declaring the ozzo-validation rules of values of conditions
+// on their fields by the paths of Go names, e.g.
+//
+//	func (Order) FilterRules() map[string][]validation.Rule {
+//		return map[string][]validation.Rule{"Total": {validation.Min(0)}, "Code": {validation.Length(3, 3)}}
+//	}
+type FilterRulesProvider interface {
+	FilterRules() map[string][]validation.Rule
+}
+
+// WithFilterRules adds ozzo-validation rules of values of conditions on fields by their paths of Go names
+// along with the ones of the model implementing FilterRulesProvider.
+func WithFilterRules(rules map[string][]validation.Rule) Option {
+	return func(o *options) {
+		if o.filterRules == nil {
+			o.filterRules = make(map[string][]validation.Rule, len(rules))
+		}
+		for field, fieldRules := range rules {
+			o.filterRules[field] = append(o.filterRules[field], fieldRules...)
+		}
+	}
+}
+
+// compileFilterRules returns the rules of the fields by their paths of Go names, the ones of the model
+// followed by the ones of the options.
+func compileFilterRules(struc interface{}, root *structSchema, o *options) (map[string][]validation.Rule, error) {
+	var modelRules map[string][]validation.Rule
+	if provider, ok := struc.(FilterRulesProvider); ok {
+		modelRules = provider.FilterRules()
+	}
+	if len(modelRules) == 0 && len(o.filterRules) == 0 {
+		return nil, nil
+	}
+
+	res := make(map[string][]validation.Rule, len(modelRules)+len(o.filterRules))
+	for _, rules := range []map[string][]validation.Rule{modelRules, o.filterRules} {
+		for path, fieldRules := range rules {
+			if _, _, ok := root.field(path, true); !ok {
+				return nil, errors.Errorf("Unknown field %s of filter rules", path)
+			}
+			res[path] = append(res[path], fieldRules...)
+		}
+	}
+	return res, nil
+}
+
+// isRuleCondition reports whether the values of the condition are checked by the rules of the field,
+// the values of other conditions like ilike or near are not the ones of the field.
+func isRuleCondition(condition string) bool {
+	switch condition {
+	case ConditionEq, ConditionIEq, ConditionGt, ConditionGte, ConditionLt, ConditionLte,
+		ConditionIn, ConditionBt, ConditionBtExcl, ConditionAny, ConditionAll, ConditionOverlaps:
+		return true
+	}
+	return false
+}
+
+// validateFilterValue validates the value and each value of a list by the rules.
+func validateFilterValue(rules []validation.Rule, value interface{}) error {
+	values, ok := value.([]interface{})
+	if !ok {
+		return validation.Validate(value, rules...)
+	}
+	for _, v := range values {
+		if err := validation.Validate(v, rules...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package scbun
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+
+	sc "github.com/minipkg/selection_condition"
+	"github.com/minipkg/selection_condition/internal/naming"
+)
+
+type config struct {
+	columnName func(field string) string
+}
+
+type Option func(*config)
+
+// WithColumnName sets the function that maps a struct field name to a column name. Default is snake_case as in bun.
+func WithColumnName(fn func(field string) string) Option {
+	return func(c *config) {
+		c.columnName = fn
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		columnName: naming.SnakeCase,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ApplyToBun adds the columns of the projection with DISTINCT or of the grouping with its aggregates and the conditions on them,
+// the relations, where conditions, sort order, limit and offset of cond to q.
+// An error is stored in the query and returned on its execution.
+func ApplyToBun(q *bun.SelectQuery, cond *sc.SelectionCondition, opts ...Option) *bun.SelectQuery {
+	if cond == nil {
+		return q
+	}
+	c := newConfig(opts)
+
+	if len(cond.GroupBy) == 0 && len(cond.Aggregates) == 0 {
+		for _, field := range cond.Fields {
+			q = q.Column(c.columnName(field))
+		}
+	}
+	for _, field := range cond.GroupBy {
+		q = q.Column(c.columnName(field)).Group(c.columnName(field))
+	}
+	if cond.Distinct {
+		q = q.Distinct()
+	}
+	for _, aggregate := range cond.Aggregates {
+		alias := aggregate.Func
+		if aggregate.Field != "" {
+			alias = naming.AggregateAlias(aggregate.Func, c.columnName(aggregate.Field))
+		}
+		q = q.ColumnExpr("? AS ?", c.aggregate(aggregate), bun.Ident(alias))
+	}
+	for _, having := range cond.Having {
+		query, args, err := c.condition(c.aggregate(having.Aggregate), sc.WhereCondition{
+			Field:     having.Func + sc.AggregateFieldSeparator + having.Field,
+			Condition: having.Condition,
+			Value:     having.Value,
+		})
+		if err != nil {
+			return q.Err(err)
+		}
+		q = q.Having(query, args...)
+	}
+
+	for _, relation := range cond.Include {
+		q = q.Relation(relation)
+	}
+
+	q, err := c.where(q, cond.Where)
+	if err != nil {
+		return q.Err(err)
+	}
+
+	for _, sortField := range cond.SortOrder {
+		expr := "?"
+		if sortField.CaseInsensitive {
+			expr = "LOWER(?)"
+		}
+		q = q.OrderExpr(expr+orderModifiers(sortField), bun.Ident(c.columnName(sortField.Field)))
+	}
+
+	// values above the maximum of int are clamped instead of wrapping to negative ones
+	if cond.Limit > 0 {
+		q = q.Limit(int(min(cond.Limit, math.MaxInt)))
+	}
+	if cond.Offset > 0 {
+		q = q.Offset(int(min(cond.Offset, math.MaxInt)))
+	}
+	return q
+}
+
+// ApplyCountToBun adds the conditions of cond counting the rows it selects to q, the rows are counted
+// by q.Count(ctx), e.g. for the total of with_count=true.
+func ApplyCountToBun(q *bun.SelectQuery, cond *sc.SelectionCondition, opts ...Option) *bun.SelectQuery {
+	if cond == nil {
+		return q
+	}
+	return ApplyToBun(q, cond.ForCount(), opts...)
+}
+
+func (c *config) where(q *bun.SelectQuery, where interface{}) (*bun.SelectQuery, error) {
+	switch w := where.(type) {
+	case nil:
+		return q, nil
+	case sc.WhereCondition:
+		return c.whereConditions(q, []sc.WhereCondition{w})
+	case sc.WhereConditions:
+		return c.whereConditions(q, w)
+	case sc.WhereConditionGroup:
+		query, args, err := c.group(w)
+		if err != nil {
+			return q, err
+		}
+		return q.Where(query, args...), nil
+	default:
+		return q, errors.Errorf("Unsupported type of Where: %T", where)
+	}
+}
+
+func (c *config) whereConditions(q *bun.SelectQuery, conds []sc.WhereCondition) (*bun.SelectQuery, error) {
+	for _, cond := range conds {
+		query, args, err := c.whereCondition(cond)
+		if err != nil {
+			return q, err
+		}
+		q = q.Where(query, args...)
+	}
+	return q, nil
+}
+
+func (c *config) group(group sc.WhereConditionGroup) (query string, args []interface{}, err error) {
+	var sep string
+	switch group.Logic {
+	case sc.LogicAnd:
+		sep = " AND "
+	case sc.LogicOr:
+		sep = " OR "
+	default:
+		return "", nil, errors.Errorf("Unsupported logic of a group: %q", group.Logic)
+	}
+
+	queries := make([]string, 0, len(group.Conditions))
+	for _, item := range group.Conditions {
+		var itemQuery string
+		var itemArgs []interface{}
+
+		switch cond := item.(type) {
+		case sc.WhereCondition:
+			itemQuery, itemArgs, err = c.whereCondition(cond)
+		case sc.WhereConditionGroup:
+			itemQuery, itemArgs, err = c.group(cond)
+		default:
+			err = errors.Errorf("Unsupported type of a group condition: %T", item)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		queries = append(queries, "("+itemQuery+")")
+		args = append(args, itemArgs...)
+	}
+
+	query = strings.Join(queries, sep)
+	if group.Not {
+		query = "NOT (" + query + ")"
+	}
+	return query, args, nil
+}
+
+func (c *config) whereCondition(cond sc.WhereCondition) (query string, args []interface{}, err error) {
+	if cond.JSONPath != "" {
+		return c.condition(c.jsonPath(cond), cond)
+	}
+	return c.condition(bun.Ident(c.columnName(cond.Field)), cond)
+}
+
+// jsonPath returns the expression of the value of the column at the JSON path of the condition as text,
+// e.g. "metadata"->'plan'->>'name'.
+func (c *config) jsonPath(cond sc.WhereCondition) schema.QueryWithArgs {
+	keys := strings.Split(cond.JSONPath, sc.FieldPathSeparator)
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, bun.Ident(c.columnName(cond.Field)))
+	query := "?" + strings.Repeat("->?", len(keys)-1) + "->>?"
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return bun.SafeQuery(query, args...)
+}
+
+// aggregate returns the expression of the aggregate, e.g. SUM("amount") or COUNT(*).
+func (c *config) aggregate(aggregate sc.Aggregate) schema.QueryWithArgs {
+	if aggregate.Field == "" {
+		return bun.SafeQuery(strings.ToUpper(aggregate.Func) + "(*)")
+	}
+	return bun.SafeQuery(strings.ToUpper(aggregate.Func)+"(?)", bun.Ident(c.columnName(aggregate.Field)))
+}
+
+// condition returns the condition on the column which is an identifier or an expression like SUM("amount").
+func (c *config) condition(column interface{}, cond sc.WhereCondition) (query string, args []interface{}, err error) {
+	switch cond.Condition {
+	case sc.ConditionEq:
+		return "? = ?", []interface{}{column, cond.Value}, nil
+	case sc.ConditionGt:
+		return "? > ?", []interface{}{column, cond.Value}, nil
+	case sc.ConditionGte:
+		return "? >= ?", []interface{}{column, cond.Value}, nil
+	case sc.ConditionLt:
+		return "? < ?", []interface{}{column, cond.Value}, nil
+	case sc.ConditionLte:
+		return "? <= ?", []interface{}{column, cond.Value}, nil
+	case sc.ConditionIn:
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a slice", cond.Condition, cond.Field)
+		}
+		return "? IN (?)", []interface{}{column, bun.In(values)}, nil
+	case sc.ConditionBt:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return "? BETWEEN ? AND ?", []interface{}{column, values[0], values[1]}, nil
+	case sc.ConditionFuzzy:
+		value, ok := cond.Value.(sc.Fuzzy)
+		if !ok {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a sc.Fuzzy", cond.Condition, cond.Field)
+		}
+		return "similarity(?, ?) >= ?", []interface{}{column, value.Text, value.Similarity}, nil
+	case sc.ConditionAny, sc.ConditionAll:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a non-empty slice", cond.Condition, cond.Field)
+		}
+		return "? " + arrayOperator(cond.Condition) + " ARRAY[?]", []interface{}{column, bun.In(values)}, nil
+	case sc.ConditionJSONContains:
+		document, err := json.Marshal(cond.Value)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "Value of condition %q for field %s", cond.Condition, cond.Field)
+		}
+		return "? @> CAST(? AS jsonb)", []interface{}{column, string(document)}, nil
+	case sc.ConditionNear:
+		value, ok := cond.Value.(sc.GeoNear)
+		if !ok {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoNear", cond.Condition, cond.Field)
+		}
+		return "ST_DWithin(CAST(? AS geography), CAST(ST_SetSRID(ST_MakePoint(?, ?), 4326) AS geography), ?)",
+			[]interface{}{column, value.Point.Lon, value.Point.Lat, value.Radius}, nil
+	case sc.ConditionBBox:
+		value, ok := cond.Value.(sc.GeoBoundingBox)
+		if !ok {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoBoundingBox", cond.Condition, cond.Field)
+		}
+		return "ST_Intersects(CAST(? AS geometry), ST_MakeEnvelope(?, ?, ?, ?, 4326))",
+			[]interface{}{column, value.Min.Lon, value.Min.Lat, value.Max.Lon, value.Max.Lat}, nil
+	case sc.ConditionOverlaps:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		rangeFunction, ok := sc.PostgresRangeFunction(values[0])
+		if !ok {
+			return "", nil, errors.Errorf("Bounds of type %T of condition %q for field %s are not supported", values[0], cond.Condition, cond.Field)
+		}
+		return "? && " + rangeFunction + "(?, ?, '[]')", []interface{}{column, values[0], values[1]}, nil
+	case sc.ConditionBtExcl:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return "? > ? AND ? < ?", []interface{}{column, values[0], column, values[1]}, nil
+	case sc.ConditionIEq:
+		return "LOWER(?) = LOWER(?)", []interface{}{column, cond.Value}, nil
+	case sc.ConditionILike:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return "", nil, errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		return "LOWER(?) LIKE LOWER(?) ESCAPE '" + sc.LikeEscape + "'", []interface{}{column, sc.ContainsPattern(value)}, nil
+	}
+	return "", nil, errors.Errorf("Condition %q is not supported by bun adapter", cond.Condition)
+}
+
+// arrayOperator returns the operator of Postgres arrays for the condition any or all.
+func arrayOperator(condition string) string {
+	if condition == sc.ConditionAny {
+		return "&&"
+	}
+	return "@>"
+}
+
+// orderModifiers returns the direction of the field with the placement of nulls, e.g. " DESC NULLS LAST".
+func orderModifiers(sortField sc.SortField) string {
+	res := " ASC"
+	if sortField.Direction == sc.SortOrderDesc {
+		res = " DESC"
+	}
+	switch sortField.Nulls {
+	case sc.NullsFirst:
+		res += " NULLS FIRST"
+	case sc.NullsLast:
+		res += " NULLS LAST"
+	}
+	return res
+}
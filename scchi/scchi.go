@@ -0,0 +1,98 @@
+package scchi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	sc "github.com/minipkg/selection_condition"
+)
+
+// Middleware parses and validates the query params of a request by the fields of the struct pointed by model
+// and passes the condition in the request context, it is got by FromContext. If the params are invalid
+// the response is written by RenderError unless another error handler is set by sc.WithErrorHandler.
+func Middleware(model interface{}, opts ...sc.Option) func(http.Handler) http.Handler {
+	return sc.NewParser(append([]sc.Option{sc.WithErrorHandler(RenderError)}, opts...)...).Middleware(model)
+}
+
+// With returns the router with Middleware for inline use:
+//
+//	scchi.With(r, &Order{}).Get("/orders", listOrders)
+func With(r chi.Router, model interface{}, opts ...sc.Option) chi.Router {
+	return r.With(Middleware(model, opts...))
+}
+
+// FromContext returns the condition passed by Middleware.
+func FromContext(ctx context.Context) (*sc.SelectionCondition, bool) {
+	return sc.FromContext(ctx)
+}
+
+// RenderError writes err as JSON with the status 400 Bad Request,
+// the code is set for a parse error: {"error": "Unknown field foo", "code": "unknown_field"}
+func RenderError(w http.ResponseWriter, r *http.Request, err error) {
+	res := map[string]interface{}{"error": err.Error()}
+
+	var codedErr sc.CodedError
+	if errors.As(err, &codedErr) {
+		res["code"] = codedErr.Code()
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(res)
+}
+
+// NextPageURL returns the URL of the request with the offset of the page following the one selected by cond,
+// it returns false if cond has no limit.
+func NextPageURL(r *http.Request, cond *sc.SelectionCondition) (string, bool) {
+	if cond == nil || cond.Limit == 0 {
+		return "", false
+	}
+	return withQuery(r, map[string]string{
+		sc.LimitParamName:  strconv.FormatUint(uint64(cond.Limit), 10),
+		sc.OffsetParamName: strconv.FormatUint(uint64(cond.Offset+cond.Limit), 10),
+	}), true
+}
+
+// PrevPageURL returns the URL of the request with the offset of the page preceding the one selected by cond,
+// it returns false if cond has no limit or selects the first page.
+func PrevPageURL(r *http.Request, cond *sc.SelectionCondition) (string, bool) {
+	if cond == nil || cond.Limit == 0 || cond.Offset == 0 {
+		return "", false
+	}
+	offset := uint(0)
+	if cond.Offset > cond.Limit {
+		offset = cond.Offset - cond.Limit
+	}
+	return withQuery(r, map[string]string{
+		sc.LimitParamName:  strconv.FormatUint(uint64(cond.Limit), 10),
+		sc.OffsetParamName: strconv.FormatUint(uint64(offset), 10),
+	}), true
+}
+
+// CursorURL returns the URL of the request with the cursor, e.g. made by sc.NextCursor, instead of the offset.
+func CursorURL(r *http.Request, cursor string) string {
+	return withQuery(r, map[string]string{
+		sc.CursorParamName: cursor,
+		sc.OffsetParamName: "",
+	})
+}
+
+// withQuery returns the URL of the request with the params set, an empty value deletes the param.
+func withQuery(r *http.Request, params map[string]string) string {
+	u := *r.URL
+	query := u.Query()
+	for name, value := range params {
+		if value == "" {
+			query.Del(name)
+			continue
+		}
+		query.Set(name, value)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
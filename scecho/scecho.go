@@ -0,0 +1,66 @@
+package scecho
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	sc "github.com/minipkg/selection_condition"
+)
+
+// ContextKey is the key of the condition in echo.Context set by Middleware.
+const ContextKey = "selection_condition"
+
+// BindEcho parses and validates the query params of the request by the fields of the struct pointed by model.
+// If the params are invalid it returns *echo.HTTPError with the status 400 Bad Request.
+func BindEcho(c echo.Context, model interface{}, opts ...sc.Option) (*sc.SelectionCondition, error) {
+	return bind(c, sc.NewParser(opts...), model)
+}
+
+// Middleware parses the query params of a request like BindEcho and sets the condition to the context,
+// it is got by FromContext in handlers.
+func Middleware(model interface{}, opts ...sc.Option) echo.MiddlewareFunc {
+	p := sc.NewParser(opts...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cond, err := bind(c, p, model)
+			if err != nil {
+				return err
+			}
+			c.Set(ContextKey, cond)
+			c.SetRequest(c.Request().WithContext(sc.NewContext(c.Request().Context(), cond)))
+			return next(c)
+		}
+	}
+}
+
+// FromContext returns the condition set by Middleware.
+func FromContext(c echo.Context) (*sc.SelectionCondition, bool) {
+	cond, ok := c.Get(ContextKey).(*sc.SelectionCondition)
+	return cond, ok
+}
+
+func bind(c echo.Context, p *sc.Parser, model interface{}) (*sc.SelectionCondition, error) {
+	cond, err := p.ParseRequest(c.Request(), model)
+	if err == nil {
+		err = cond.Validate()
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, errorResponse(err)).SetInternal(err)
+	}
+	return cond, nil
+}
+
+// errorResponse returns the message of echo.HTTPError to a request with invalid params,
+// the code is set for a parse error: {"error": "Unknown field foo", "code": "unknown_field"}
+func errorResponse(err error) echo.Map {
+	res := echo.Map{"error": err.Error()}
+
+	var codedErr sc.CodedError
+	if errors.As(err, &codedErr) {
+		res["code"] = codedErr.Code()
+	}
+	return res
+}
@@ -0,0 +1,300 @@
+package scelastic
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	sc "github.com/minipkg/selection_condition"
+	"github.com/minipkg/selection_condition/internal/naming"
+)
+
+type config struct {
+	fieldName func(field string) string
+}
+
+type Option func(*config)
+
+// WithFieldName sets the function that maps a struct field name to a document field. Default is snake_case.
+func WithFieldName(fn func(field string) string) Option {
+	return func(c *config) {
+		c.fieldName = fn
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		fieldName: naming.SnakeCase,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Search returns a search request body with "query", "sort", "from", "size" and "_source" keys.
+func Search(cond *sc.SelectionCondition, opts ...Option) (map[string]interface{}, error) {
+	c := newConfig(opts)
+	res := make(map[string]interface{}, 4)
+	if cond == nil {
+		res["query"] = map[string]interface{}{"match_all": map[string]interface{}{}}
+		return res, nil
+	}
+
+	query, err := c.query(cond.Where)
+	if err != nil {
+		return nil, err
+	}
+	res["query"] = query
+
+	if sort := c.sort(cond); len(sort) > 0 {
+		res["sort"] = sort
+	}
+	if len(cond.Fields) > 0 {
+		source := make([]string, 0, len(cond.Fields))
+		for _, field := range cond.Fields {
+			source = append(source, c.fieldName(field))
+		}
+		res["_source"] = source
+	}
+	if cond.Limit > 0 {
+		res["size"] = cond.Limit
+	}
+	if cond.Offset > 0 {
+		res["from"] = cond.Offset
+	}
+	return res, nil
+}
+
+func SearchJSON(cond *sc.SelectionCondition, opts ...Option) ([]byte, error) {
+	search, err := Search(cond, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(search)
+}
+
+func Query(cond *sc.SelectionCondition, opts ...Option) (map[string]interface{}, error) {
+	if cond == nil {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+	}
+	return newConfig(opts).query(cond.Where)
+}
+
+// sort returns the sort of the search, a case-insensitive order requires a keyword field with a lowercase normalizer.
+func (c *config) sort(cond *sc.SelectionCondition) []interface{} {
+	res := make([]interface{}, 0, len(cond.SortOrder))
+
+	for _, sortField := range cond.SortOrder {
+		sort := map[string]interface{}{"order": sortField.Direction}
+		switch sortField.Nulls {
+		case sc.NullsFirst:
+			sort["missing"] = "_first"
+		case sc.NullsLast:
+			sort["missing"] = "_last"
+		}
+		res = append(res, map[string]interface{}{
+			c.fieldName(sortField.Field): sort,
+		})
+	}
+	return res
+}
+
+func (c *config) query(where interface{}) (map[string]interface{}, error) {
+	switch w := where.(type) {
+	case nil:
+		return map[string]interface{}{"match_all": map[string]interface{}{}}, nil
+	case sc.WhereCondition:
+		return c.whereConditions([]sc.WhereCondition{w})
+	case sc.WhereConditions:
+		return c.whereConditions(w)
+	case sc.WhereConditionGroup:
+		return c.group(w)
+	default:
+		return nil, errors.Errorf("Unsupported type of Where: %T", where)
+	}
+}
+
+func (c *config) whereConditions(conds []sc.WhereCondition) (map[string]interface{}, error) {
+	items := make([]interface{}, 0, len(conds))
+	for _, cond := range conds {
+		items = append(items, cond)
+	}
+	return c.group(sc.WhereConditionGroup{Logic: sc.LogicAnd, Conditions: items})
+}
+
+func (c *config) group(group sc.WhereConditionGroup) (map[string]interface{}, error) {
+	filter := make([]interface{}, 0, len(group.Conditions))
+	must := make([]interface{}, 0)
+
+	for _, item := range group.Conditions {
+		switch cond := item.(type) {
+		case sc.WhereCondition:
+			clause, err := c.whereCondition(cond)
+			if err != nil {
+				return nil, err
+			}
+			// full text search affects the score, so it goes to "must" instead of "filter"
+			if cond.Condition == sc.ConditionTS {
+				must = append(must, clause)
+				continue
+			}
+			filter = append(filter, clause)
+		case sc.WhereConditionGroup:
+			clause, err := c.group(cond)
+			if err != nil {
+				return nil, err
+			}
+			must = append(must, clause)
+		default:
+			return nil, errors.Errorf("Unsupported type of a group condition: %T", item)
+		}
+	}
+
+	boolQuery := make(map[string]interface{}, 3)
+	switch group.Logic {
+	case sc.LogicAnd:
+		if len(filter) > 0 {
+			boolQuery["filter"] = filter
+		}
+		if len(must) > 0 {
+			boolQuery["must"] = must
+		}
+	case sc.LogicOr:
+		boolQuery["should"] = append(filter, must...)
+		boolQuery["minimum_should_match"] = 1
+	default:
+		return nil, errors.Errorf("Unsupported logic of a group: %q", group.Logic)
+	}
+
+	if group.Not {
+		return map[string]interface{}{"bool": map[string]interface{}{
+			"must_not": []interface{}{map[string]interface{}{"bool": boolQuery}},
+		}}, nil
+	}
+	return map[string]interface{}{"bool": boolQuery}, nil
+}
+
+func (c *config) whereCondition(cond sc.WhereCondition) (map[string]interface{}, error) {
+	field := c.fieldName(cond.Field)
+	if cond.JSONPath != "" {
+		field += sc.FieldPathSeparator + cond.JSONPath
+	}
+
+	switch cond.Condition {
+	case sc.ConditionEq:
+		return map[string]interface{}{"term": map[string]interface{}{field: cond.Value}}, nil
+	case sc.ConditionGt, sc.ConditionGte, sc.ConditionLt, sc.ConditionLte:
+		return rangeClause(field, map[string]interface{}{cond.Condition: cond.Value}), nil
+	case sc.ConditionIn:
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice", cond.Condition, cond.Field)
+		}
+		return map[string]interface{}{"terms": map[string]interface{}{field: values}}, nil
+	case sc.ConditionBt:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return rangeClause(field, map[string]interface{}{"gte": values[0], "lte": values[1]}), nil
+	case sc.ConditionTS:
+		return map[string]interface{}{"match": map[string]interface{}{field: cond.Value}}, nil
+	case sc.ConditionAny, sc.ConditionAll:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a non-empty slice", cond.Condition, cond.Field)
+		}
+		if cond.Condition == sc.ConditionAny {
+			return map[string]interface{}{"terms": map[string]interface{}{field: values}}, nil
+		}
+		terms := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			terms = append(terms, map[string]interface{}{"term": map[string]interface{}{field: value}})
+		}
+		return map[string]interface{}{"bool": map[string]interface{}{"filter": terms}}, nil
+	case sc.ConditionJSONContains:
+		object, ok := cond.Value.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be an object", cond.Condition, cond.Field)
+		}
+		values, ok := sc.FlattenJSON(object)
+		if !ok {
+			return nil, errors.Errorf("Arrays in the value of condition %q for field %s are not supported by elasticsearch adapter", cond.Condition, cond.Field)
+		}
+		paths := make([]string, 0, len(values))
+		for path := range values {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		terms := make([]interface{}, 0, len(paths))
+		for _, path := range paths {
+			terms = append(terms, map[string]interface{}{"term": map[string]interface{}{field + sc.FieldPathSeparator + path: values[path]}})
+		}
+		return map[string]interface{}{"bool": map[string]interface{}{"filter": terms}}, nil
+	case sc.ConditionNear:
+		value, ok := cond.Value.(sc.GeoNear)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoNear", cond.Condition, cond.Field)
+		}
+		return map[string]interface{}{"geo_distance": map[string]interface{}{
+			"distance": strconv.FormatFloat(value.Radius, 'f', -1, 64) + "m",
+			field:      map[string]interface{}{"lat": value.Point.Lat, "lon": value.Point.Lon},
+		}}, nil
+	case sc.ConditionBBox:
+		value, ok := cond.Value.(sc.GeoBoundingBox)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoBoundingBox", cond.Condition, cond.Field)
+		}
+		return map[string]interface{}{"geo_bounding_box": map[string]interface{}{field: map[string]interface{}{
+			"top_left":     map[string]interface{}{"lat": value.Max.Lat, "lon": value.Min.Lon},
+			"bottom_right": map[string]interface{}{"lat": value.Min.Lat, "lon": value.Max.Lon},
+		}}}, nil
+	case sc.ConditionOverlaps:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return rangeClause(field, map[string]interface{}{"gte": values[0], "lte": values[1], "relation": "intersects"}), nil
+	case sc.ConditionBtExcl:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return rangeClause(field, map[string]interface{}{"gt": values[0], "lt": values[1]}), nil
+	case sc.ConditionIEq:
+		return map[string]interface{}{"term": map[string]interface{}{field: map[string]interface{}{
+			"value":            cond.Value,
+			"case_insensitive": true,
+		}}}, nil
+	case sc.ConditionFuzzy:
+		value, ok := cond.Value.(sc.Fuzzy)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.Fuzzy", cond.Condition, cond.Field)
+		}
+		return map[string]interface{}{"match": map[string]interface{}{field: map[string]interface{}{
+			"query":     value.Text,
+			"fuzziness": value.Fuzziness(),
+		}}}, nil
+	case sc.ConditionILike:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		return map[string]interface{}{"wildcard": map[string]interface{}{field: map[string]interface{}{
+			"value":            "*" + wildcardEscaper.Replace(value) + "*",
+			"case_insensitive": true,
+		}}}, nil
+	}
+	return nil, errors.Errorf("Condition %q is not supported by elasticsearch adapter", cond.Condition)
+}
+
+// wildcardEscaper escapes the characters having a meaning in the value of a wildcard query.
+var wildcardEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`)
+
+func rangeClause(field string, bounds map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"range": map[string]interface{}{field: bounds}}
+}
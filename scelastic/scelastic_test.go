@@ -0,0 +1,110 @@
+package scelastic
+
+import (
+	"testing"
+
+	sc "github.com/minipkg/selection_condition"
+)
+
+func TestSearchJSON(t *testing.T) {
+	statusOpen := sc.WhereCondition{Field: "Status", Condition: sc.ConditionEq, Value: "open"}
+
+	tests := []struct {
+		name string
+		cond *sc.SelectionCondition
+		opts []Option
+		want string
+		err  bool
+	}{
+		{
+			name: "nil",
+			want: `{"query":{"match_all":{}}}`,
+		},
+		{
+			name: "empty",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{}},
+			want: `{"query":{"bool":{}}}`,
+		},
+		{
+			name: "conditions",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{
+				statusOpen,
+				{Field: "Amount", Condition: sc.ConditionGte, Value: 10},
+				{Field: "Tags", Condition: sc.ConditionIn, Value: []interface{}{"a", "b"}},
+				{Field: "CreatedAt", Condition: sc.ConditionBt, Value: []interface{}{"2024-01-01", "2024-01-31"}},
+				{Field: "Author.Name", Condition: sc.ConditionILike, Value: "jo*"},
+			}},
+			want: `{"query":{"bool":{"filter":[` +
+				`{"term":{"status":"open"}},` +
+				`{"range":{"amount":{"gte":10}}},` +
+				`{"terms":{"tags":["a","b"]}},` +
+				`{"range":{"created_at":{"gte":"2024-01-01","lte":"2024-01-31"}}},` +
+				`{"wildcard":{"author.name":{"case_insensitive":true,"value":"*jo\\**"}}}]}}}`,
+		},
+		{
+			name: "full text search is scored",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{
+				statusOpen,
+				{Field: "Title", Condition: sc.ConditionTS, Value: "quick fox"},
+			}},
+			want: `{"query":{"bool":{"filter":[{"term":{"status":"open"}}],"must":[{"match":{"title":"quick fox"}}]}}}`,
+		},
+		{
+			name: "groups",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditionGroup{Logic: sc.LogicOr, Conditions: []interface{}{
+				statusOpen,
+				sc.WhereConditionGroup{Logic: sc.LogicAnd, Not: true, Conditions: []interface{}{
+					sc.WhereCondition{Field: "Paid", Condition: sc.ConditionEq, Value: true},
+				}},
+			}}},
+			want: `{"query":{"bool":{"minimum_should_match":1,"should":[` +
+				`{"term":{"status":"open"}},` +
+				`{"bool":{"must_not":[{"bool":{"filter":[{"term":{"paid":true}}]}}]}}]}}}`,
+		},
+		{
+			name: "sort, source and page",
+			cond: &sc.SelectionCondition{
+				SortOrder: []sc.SortField{{Field: "CreatedAt", Direction: sc.SortOrderDesc, Nulls: sc.NullsLast}, {Field: "ID", Direction: sc.SortOrderAsc}},
+				Fields:    []string{"ID", "Author.Name"},
+				Limit:     20,
+				Offset:    40,
+			},
+			want: `{"_source":["id","author.name"],"from":40,"query":{"match_all":{}},"size":20,` +
+				`"sort":[{"created_at":{"missing":"_last","order":"desc"}},{"id":{"order":"asc"}}]}`,
+		},
+		{
+			name: "field names",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{statusOpen}},
+			opts: []Option{WithFieldName(func(field string) string { return "o_" + field })},
+			want: `{"query":{"bool":{"filter":[{"term":{"o_Status":"open"}}]}}}`,
+		},
+		{
+			name: "bt without two bounds",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{{Field: "Amount", Condition: sc.ConditionBt, Value: []interface{}{1}}}},
+			err:  true,
+		},
+		{
+			name: "unknown condition",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{{Field: "Status", Condition: "unknown", Value: "open"}}},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SearchJSON(tt.cond, tt.opts...)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package scent
+
+import (
+	"entgo.io/ent/entql"
+	"github.com/pkg/errors"
+
+	sc "github.com/minipkg/selection_condition"
+	"github.com/minipkg/selection_condition/internal/naming"
+)
+
+type config struct {
+	fieldName func(field string) string
+}
+
+type Option func(*config)
+
+// WithFieldName sets the function that maps a struct field name to an ent field name. Default is snake_case.
+func WithFieldName(fn func(field string) string) Option {
+	return func(c *config) {
+		c.fieldName = fn
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		fieldName: naming.SnakeCase,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Predicate returns an entql predicate for the where conditions of cond, e.g. to be passed to the Where method
+// of a generated entql filter. It returns nil if there is nothing to filter by.
+func Predicate(cond *sc.SelectionCondition, opts ...Option) (entql.P, error) {
+	if cond == nil {
+		return nil, nil
+	}
+	return newConfig(opts).predicate(cond.Where)
+}
+
+func (c *config) predicate(where interface{}) (entql.P, error) {
+	switch w := where.(type) {
+	case nil:
+		return nil, nil
+	case sc.WhereCondition:
+		return c.whereCondition(w)
+	case sc.WhereConditions:
+		return c.whereConditions(w)
+	case sc.WhereConditionGroup:
+		return c.group(w)
+	default:
+		return nil, errors.Errorf("Unsupported type of Where: %T", where)
+	}
+}
+
+func (c *config) whereConditions(conds []sc.WhereCondition) (entql.P, error) {
+	ps := make([]entql.P, 0, len(conds))
+
+	for _, cond := range conds {
+		p, err := c.whereCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+	return and(ps), nil
+}
+
+func (c *config) group(group sc.WhereConditionGroup) (entql.P, error) {
+	ps := make([]entql.P, 0, len(group.Conditions))
+
+	for _, item := range group.Conditions {
+		var p entql.P
+		var err error
+
+		switch cond := item.(type) {
+		case sc.WhereCondition:
+			p, err = c.whereCondition(cond)
+		case sc.WhereConditionGroup:
+			p, err = c.group(cond)
+		default:
+			err = errors.Errorf("Unsupported type of a group condition: %T", item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+
+	var p entql.P
+	switch group.Logic {
+	case sc.LogicAnd:
+		p = and(ps)
+	case sc.LogicOr:
+		p = or(ps)
+	default:
+		return nil, errors.Errorf("Unsupported logic of a group: %q", group.Logic)
+	}
+
+	if group.Not && p != nil {
+		return entql.Not(p), nil
+	}
+	return p, nil
+}
+
+func (c *config) whereCondition(cond sc.WhereCondition) (entql.P, error) {
+	field := c.fieldName(cond.Field)
+	if cond.JSONPath != "" {
+		return nil, errors.Errorf("JSON path %s of field %s is not supported by ent adapter", cond.JSONPath, cond.Field)
+	}
+
+	switch cond.Condition {
+	case sc.ConditionEq:
+		return entql.FieldEQ(field, cond.Value), nil
+	case sc.ConditionGt:
+		return entql.FieldGT(field, cond.Value), nil
+	case sc.ConditionGte:
+		return entql.FieldGTE(field, cond.Value), nil
+	case sc.ConditionLt:
+		return entql.FieldLT(field, cond.Value), nil
+	case sc.ConditionLte:
+		return entql.FieldLTE(field, cond.Value), nil
+	case sc.ConditionIn:
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice", cond.Condition, cond.Field)
+		}
+		return entql.FieldIn(field, values...), nil
+	case sc.ConditionBt:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return entql.And(entql.FieldGTE(field, values[0]), entql.FieldLTE(field, values[1])), nil
+	case sc.ConditionBtExcl:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return entql.And(entql.FieldGT(field, values[0]), entql.FieldLT(field, values[1])), nil
+	case sc.ConditionIEq:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		return entql.FieldEqualFold(field, value), nil
+	case sc.ConditionILike:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		return entql.FieldContainsFold(field, value), nil
+	}
+	return nil, errors.Errorf("Condition %q is not supported by ent adapter", cond.Condition)
+}
+
+func and(ps []entql.P) entql.P {
+	switch len(ps) {
+	case 0:
+		return nil
+	case 1:
+		return ps[0]
+	}
+	return entql.And(ps[0], ps[1], ps[2:]...)
+}
+
+func or(ps []entql.P) entql.P {
+	switch len(ps) {
+	case 0:
+		return nil
+	case 1:
+		return ps[0]
+	}
+	return entql.Or(ps[0], ps[1], ps[2:]...)
+}
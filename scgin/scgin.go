@@ -0,0 +1,69 @@
+package scgin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	sc "github.com/minipkg/selection_condition"
+)
+
+// ContextKey is the key of the condition in gin.Context set by Middleware.
+const ContextKey = "selection_condition"
+
+// BindGin parses and validates the query params of the request by the fields of the struct pointed by model.
+// If the params are invalid it aborts the request with the status 400 Bad Request and returns the error.
+func BindGin(c *gin.Context, model interface{}, opts ...sc.Option) (*sc.SelectionCondition, error) {
+	return bind(c, sc.NewParser(opts...), model)
+}
+
+// Middleware parses the query params of a request like BindGin and sets the condition to the context,
+// it is got by FromContext in handlers.
+func Middleware(model interface{}, opts ...sc.Option) gin.HandlerFunc {
+	p := sc.NewParser(opts...)
+
+	return func(c *gin.Context) {
+		cond, err := bind(c, p, model)
+		if err != nil {
+			return
+		}
+		c.Set(ContextKey, cond)
+		c.Request = c.Request.WithContext(sc.NewContext(c.Request.Context(), cond))
+		c.Next()
+	}
+}
+
+// FromContext returns the condition set by Middleware.
+func FromContext(c *gin.Context) (*sc.SelectionCondition, bool) {
+	value, ok := c.Get(ContextKey)
+	if !ok {
+		return nil, false
+	}
+	cond, ok := value.(*sc.SelectionCondition)
+	return cond, ok
+}
+
+func bind(c *gin.Context, p *sc.Parser, model interface{}) (*sc.SelectionCondition, error) {
+	cond, err := p.ParseRequest(c.Request, model)
+	if err == nil {
+		err = cond.Validate()
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+		return nil, err
+	}
+	return cond, nil
+}
+
+// errorResponse returns the body of the response to a request with invalid params,
+// the code is set for a parse error: {"error": "Unknown field foo", "code": "unknown_field"}
+func errorResponse(err error) gin.H {
+	res := gin.H{"error": err.Error()}
+
+	var codedErr sc.CodedError
+	if errors.As(err, &codedErr) {
+		res["code"] = codedErr.Code()
+	}
+	return res
+}
@@ -0,0 +1,335 @@
+package scgorm
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	sc "github.com/minipkg/selection_condition"
+	"github.com/minipkg/selection_condition/internal/naming"
+)
+
+func GormScope(cond *sc.SelectionCondition) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if cond == nil {
+			return db
+		}
+
+		if len(cond.GroupBy) > 0 || len(cond.Aggregates) > 0 {
+			db = db.Select(groupColumns(db, cond))
+			for _, field := range cond.GroupBy {
+				db = db.Group(db.Statement.Quote(columnName(db, field)))
+			}
+		} else if len(cond.Fields) > 0 {
+			columns := make([]string, 0, len(cond.Fields))
+			for _, field := range cond.Fields {
+				columns = append(columns, columnName(db, field))
+			}
+			db = db.Select(columns)
+		}
+
+		if cond.Distinct {
+			db = db.Distinct()
+		}
+
+		for _, having := range cond.Having {
+			expr, err := condition(aggregateColumn(db, having.Aggregate), sc.WhereCondition{
+				Field:     having.Func + sc.AggregateFieldSeparator + having.Field,
+				Condition: having.Condition,
+				Value:     having.Value,
+			})
+			if err != nil {
+				db.AddError(err)
+				return db
+			}
+			db = db.Having(expr)
+		}
+
+		for _, relation := range cond.Include {
+			db = db.Preload(relation)
+		}
+
+		exprs, err := whereExpressions(db, cond.Where)
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		if len(exprs) > 0 {
+			db = db.Clauses(clause.Where{Exprs: exprs})
+		}
+
+		for _, sortField := range cond.SortOrder {
+			column := clause.Column{Name: columnName(db, sortField.Field)}
+			if sortField.Nulls == "" && !sortField.CaseInsensitive {
+				db = db.Order(clause.OrderByColumn{
+					Column: column,
+					Desc:   sortField.Direction == sc.SortOrderDesc,
+				})
+				continue
+			}
+
+			// gorm has neither placement of nulls nor functions, so the order is built as raw SQL
+			expr := db.Statement.Quote(column)
+			if sortField.CaseInsensitive {
+				expr = "LOWER(" + expr + ")"
+			}
+			if sortField.Direction == sc.SortOrderDesc {
+				expr += " DESC"
+			} else {
+				expr += " ASC"
+			}
+			switch sortField.Nulls {
+			case sc.NullsFirst:
+				expr += " NULLS FIRST"
+			case sc.NullsLast:
+				expr += " NULLS LAST"
+			}
+			db = db.Order(clause.OrderByColumn{
+				Column: clause.Column{Name: expr, Raw: true},
+			})
+		}
+
+		// values above the maximum of int are clamped, a negative limit is no limit for gorm
+		if cond.Limit > 0 {
+			db = db.Limit(int(min(cond.Limit, math.MaxInt)))
+		}
+		if cond.Offset > 0 {
+			db = db.Offset(int(min(cond.Offset, math.MaxInt)))
+		}
+		return db
+	}
+}
+
+// groupColumns returns the columns of grouped rows, the ones of GROUP BY followed by the aggregates
+// like SUM("amount") AS "sum_amount".
+func groupColumns(db *gorm.DB, cond *sc.SelectionCondition) string {
+	columns := make([]string, 0, len(cond.GroupBy)+len(cond.Aggregates))
+	for _, field := range cond.GroupBy {
+		columns = append(columns, db.Statement.Quote(columnName(db, field)))
+	}
+	for _, aggregate := range cond.Aggregates {
+		alias := aggregate.Func
+		if aggregate.Field != "" {
+			alias = naming.AggregateAlias(aggregate.Func, columnName(db, aggregate.Field))
+		}
+		columns = append(columns, aggregateColumn(db, aggregate).Name+" AS "+db.Statement.Quote(alias))
+	}
+	return strings.Join(columns, ", ")
+}
+
+// aggregateColumn returns the expression of the aggregate as a raw column, e.g. SUM("amount") or COUNT(*).
+func aggregateColumn(db *gorm.DB, aggregate sc.Aggregate) clause.Column {
+	column := "*"
+	if aggregate.Field != "" {
+		column = db.Statement.Quote(columnName(db, aggregate.Field))
+	}
+	return clause.Column{Name: strings.ToUpper(aggregate.Func) + "(" + column + ")", Raw: true}
+}
+
+// CountScope returns the scope of the query counting the rows selected by cond by db.Count,
+// e.g. for the total of with_count=true.
+func CountScope(cond *sc.SelectionCondition) func(*gorm.DB) *gorm.DB {
+	if cond == nil {
+		return GormScope(nil)
+	}
+	return GormScope(cond.ForCount())
+}
+
+func whereExpressions(db *gorm.DB, where interface{}) ([]clause.Expression, error) {
+	switch w := where.(type) {
+	case nil:
+		return nil, nil
+	case sc.WhereCondition:
+		expr, err := whereExpression(db, w)
+		if err != nil {
+			return nil, err
+		}
+		return []clause.Expression{expr}, nil
+	case sc.WhereConditions:
+		return whereConditionsExpressions(db, w)
+	case sc.WhereConditionGroup:
+		expr, err := groupExpression(db, w)
+		if err != nil {
+			return nil, err
+		}
+		return []clause.Expression{expr}, nil
+	default:
+		return nil, errors.Errorf("Unsupported type of Where: %T", where)
+	}
+}
+
+func whereConditionsExpressions(db *gorm.DB, conds []sc.WhereCondition) ([]clause.Expression, error) {
+	exprs := make([]clause.Expression, 0, len(conds))
+
+	for _, cond := range conds {
+		expr, err := whereExpression(db, cond)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+func groupExpression(db *gorm.DB, group sc.WhereConditionGroup) (clause.Expression, error) {
+	exprs := make([]clause.Expression, 0, len(group.Conditions))
+
+	for _, item := range group.Conditions {
+		var expr clause.Expression
+		var err error
+
+		switch c := item.(type) {
+		case sc.WhereCondition:
+			expr, err = whereExpression(db, c)
+		case sc.WhereConditionGroup:
+			expr, err = groupExpression(db, c)
+		default:
+			err = errors.Errorf("Unsupported type of a group condition: %T", item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	var expr clause.Expression
+	switch group.Logic {
+	case sc.LogicAnd:
+		expr = clause.And(exprs...)
+	case sc.LogicOr:
+		expr = clause.Or(exprs...)
+	default:
+		return nil, errors.Errorf("Unsupported logic of a group: %q", group.Logic)
+	}
+
+	if group.Not {
+		// clause.Not negates each of the expressions, so it does not fit for a group
+		return clause.Expr{SQL: "NOT (?)", Vars: []interface{}{expr}}, nil
+	}
+	return expr, nil
+}
+
+func whereExpression(db *gorm.DB, cond sc.WhereCondition) (clause.Expression, error) {
+	if cond.JSONPath != "" {
+		column := sc.JSONPathExpression(db.Statement.Quote(columnName(db, cond.Field)), cond.JSONPath)
+		return condition(clause.Column{Name: column, Raw: true}, cond)
+	}
+	return condition(clause.Column{Name: columnName(db, cond.Field)}, cond)
+}
+
+// condition returns the condition on the column which may be a raw expression like SUM("amount").
+func condition(column clause.Column, cond sc.WhereCondition) (clause.Expression, error) {
+	switch cond.Condition {
+	case sc.ConditionEq:
+		return clause.Eq{Column: column, Value: cond.Value}, nil
+	case sc.ConditionGt:
+		return clause.Gt{Column: column, Value: cond.Value}, nil
+	case sc.ConditionGte:
+		return clause.Gte{Column: column, Value: cond.Value}, nil
+	case sc.ConditionLt:
+		return clause.Lt{Column: column, Value: cond.Value}, nil
+	case sc.ConditionLte:
+		return clause.Lte{Column: column, Value: cond.Value}, nil
+	case sc.ConditionIn:
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice", cond.Condition, cond.Field)
+		}
+		return clause.IN{Column: column, Values: values}, nil
+	case sc.ConditionBt:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{column, values[0], values[1]}}, nil
+	case sc.ConditionFuzzy:
+		value, ok := cond.Value.(sc.Fuzzy)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.Fuzzy", cond.Condition, cond.Field)
+		}
+		return clause.Expr{SQL: "similarity(?, ?) >= ?", Vars: []interface{}{column, value.Text, value.Similarity}}, nil
+	case sc.ConditionAny, sc.ConditionAll:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a non-empty slice", cond.Condition, cond.Field)
+		}
+		// the placeholders are listed as gorm encloses a slice in parentheses
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+		return clause.Expr{SQL: "? " + arrayOperator(cond.Condition) + " ARRAY[" + placeholders + "]", Vars: append([]interface{}{column}, values...)}, nil
+	case sc.ConditionJSONContains:
+		document, err := json.Marshal(cond.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Value of condition %q for field %s", cond.Condition, cond.Field)
+		}
+		return clause.Expr{SQL: "? @> CAST(? AS jsonb)", Vars: []interface{}{column, string(document)}}, nil
+	case sc.ConditionNear:
+		value, ok := cond.Value.(sc.GeoNear)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoNear", cond.Condition, cond.Field)
+		}
+		return clause.Expr{
+			SQL:  "ST_DWithin(CAST(? AS geography), CAST(ST_SetSRID(ST_MakePoint(?, ?), 4326) AS geography), ?)",
+			Vars: []interface{}{column, value.Point.Lon, value.Point.Lat, value.Radius},
+		}, nil
+	case sc.ConditionBBox:
+		value, ok := cond.Value.(sc.GeoBoundingBox)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoBoundingBox", cond.Condition, cond.Field)
+		}
+		return clause.Expr{
+			SQL:  "ST_Intersects(CAST(? AS geometry), ST_MakeEnvelope(?, ?, ?, ?, 4326))",
+			Vars: []interface{}{column, value.Min.Lon, value.Min.Lat, value.Max.Lon, value.Max.Lat},
+		}, nil
+	case sc.ConditionOverlaps:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		rangeFunction, ok := sc.PostgresRangeFunction(values[0])
+		if !ok {
+			return nil, errors.Errorf("Bounds of type %T of condition %q for field %s are not supported", values[0], cond.Condition, cond.Field)
+		}
+		return clause.Expr{SQL: "? && " + rangeFunction + "(?, ?, '[]')", Vars: []interface{}{column, values[0], values[1]}}, nil
+	case sc.ConditionBtExcl:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return clause.And(clause.Gt{Column: column, Value: values[0]}, clause.Lt{Column: column, Value: values[1]}), nil
+	case sc.ConditionIEq:
+		return clause.Expr{SQL: "LOWER(?) = LOWER(?)", Vars: []interface{}{column, cond.Value}}, nil
+	case sc.ConditionILike:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		return clause.Expr{SQL: "LOWER(?) LIKE LOWER(?) ESCAPE '" + sc.LikeEscape + "'", Vars: []interface{}{column, sc.ContainsPattern(value)}}, nil
+	}
+	return nil, errors.Errorf("Condition %q is not supported by gorm scope", cond.Condition)
+}
+
+// arrayOperator returns the operator of Postgres arrays for the condition any or all.
+func arrayOperator(condition string) string {
+	if condition == sc.ConditionAny {
+		return "&&"
+	}
+	return "@>"
+}
+
+// columnName maps the field to a column name by the naming strategy of db,
+// for a path of nested fields like Author.Name each of the names is mapped.
+func columnName(db *gorm.DB, field string) string {
+	if db.NamingStrategy == nil {
+		return field
+	}
+
+	names := strings.Split(field, sc.FieldPathSeparator)
+	for i, name := range names {
+		names[i] = db.NamingStrategy.ColumnName("", name)
+	}
+	return strings.Join(names, ".")
+}
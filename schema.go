@@ -0,0 +1,301 @@
+package selection_condition
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Schema holds the fields of a struct prepared once for parsing, so parsing of params by a schema
+// does not walk the struct by reflection. It is compiled at startup for hot paths:
+//
+//	var orderSchema, _ = sc.CompileSchema(&Order{}, sc.WithStrictFilters())
+//
+//	cond, err := orderSchema.Parse(r.URL.Query())
+//
+// A Schema is safe for concurrent use.
+type Schema struct {
+	o    *options
+	root *structSchema
+	// searchFields are the paths of Go names of the fields searched by the param q
+	searchFields []string
+	// filterRules are the ozzo-validation rules of values of conditions by the paths of Go names of the fields
+	filterRules map[string][]validation.Rule
+}
+
+// structSchema holds the fields of a struct by their param names and by their Go names.
+type structSchema struct {
+	byName   map[string]*schemaField
+	byGoName map[string]*schemaField
+}
+
+type schemaField struct {
+	// name is the name of the field in params
+	name   string
+	goName string
+	// typ is the type of values of the field
+	typ        reflect.Type
+	filterable bool
+	sortable   bool
+	searchable bool
+	// conditions are the only conditions allowed by the tag, nil means any
+	conditions []string
+	// transformers are the names of the transformers of values by the tag
+	transformers []string
+	// enum are the only values of the field in conditions by the enum tag, nil means any
+	enum []string
+	// nested is the schema of the struct for the path traversal through the field, nil if there is no struct
+	nested *structSchema
+}
+
+// CompileSchema prepares the fields of the struct pointed by struc for parsing by the options.
+func CompileSchema(struc interface{}, opts ...Option) (*Schema, error) {
+	o := newOptions(opts)
+
+	schema, err := compileSchema(struc, o)
+	if err != nil {
+		return nil, translateError(err, o.translator)
+	}
+	return schema, nil
+}
+
+func compileSchema(struc interface{}, o *options) (*Schema, error) {
+	structType, err := getTypeOfAStruct(struc)
+	if err != nil {
+		return nil, err
+	}
+	root := compileStructSchema(structType, o.tagName, make(map[reflect.Type]*structSchema))
+	searchFields, err := compileSearchFields(structType, root, o)
+	if err != nil {
+		return nil, err
+	}
+	filterRules, err := compileFilterRules(struc, root, o)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{
+		o:            o,
+		root:         root,
+		searchFields: searchFields,
+		filterRules:  filterRules,
+	}, nil
+}
+
+// compileStructSchema returns the schema of the struct type, compiled holds the schemas made for the types
+// so recursive types get the same schema.
+func compileStructSchema(structType reflect.Type, tagName string, compiled map[reflect.Type]*structSchema) *structSchema {
+	indexesByNames := cachedStructFieldIndexes(structType, tagName)
+	s := &structSchema{
+		byName:   make(map[string]*schemaField, len(indexesByNames)),
+		byGoName: make(map[string]*schemaField, len(indexesByNames)),
+	}
+	compiled[structType] = s
+
+	for name, index := range indexesByNames {
+		field := structType.FieldByIndex(index)
+		f := &schemaField{
+			name:         name,
+			goName:       field.Name,
+			typ:          valueType(field.Type),
+			filterable:   hasSelectionTag(field, SelectionFilter),
+			sortable:     hasSelectionTag(field, SelectionSort),
+			searchable:   hasSelectionTag(field, SelectionSearch),
+			conditions:   tagConditions(field),
+			transformers: tagTransformers(field),
+			enum:         tagEnum(field),
+		}
+		if nestedType, ok := nestedStructType(field.Type); ok {
+			if f.nested, ok = compiled[nestedType]; !ok {
+				f.nested = compileStructSchema(nestedType, tagName, compiled)
+			}
+		}
+		s.byName[name] = f
+		s.byGoName[field.Name] = f
+	}
+	return s
+}
+
+// Parse parses params by the fields of the schema.
+func (s *Schema) Parse(params map[string][]string) (*SelectionCondition, error) {
+	return s.ParseContext(context.Background(), params)
+}
+
+// ParseContext parses params by the fields of the schema, ctx is passed to the functions set by WithForcedConditionsFunc.
+func (s *Schema) ParseContext(ctx context.Context, params map[string][]string) (*SelectionCondition, error) {
+	conditions, err := parseQueryParams(ctx, params, s)
+	if err != nil {
+		return nil, translateError(err, s.o.translator)
+	}
+	return conditions, nil
+}
+
+// fieldByParamName returns the path of Go names and the field by its param name or its alias.
+func (s *Schema) fieldByParamName(paramName string) (string, *schemaField, bool) {
+	if path, ok := s.o.aliases[paramName]; ok {
+		return s.root.field(path, true)
+	}
+	return s.root.field(paramName, false)
+}
+
+// fieldByPath returns the field by its path of Go names like Author.Name
+func (s *Schema) fieldByPath(path string) (*schemaField, bool) {
+	_, f, ok := s.root.field(path, true)
+	return f, ok
+}
+
+// paramNameByPath returns the name of the field in params by its path of Go names like Author.Name
+func (s *Schema) paramNameByPath(path string) (string, bool) {
+	fields := s.root
+	names := make([]string, 0, strings.Count(path, FieldPathSeparator)+1)
+	for _, goName := range strings.Split(path, FieldPathSeparator) {
+		if fields == nil {
+			return "", false
+		}
+		f, ok := fields.byGoName[goName]
+		if !ok {
+			return "", false
+		}
+		names = append(names, f.name)
+		fields = f.nested
+	}
+	return strings.Join(names, FieldPathSeparator), true
+}
+
+// isConditionAllowed reports whether the condition is allowed on the field by its path of Go names.
+func (s *Schema) isConditionAllowed(path string, f *schemaField, condition string) bool {
+	conditions, ok := s.o.fieldConditions[path]
+	if !ok {
+		// a field is nil for a param not resolved to a field
+		if f == nil || f.conditions == nil {
+			return true
+		}
+		conditions = f.conditions
+	}
+	for _, c := range conditions {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the path of Go names and the field by its name, by the Go name if byGoName is set,
+// names of nested struct fields are separated by dots: author.name gives Author.Name
+func (s *structSchema) field(name string, byGoName bool) (string, *schemaField, bool) {
+	fields := s.byName
+	if byGoName {
+		fields = s.byGoName
+	}
+	if f, ok := fields[name]; ok {
+		return f.goName, f, true
+	}
+
+	i := strings.Index(name, FieldPathSeparator)
+	if i < 0 {
+		return "", nil, false
+	}
+	path, f, ok := s.field(name[:i], byGoName)
+	if !ok || f.nested == nil {
+		return "", nil, false
+	}
+	nestedPath, nestedField, ok := f.nested.field(name[i+len(FieldPathSeparator):], byGoName)
+	if !ok {
+		return "", nil, false
+	}
+	return path + FieldPathSeparator + nestedPath, nestedField, true
+}
+
+// schemaFieldPath is a field with its param name and its path of Go names.
+type schemaFieldPath struct {
+	name  string
+	path  string
+	field *schemaField
+}
+
+// flatFields returns the fields of values ordered by their param names, the fields of nested structs
+// are included by their paths but the ones of recursive types only once, a nested struct itself is included
+// only if it is a point.
+func (s *Schema) flatFields() []schemaFieldPath {
+	var res []schemaFieldPath
+	var walk func(fields *structSchema, namePrefix string, pathPrefix string, visiting map[*structSchema]bool)
+	walk = func(fields *structSchema, namePrefix string, pathPrefix string, visiting map[*structSchema]bool) {
+		visiting[fields] = true
+		defer delete(visiting, fields)
+
+		names := make([]string, 0, len(fields.byName))
+		for name := range fields.byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			f := fields.byName[name]
+			if f.nested != nil && !visiting[f.nested] {
+				walk(f.nested, namePrefix+name+FieldPathSeparator, pathPrefix+f.goName+FieldPathSeparator, visiting)
+			}
+			if f.nested != nil && !isGeoPointType(f.typ) {
+				continue
+			}
+			res = append(res, schemaFieldPath{name: namePrefix + name, path: pathPrefix + f.goName, field: f})
+		}
+	}
+	walk(s.root, "", "", make(map[*structSchema]bool))
+	return res
+}
+
+// fieldConditions returns the conditions parsing accepts on the field by its path of Go names in the order
+// of ConditionVariants, registered conditions are included if the conditions of the field list them.
+// Parsing accepts some more, they are left out as they make no sense: the geo conditions on fields other than points,
+// ts on fields other than text and comparisons of booleans.
+func (s *Schema) fieldConditions(path string, f *schemaField) []string {
+	typ := f.typ
+	isList := (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && !isUUIDType(typ) && typ.Elem().Kind() != reflect.Uint8
+	isScalar := isScalarType(typ)
+
+	var res []string
+	for _, variant := range conditionVariants() {
+		condition := variant.(string)
+		var ok bool
+		switch condition {
+		case ConditionJSONContains:
+			ok = isJSONType(typ)
+		case ConditionNear, ConditionBBox:
+			ok = isGeoType(typ) && isGeoPointType(typ)
+		case ConditionAny, ConditionAll:
+			ok = isList
+		case ConditionOverlaps:
+			ok = isRangeType(typ) && !isUUIDType(typ)
+		case ConditionILike, ConditionFuzzy, ConditionIEq, ConditionTS:
+			ok = typ.Kind() == reflect.String
+		case ConditionEq, ConditionIn:
+			ok = isScalar
+		default:
+			if _, custom := customConditionByName(condition); custom {
+				_, listed := s.o.fieldConditions[path]
+				ok = listed || f.conditions != nil
+			} else {
+				// booleans and UUIDs are not ordered
+				ok = isScalar && !isUUIDType(typ) && typ.Kind() != reflect.Bool
+			}
+		}
+		if ok && s.isConditionAllowed(path, f, condition) {
+			res = append(res, condition)
+		}
+	}
+	return res
+}
+
+// isScalarType reports whether values of the type are single values, that is not lists, JSON documents or structs
+// other than times and types parsed from text.
+func isScalarType(typ reflect.Type) bool {
+	if _, ok := converterByType(typ); ok || typ == timeType || isUUIDType(typ) || reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Struct, reflect.Map, reflect.Interface:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,272 @@
+package scmongo
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	sc "github.com/minipkg/selection_condition"
+)
+
+type config struct {
+	fieldName func(field string) string
+}
+
+type Option func(*config)
+
+// WithFieldName sets the function that maps a struct field name to a document key.
+// Default is strings.ToLower, the same as the default key of the mongo driver struct codec.
+func WithFieldName(fn func(field string) string) Option {
+	return func(c *config) {
+		c.fieldName = fn
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		fieldName: strings.ToLower,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func Filter(cond *sc.SelectionCondition, opts ...Option) (bson.M, error) {
+	if cond == nil {
+		return bson.M{}, nil
+	}
+	return newConfig(opts).filter(cond.Where)
+}
+
+// FindOptions returns the options of Find with the projection, sort order, limit and offset of cond,
+// distinct values are got by the command Distinct instead.
+func FindOptions(cond *sc.SelectionCondition, opts ...Option) *options.FindOptions {
+	findOptions := options.Find()
+	if cond == nil {
+		return findOptions
+	}
+	c := newConfig(opts)
+
+	if len(cond.Fields) > 0 {
+		projection := make(bson.D, 0, len(cond.Fields))
+		for _, field := range cond.Fields {
+			projection = append(projection, bson.E{Key: c.fieldName(field), Value: 1})
+		}
+		findOptions.SetProjection(projection)
+	}
+	if sort := c.sort(cond); len(sort) > 0 {
+		findOptions.SetSort(sort)
+	}
+	// values above the maximum of int64 are clamped, a negative limit of MongoDB returns a single batch
+	if cond.Limit > 0 {
+		findOptions.SetLimit(int64(min(uint64(cond.Limit), math.MaxInt64)))
+	}
+	if cond.Offset > 0 {
+		findOptions.SetSkip(int64(min(uint64(cond.Offset), math.MaxInt64)))
+	}
+	return findOptions
+}
+
+// sort returns the sort document, the placement of nulls is not set as MongoDB always takes nulls as the lowest values,
+// a case-insensitive order requires a collation of the collection or of the query like {locale: "en", strength: 2}.
+func (c *config) sort(cond *sc.SelectionCondition) bson.D {
+	res := make(bson.D, 0, len(cond.SortOrder))
+
+	for _, sortField := range cond.SortOrder {
+		if sortField.Direction == sc.SortOrderDesc {
+			res = append(res, bson.E{Key: c.fieldName(sortField.Field), Value: -1})
+			continue
+		}
+		res = append(res, bson.E{Key: c.fieldName(sortField.Field), Value: 1})
+	}
+	return res
+}
+
+func (c *config) filter(where interface{}) (bson.M, error) {
+	switch w := where.(type) {
+	case nil:
+		return bson.M{}, nil
+	case sc.WhereCondition:
+		return c.whereConditions([]sc.WhereCondition{w})
+	case sc.WhereConditions:
+		return c.whereConditions(w)
+	case sc.WhereConditionGroup:
+		return c.group(w)
+	default:
+		return nil, errors.Errorf("Unsupported type of Where: %T", where)
+	}
+}
+
+func (c *config) whereConditions(conds []sc.WhereCondition) (bson.M, error) {
+	res := make(bson.M, len(conds))
+	filters := make([]interface{}, 0, len(conds))
+
+	for _, cond := range conds {
+		filter, err := c.whereCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	switch len(filters) {
+	case 0:
+	case 1:
+		res = filters[0].(bson.M)
+	default:
+		res["$and"] = filters
+	}
+	return res, nil
+}
+
+func (c *config) group(group sc.WhereConditionGroup) (bson.M, error) {
+	filters := make([]interface{}, 0, len(group.Conditions))
+
+	for _, item := range group.Conditions {
+		var filter bson.M
+		var err error
+
+		switch cond := item.(type) {
+		case sc.WhereCondition:
+			filter, err = c.whereCondition(cond)
+		case sc.WhereConditionGroup:
+			filter, err = c.group(cond)
+		default:
+			err = errors.Errorf("Unsupported type of a group condition: %T", item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	// MongoDB requires a nonempty array of $and and $or, so an empty AND matches everything
+	// and an empty OR matches nothing
+	var filter bson.M
+	switch {
+	case group.Logic == sc.LogicAnd && len(filters) == 0:
+		filter = bson.M{}
+	case group.Logic == sc.LogicOr && len(filters) == 0:
+		filter = bson.M{"$expr": false}
+	case group.Logic == sc.LogicAnd:
+		filter = bson.M{"$and": filters}
+	case group.Logic == sc.LogicOr:
+		filter = bson.M{"$or": filters}
+	default:
+		return nil, errors.Errorf("Unsupported logic of a group: %q", group.Logic)
+	}
+
+	if group.Not {
+		return bson.M{"$nor": []interface{}{filter}}, nil
+	}
+	return filter, nil
+}
+
+func (c *config) whereCondition(cond sc.WhereCondition) (bson.M, error) {
+	key := c.fieldName(cond.Field)
+	if cond.JSONPath != "" {
+		key += sc.FieldPathSeparator + cond.JSONPath
+	}
+
+	switch cond.Condition {
+	case sc.ConditionEq:
+		return bson.M{key: bson.M{"$eq": cond.Value}}, nil
+	case sc.ConditionGt:
+		return bson.M{key: bson.M{"$gt": cond.Value}}, nil
+	case sc.ConditionGte:
+		return bson.M{key: bson.M{"$gte": cond.Value}}, nil
+	case sc.ConditionLt:
+		return bson.M{key: bson.M{"$lt": cond.Value}}, nil
+	case sc.ConditionLte:
+		return bson.M{key: bson.M{"$lte": cond.Value}}, nil
+	case sc.ConditionIn:
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice", cond.Condition, cond.Field)
+		}
+		return bson.M{key: bson.M{"$in": values}}, nil
+	case sc.ConditionBt:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return bson.M{key: bson.M{"$gte": values[0], "$lte": values[1]}}, nil
+	case sc.ConditionAny, sc.ConditionAll:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a non-empty slice", cond.Condition, cond.Field)
+		}
+		if cond.Condition == sc.ConditionAny {
+			return bson.M{key: bson.M{"$in": values}}, nil
+		}
+		return bson.M{key: bson.M{"$all": values}}, nil
+	case sc.ConditionJSONContains:
+		object, ok := cond.Value.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be an object", cond.Condition, cond.Field)
+		}
+		values, ok := sc.FlattenJSON(object)
+		if !ok {
+			return nil, errors.Errorf("Arrays in the value of condition %q for field %s are not supported by mongo adapter", cond.Condition, cond.Field)
+		}
+		filter := make(bson.M, len(values))
+		for path, value := range values {
+			filter[key+sc.FieldPathSeparator+path] = value
+		}
+		return filter, nil
+	case sc.ConditionNear:
+		value, ok := cond.Value.(sc.GeoNear)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoNear", cond.Condition, cond.Field)
+		}
+		return bson.M{key: bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{bson.A{value.Point.Lon, value.Point.Lat}, value.RadiusRadians()},
+		}}}, nil
+	case sc.ConditionBBox:
+		value, ok := cond.Value.(sc.GeoBoundingBox)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoBoundingBox", cond.Condition, cond.Field)
+		}
+		ring := bson.A{
+			bson.A{value.Min.Lon, value.Min.Lat},
+			bson.A{value.Max.Lon, value.Min.Lat},
+			bson.A{value.Max.Lon, value.Max.Lat},
+			bson.A{value.Min.Lon, value.Max.Lat},
+			bson.A{value.Min.Lon, value.Min.Lat},
+		}
+		return bson.M{key: bson.M{"$geoWithin": bson.M{
+			"$geometry": bson.M{"type": "Polygon", "coordinates": bson.A{ring}},
+		}}}, nil
+	case sc.ConditionOverlaps:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		// a range is an array of its bounds
+		return bson.M{key + ".0": bson.M{"$lte": values[1]}, key + ".1": bson.M{"$gte": values[0]}}, nil
+	case sc.ConditionBtExcl:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return bson.M{key: bson.M{"$gt": values[0], "$lt": values[1]}}, nil
+	case sc.ConditionIEq:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		return bson.M{key: bson.M{"$regex": "^" + regexp.QuoteMeta(value) + "$", "$options": "i"}}, nil
+	case sc.ConditionILike:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		return bson.M{key: bson.M{"$regex": regexp.QuoteMeta(value), "$options": "i"}}, nil
+	}
+	return nil, errors.Errorf("Condition %q is not supported by mongo adapter", cond.Condition)
+}
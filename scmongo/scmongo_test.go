@@ -0,0 +1,168 @@
+package scmongo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	sc "github.com/minipkg/selection_condition"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestFilter(t *testing.T) {
+	statusOpen := sc.WhereCondition{Field: "Status", Condition: sc.ConditionEq, Value: "open"}
+
+	tests := []struct {
+		name string
+		cond *sc.SelectionCondition
+		opts []Option
+		want bson.M
+		err  bool
+	}{
+		{
+			name: "nil",
+			want: bson.M{},
+		},
+		{
+			name: "empty",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{}},
+			want: bson.M{},
+		},
+		{
+			name: "single condition",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{statusOpen}},
+			want: bson.M{"status": bson.M{"$eq": "open"}},
+		},
+		{
+			name: "conditions",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{
+				{Field: "Amount", Condition: sc.ConditionGte, Value: 10},
+				{Field: "Status", Condition: sc.ConditionIn, Value: []interface{}{"a", "b"}},
+				{Field: "Author.Name", Condition: sc.ConditionBt, Value: []interface{}{"a", "m"}},
+				{Field: "Quantity", Condition: sc.ConditionBtExcl, Value: []interface{}{1, 5}},
+			}},
+			want: bson.M{"$and": []interface{}{
+				bson.M{"amount": bson.M{"$gte": 10}},
+				bson.M{"status": bson.M{"$in": []interface{}{"a", "b"}}},
+				bson.M{"author.name": bson.M{"$gte": "a", "$lte": "m"}},
+				bson.M{"quantity": bson.M{"$gt": 1, "$lt": 5}},
+			}},
+		},
+		{
+			name: "groups",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditionGroup{Logic: sc.LogicOr, Conditions: []interface{}{
+				statusOpen,
+				sc.WhereConditionGroup{Logic: sc.LogicAnd, Not: true, Conditions: []interface{}{
+					sc.WhereCondition{Field: "Paid", Condition: sc.ConditionEq, Value: true},
+				}},
+			}}},
+			want: bson.M{"$or": []interface{}{
+				bson.M{"status": bson.M{"$eq": "open"}},
+				bson.M{"$nor": []interface{}{bson.M{"$and": []interface{}{bson.M{"paid": bson.M{"$eq": true}}}}}},
+			}},
+		},
+		{
+			name: "empty and",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditionGroup{Logic: sc.LogicAnd}},
+			want: bson.M{},
+		},
+		{
+			name: "empty or",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditionGroup{Logic: sc.LogicOr}},
+			want: bson.M{"$expr": false},
+		},
+		{
+			name: "negated empty or",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditionGroup{Logic: sc.LogicOr, Not: true}},
+			want: bson.M{"$nor": []interface{}{bson.M{"$expr": false}}},
+		},
+		{
+			name: "field names",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{statusOpen}},
+			opts: []Option{WithFieldName(func(field string) string { return "o_" + field })},
+			want: bson.M{"o_Status": bson.M{"$eq": "open"}},
+		},
+		{
+			name: "in without a slice",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{{Field: "Status", Condition: sc.ConditionIn, Value: "a"}}},
+			err:  true,
+		},
+		{
+			name: "unknown logic",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditionGroup{Logic: "xor", Conditions: []interface{}{statusOpen}}},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Filter(tt.cond, tt.opts...)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindOptions(t *testing.T) {
+	int64Ptr := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name           string
+		cond           *sc.SelectionCondition
+		wantProjection interface{}
+		wantSort       interface{}
+		wantLimit      *int64
+		wantSkip       *int64
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name: "projection, sort and page",
+			cond: &sc.SelectionCondition{
+				Fields:    []string{"ID", "Author.Name"},
+				SortOrder: []sc.SortField{{Field: "CreatedAt", Direction: sc.SortOrderDesc}, {Field: "ID", Direction: sc.SortOrderAsc}},
+				Limit:     20,
+				Offset:    40,
+			},
+			wantProjection: bson.D{{Key: "id", Value: 1}, {Key: "author.name", Value: 1}},
+			wantSort:       bson.D{{Key: "createdat", Value: -1}, {Key: "id", Value: 1}},
+			wantLimit:      int64Ptr(20),
+			wantSkip:       int64Ptr(40),
+		},
+		{
+			name:      "page beyond the maximum of int64",
+			cond:      &sc.SelectionCondition{Limit: math.MaxUint, Offset: math.MaxUint},
+			wantLimit: int64Ptr(math.MaxInt64),
+			wantSkip:  int64Ptr(math.MaxInt64),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindOptions(tt.cond)
+			if !reflect.DeepEqual(got.Projection, tt.wantProjection) {
+				t.Errorf("got projection %v, want %v", got.Projection, tt.wantProjection)
+			}
+			if !reflect.DeepEqual(got.Sort, tt.wantSort) {
+				t.Errorf("got sort %v, want %v", got.Sort, tt.wantSort)
+			}
+			if !reflect.DeepEqual(got.Limit, tt.wantLimit) {
+				t.Errorf("got limit %v, want %v", got.Limit, tt.wantLimit)
+			}
+			if !reflect.DeepEqual(got.Skip, tt.wantSkip) {
+				t.Errorf("got skip %v, want %v", got.Skip, tt.wantSkip)
+			}
+		})
+	}
+}
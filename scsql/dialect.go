@@ -0,0 +1,157 @@
+package scsql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	sc "github.com/minipkg/selection_condition"
+)
+
+// Dialect is the syntax of a database for identifiers and placeholders of parameters. Conditions on arrays, ranges,
+// JSON documents, geo values and fuzzy ones have the syntax of Postgres and its extensions pg_trgm and PostGIS,
+// the builders fail on the ones a dialect of the package has no syntax for and on all of them for other dialects.
+type Dialect interface {
+	// QuoteIdentifier quotes a name of a column or a table without dots
+	QuoteIdentifier(name string) string
+	// Placeholder returns the placeholder of the n-th parameter of a query from 1
+	Placeholder(n int) string
+}
+
+var (
+	// Postgres quotes identifiers like "name" and has placeholders like $1.
+	Postgres Dialect = postgresDialect{}
+	// MySQL quotes identifiers like `name` and has placeholders ?.
+	MySQL Dialect = mysqlDialect{}
+	// SQLite quotes identifiers like "name" and has placeholders ?.
+	SQLite Dialect = sqliteDialect{}
+	// SQLServer quotes identifiers like [name] and has placeholders like @p1.
+	SQLServer Dialect = sqlServerDialect{}
+)
+
+// defaultDialect is the one without WithDialect, it quotes identifiers like Postgres and has placeholders ?
+// which are rebound by libraries like sqlx.
+type defaultDialect struct{}
+
+func (defaultDialect) QuoteIdentifier(name string) string {
+	return postgresDialect{}.QuoteIdentifier(name)
+}
+
+func (defaultDialect) Placeholder(int) string {
+	return "?"
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) Placeholder(int) string {
+	return "?"
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) Placeholder(int) string {
+	return "?"
+}
+
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) QuoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (sqlServerDialect) Placeholder(n int) string {
+	return "@p" + strconv.Itoa(n)
+}
+
+// quote returns the name of a column quoted by the dialect, each part of a name qualified by dots like "t.name"
+// is quoted apart.
+func (c *config) quote(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part != "*" {
+			parts[i] = c.dialect.QuoteIdentifier(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// column returns the quoted name of the column of the field.
+func (c *config) column(field string) string {
+	return c.quote(c.columnName(field))
+}
+
+// isPostgres reports whether the dialect has the syntax of Postgres for arrays, ranges, JSON documents,
+// geo values and fuzzy ones.
+func (c *config) isPostgres() bool {
+	switch c.dialect.(type) {
+	case defaultDialect, postgresDialect:
+		return true
+	}
+	return false
+}
+
+// nulls returns the placement of nulls of the sort field by NULLS FIRST or NULLS LAST after the direction,
+// the dialects without them place nulls by a preceding expression like "CASE WHEN name IS NULL THEN 1 ELSE 0 END ASC, ".
+func (c *config) nulls(column string, sortField sc.SortField) (string, string) {
+	if sortField.Nulls != sc.NullsFirst && sortField.Nulls != sc.NullsLast {
+		return "", ""
+	}
+	switch c.dialect.(type) {
+	case defaultDialect, postgresDialect, sqliteDialect:
+		if sortField.Nulls == sc.NullsFirst {
+			return "", " NULLS FIRST"
+		}
+		return "", " NULLS LAST"
+	}
+	if sortField.Nulls == sc.NullsFirst {
+		return "CASE WHEN " + column + " IS NULL THEN 0 ELSE 1 END ASC, ", ""
+	}
+	return "CASE WHEN " + column + " IS NULL THEN 1 ELSE 0 END ASC, ", ""
+}
+
+// jsonPathExpression returns the expression of the value of the quoted column at the JSON path as text.
+func (c *config) jsonPathExpression(column string, jsonPath string) (string, error) {
+	var format string
+	switch c.dialect.(type) {
+	case defaultDialect, postgresDialect:
+		return sc.JSONPathExpression(column, jsonPath), nil
+	case mysqlDialect:
+		format = "JSON_UNQUOTE(JSON_EXTRACT(%s, '%s'))"
+	case sqliteDialect:
+		format = "json_extract(%s, '%s')"
+	case sqlServerDialect:
+		format = "JSON_VALUE(%s, '%s')"
+	default:
+		return "", errors.Errorf("JSON path %s is not supported by the dialect of sql builder", jsonPath)
+	}
+
+	// keys are quoted in the path as they may have any characters: $."plan"."name"
+	var path strings.Builder
+	path.WriteString("$")
+	for _, key := range strings.Split(jsonPath, sc.FieldPathSeparator) {
+		path.WriteString(`."` + jsonPathKeyEscaper.Replace(key) + `"`)
+	}
+	return fmt.Sprintf(format, column, strings.ReplaceAll(path.String(), "'", "''")), nil
+}
+
+var jsonPathKeyEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
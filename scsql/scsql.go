@@ -0,0 +1,548 @@
+package scsql
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	sc "github.com/minipkg/selection_condition"
+	"github.com/minipkg/selection_condition/internal/naming"
+)
+
+type config struct {
+	columnName func(field string) string
+	dialect    Dialect
+}
+
+type Option func(*config)
+
+// WithColumnName sets the function that maps a struct field name to a column name. Default is snake_case.
+func WithColumnName(fn func(field string) string) Option {
+	return func(c *config) {
+		c.columnName = fn
+	}
+}
+
+// WithDialect sets the dialect quoting the names of columns, the placeholders of Where are the ones of the dialect.
+// Default is quoting like Postgres with its syntax of conditions and placeholders ?.
+func WithDialect(d Dialect) Option {
+	return func(c *config) {
+		c.dialect = d
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		columnName: naming.SnakeCase,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.dialect == nil {
+		c.dialect = defaultDialect{}
+	}
+	return c
+}
+
+// NamedWhere returns a condition for a WHERE clause (without the keyword) with named parameters like ":name_0"
+// and the map of their values, ready to be passed to sqlx.NamedQuery. The condition is empty if there is nothing to filter by.
+func NamedWhere(cond *sc.SelectionCondition, opts ...Option) (string, map[string]interface{}, error) {
+	b := &namedBuilder{
+		config: newConfig(opts),
+		args:   make(map[string]interface{}),
+	}
+	if cond == nil {
+		return "", b.args, nil
+	}
+
+	if err := b.where(cond.Where); err != nil {
+		return "", nil, err
+	}
+	return b.sql.String(), b.args, nil
+}
+
+// Where returns a condition for a WHERE clause (without the keyword) like NamedWhere with positional parameters
+// like "$1" or "?" by the dialect set by WithDialect and the list of their values.
+func Where(cond *sc.SelectionCondition, opts ...Option) (string, []interface{}, error) {
+	b := &namedBuilder{
+		config:     newConfig(opts),
+		positional: true,
+	}
+	if cond == nil {
+		return "", nil, nil
+	}
+
+	if err := b.where(cond.Where); err != nil {
+		return "", nil, err
+	}
+	return b.sql.String(), b.list, nil
+}
+
+// NamedHaving returns a condition for a HAVING clause (without the keyword) like NamedWhere, the names of its parameters
+// start with "having_" so the maps of both may be joined. The condition is empty if there is nothing to filter by.
+func NamedHaving(cond *sc.SelectionCondition, opts ...Option) (string, map[string]interface{}, error) {
+	b := &namedBuilder{
+		config: newConfig(opts),
+		prefix: "having_",
+		args:   make(map[string]interface{}),
+	}
+	if cond == nil {
+		return "", b.args, nil
+	}
+
+	for i, having := range cond.Having {
+		if i > 0 {
+			b.sql.WriteString(" AND ")
+		}
+		alias := having.Func
+		if having.Field != "" {
+			alias = naming.AggregateAlias(having.Func, b.columnName(having.Field))
+		}
+		err := b.condition(b.aggregate(having.Aggregate), alias, sc.WhereCondition{
+			Field:     having.Func + sc.AggregateFieldSeparator + having.Field,
+			Condition: having.Condition,
+			Value:     having.Value,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	return b.sql.String(), b.args, nil
+}
+
+// CountQuery returns the query counting the rows of the table selected by cond with named parameters like NamedWhere,
+// e.g. for the total of with_count=true. Groups and distinct rows are counted by a subquery.
+func CountQuery(table string, cond *sc.SelectionCondition, opts ...Option) (string, map[string]interface{}, error) {
+	if cond == nil {
+		return "SELECT COUNT(*) FROM " + table, map[string]interface{}{}, nil
+	}
+	cond = cond.ForCount()
+
+	where, args, err := NamedWhere(cond, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	var query strings.Builder
+	if cond.IsGrouped() {
+		query.WriteString("SELECT COUNT(*) FROM (SELECT ")
+		query.WriteString(Columns(cond, opts...))
+		query.WriteString(" FROM ")
+	} else {
+		query.WriteString("SELECT COUNT(*) FROM ")
+	}
+	query.WriteString(table)
+	if where != "" {
+		query.WriteString(" WHERE ")
+		query.WriteString(where)
+	}
+	if !cond.IsGrouped() {
+		return query.String(), args, nil
+	}
+
+	if groupBy := GroupBy(cond, opts...); groupBy != "" {
+		query.WriteString(" GROUP BY ")
+		query.WriteString(groupBy)
+	}
+	having, havingArgs, err := NamedHaving(cond, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	if having != "" {
+		query.WriteString(" HAVING ")
+		query.WriteString(having)
+		for name, value := range havingArgs {
+			args[name] = value
+		}
+	}
+	query.WriteString(") AS t")
+	return query.String(), args, nil
+}
+
+// Columns returns a list of the columns of the projection for a SELECT clause, e.g. `"id", "name"`, or "*" if there is none,
+// preceded by DISTINCT if the rows are distinct. The columns of grouped rows are the ones of GROUP BY followed
+// by the aggregates, e.g. `"country", SUM("amount") AS "sum_amount"`.
+func Columns(cond *sc.SelectionCondition, opts ...Option) string {
+	if cond == nil {
+		return "*"
+	}
+	c := newConfig(opts)
+
+	var distinct string
+	if cond.Distinct {
+		distinct = "DISTINCT "
+	}
+	if len(cond.GroupBy) == 0 && len(cond.Aggregates) == 0 {
+		if len(cond.Fields) == 0 {
+			return distinct + "*"
+		}
+		res := make([]string, 0, len(cond.Fields))
+		for _, field := range cond.Fields {
+			res = append(res, c.column(field))
+		}
+		return distinct + strings.Join(res, ", ")
+	}
+
+	res := make([]string, 0, len(cond.GroupBy)+len(cond.Aggregates))
+	for _, field := range cond.GroupBy {
+		res = append(res, c.column(field))
+	}
+	for _, aggregate := range cond.Aggregates {
+		alias := aggregate.Func
+		if aggregate.Field != "" {
+			alias = naming.AggregateAlias(aggregate.Func, c.columnName(aggregate.Field))
+		}
+		res = append(res, c.aggregate(aggregate)+" AS "+c.quote(alias))
+	}
+	return distinct + strings.Join(res, ", ")
+}
+
+// aggregate returns the expression of the aggregate, e.g. `SUM("amount")` or "COUNT(*)".
+func (c *config) aggregate(aggregate sc.Aggregate) string {
+	column := "*"
+	if aggregate.Field != "" {
+		column = c.column(aggregate.Field)
+	}
+	return strings.ToUpper(aggregate.Func) + "(" + column + ")"
+}
+
+// GroupBy returns a list for a GROUP BY clause (without the keyword), e.g. `"country", "city"`.
+func GroupBy(cond *sc.SelectionCondition, opts ...Option) string {
+	if cond == nil {
+		return ""
+	}
+	c := newConfig(opts)
+	res := make([]string, 0, len(cond.GroupBy))
+
+	for _, field := range cond.GroupBy {
+		res = append(res, c.column(field))
+	}
+	return strings.Join(res, ", ")
+}
+
+// OrderBy returns a list for an ORDER BY clause (without the keyword), e.g. `"name" DESC, "id" ASC`.
+func OrderBy(cond *sc.SelectionCondition, opts ...Option) string {
+	if cond == nil {
+		return ""
+	}
+	c := newConfig(opts)
+	res := make([]string, 0, len(cond.SortOrder))
+
+	for _, sortField := range cond.SortOrder {
+		column := c.column(sortField.Field)
+		nullsBefore, nullsAfter := c.nulls(column, sortField)
+		if sortField.CaseInsensitive {
+			column = "LOWER(" + column + ")"
+		}
+		res = append(res, nullsBefore+column+orderDirection(sortField)+nullsAfter)
+	}
+	return strings.Join(res, ", ")
+}
+
+// orderDirection returns the direction of the field, e.g. " DESC".
+func orderDirection(sortField sc.SortField) string {
+	if sortField.Direction == sc.SortOrderDesc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+type namedBuilder struct {
+	*config
+	// prefix is the prefix of the names of the parameters
+	prefix string
+	sql    strings.Builder
+	args   map[string]interface{}
+	// positional makes the parameters positional ones of the dialect with the values in list
+	positional bool
+	list       []interface{}
+}
+
+func (b *namedBuilder) where(where interface{}) error {
+	switch w := where.(type) {
+	case nil:
+		return nil
+	case sc.WhereCondition:
+		return b.whereConditions([]sc.WhereCondition{w})
+	case sc.WhereConditions:
+		return b.whereConditions(w)
+	case sc.WhereConditionGroup:
+		return b.group(w)
+	default:
+		return errors.Errorf("Unsupported type of Where: %T", where)
+	}
+}
+
+func (b *namedBuilder) whereConditions(conds []sc.WhereCondition) error {
+	for i, cond := range conds {
+		if i > 0 {
+			b.sql.WriteString(" AND ")
+		}
+		if err := b.whereCondition(cond); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *namedBuilder) group(group sc.WhereConditionGroup) error {
+	var separator string
+	switch group.Logic {
+	case sc.LogicAnd:
+		separator = " AND "
+	case sc.LogicOr:
+		separator = " OR "
+	default:
+		return errors.Errorf("Unsupported logic of a group: %q", group.Logic)
+	}
+
+	if group.Not {
+		b.sql.WriteString("NOT ")
+	}
+
+	if len(group.Conditions) == 0 {
+		if group.Logic == sc.LogicOr {
+			b.sql.WriteString("1=0")
+			return nil
+		}
+		b.sql.WriteString("1=1")
+		return nil
+	}
+
+	b.sql.WriteByte('(')
+	for i, item := range group.Conditions {
+		if i > 0 {
+			b.sql.WriteString(separator)
+		}
+
+		var err error
+		switch c := item.(type) {
+		case sc.WhereCondition:
+			err = b.whereCondition(c)
+		case sc.WhereConditionGroup:
+			err = b.group(c)
+		default:
+			err = errors.Errorf("Unsupported type of a group condition: %T", item)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	b.sql.WriteByte(')')
+	return nil
+}
+
+func (b *namedBuilder) whereCondition(cond sc.WhereCondition) error {
+	column := b.columnName(cond.Field)
+	if cond.JSONPath != "" {
+		expression, err := b.jsonPathExpression(b.quote(column), cond.JSONPath)
+		if err != nil {
+			return err
+		}
+		return b.condition(expression, column+"_"+cond.JSONPath, cond)
+	}
+	return b.condition(b.quote(column), column, cond)
+}
+
+// condition writes the condition on the column which may be an expression like SUM(amount),
+// name is the base of the names of the parameters.
+func (b *namedBuilder) condition(column string, name string, cond sc.WhereCondition) error {
+	switch cond.Condition {
+	case sc.ConditionFuzzy, sc.ConditionAny, sc.ConditionAll, sc.ConditionNear, sc.ConditionBBox, sc.ConditionOverlaps:
+		if !b.isPostgres() {
+			return errors.Errorf("Condition %q is not supported by the dialect of sql builder", cond.Condition)
+		}
+	case sc.ConditionJSONContains:
+		if _, ok := b.dialect.(mysqlDialect); !ok && !b.isPostgres() {
+			return errors.Errorf("Condition %q is not supported by the dialect of sql builder", cond.Condition)
+		}
+	}
+
+	switch cond.Condition {
+	case sc.ConditionEq:
+		b.comparison(column, name, "=", cond.Value)
+	case sc.ConditionGt:
+		b.comparison(column, name, ">", cond.Value)
+	case sc.ConditionGte:
+		b.comparison(column, name, ">=", cond.Value)
+	case sc.ConditionLt:
+		b.comparison(column, name, "<", cond.Value)
+	case sc.ConditionLte:
+		b.comparison(column, name, "<=", cond.Value)
+	case sc.ConditionIn:
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return errors.Errorf("Value of condition %q for field %s must be a slice", cond.Condition, cond.Field)
+		}
+		if len(values) == 0 {
+			b.sql.WriteString("1=0")
+			return nil
+		}
+		b.sql.WriteString(column)
+		b.sql.WriteString(" IN (")
+		for i, value := range values {
+			if i > 0 {
+				b.sql.WriteString(", ")
+			}
+			b.bind(name, value)
+		}
+		b.sql.WriteByte(')')
+	case sc.ConditionBt:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		b.sql.WriteString(column)
+		b.sql.WriteString(" BETWEEN ")
+		b.bind(name, values[0])
+		b.sql.WriteString(" AND ")
+		b.bind(name, values[1])
+	case sc.ConditionFuzzy:
+		value, ok := cond.Value.(sc.Fuzzy)
+		if !ok {
+			return errors.Errorf("Value of condition %q for field %s must be a sc.Fuzzy", cond.Condition, cond.Field)
+		}
+		b.sql.WriteString("similarity(")
+		b.sql.WriteString(column)
+		b.sql.WriteString(", ")
+		b.bind(name, value.Text)
+		b.sql.WriteString(") >= ")
+		b.bind(name, value.Similarity)
+	case sc.ConditionAny, sc.ConditionAll:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return errors.Errorf("Value of condition %q for field %s must be a non-empty slice", cond.Condition, cond.Field)
+		}
+		// Postgres arrays: && is for overlapping ones and @> for containing ones
+		b.sql.WriteString(column)
+		if cond.Condition == sc.ConditionAny {
+			b.sql.WriteString(" && ARRAY[")
+		} else {
+			b.sql.WriteString(" @> ARRAY[")
+		}
+		for i, value := range values {
+			if i > 0 {
+				b.sql.WriteString(", ")
+			}
+			b.bind(name, value)
+		}
+		b.sql.WriteByte(']')
+	case sc.ConditionJSONContains:
+		document, err := json.Marshal(cond.Value)
+		if err != nil {
+			return errors.Wrapf(err, "Value of condition %q for field %s", cond.Condition, cond.Field)
+		}
+		if _, ok := b.dialect.(mysqlDialect); ok {
+			b.sql.WriteString("JSON_CONTAINS(")
+			b.sql.WriteString(column)
+			b.sql.WriteString(", CAST(")
+			b.bind(name, string(document))
+			b.sql.WriteString(" AS JSON))")
+			break
+		}
+		b.sql.WriteString(column)
+		b.sql.WriteString(" @> CAST(")
+		b.bind(name, string(document))
+		b.sql.WriteString(" AS jsonb)")
+	case sc.ConditionNear:
+		value, ok := cond.Value.(sc.GeoNear)
+		if !ok {
+			return errors.Errorf("Value of condition %q for field %s must be a sc.GeoNear", cond.Condition, cond.Field)
+		}
+		// PostGIS measures the distance on the spheroid for geography values
+		b.sql.WriteString("ST_DWithin(CAST(")
+		b.sql.WriteString(column)
+		b.sql.WriteString(" AS geography), CAST(ST_SetSRID(ST_MakePoint(")
+		b.bind(name, value.Point.Lon)
+		b.sql.WriteString(", ")
+		b.bind(name, value.Point.Lat)
+		b.sql.WriteString("), 4326) AS geography), ")
+		b.bind(name, value.Radius)
+		b.sql.WriteByte(')')
+	case sc.ConditionBBox:
+		value, ok := cond.Value.(sc.GeoBoundingBox)
+		if !ok {
+			return errors.Errorf("Value of condition %q for field %s must be a sc.GeoBoundingBox", cond.Condition, cond.Field)
+		}
+		b.sql.WriteString("ST_Intersects(CAST(")
+		b.sql.WriteString(column)
+		b.sql.WriteString(" AS geometry), ST_MakeEnvelope(")
+		for _, coordinate := range []float64{value.Min.Lon, value.Min.Lat, value.Max.Lon, value.Max.Lat} {
+			b.bind(name, coordinate)
+			b.sql.WriteString(", ")
+		}
+		b.sql.WriteString("4326))")
+	case sc.ConditionOverlaps:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		rangeFunction, ok := sc.PostgresRangeFunction(values[0])
+		if !ok {
+			return errors.Errorf("Bounds of type %T of condition %q for field %s are not supported", values[0], cond.Condition, cond.Field)
+		}
+		b.sql.WriteString(column)
+		b.sql.WriteString(" && " + rangeFunction + "(")
+		b.bind(name, values[0])
+		b.sql.WriteString(", ")
+		b.bind(name, values[1])
+		b.sql.WriteString(", '[]')")
+	case sc.ConditionBtExcl:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		b.comparison(column, name, ">", values[0])
+		b.sql.WriteString(" AND ")
+		b.comparison(column, name, "<", values[1])
+	case sc.ConditionIEq:
+		b.sql.WriteString("LOWER(")
+		b.sql.WriteString(column)
+		b.sql.WriteString(") = LOWER(")
+		b.bind(name, cond.Value)
+		b.sql.WriteByte(')')
+	case sc.ConditionILike:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		b.sql.WriteString("LOWER(")
+		b.sql.WriteString(column)
+		b.sql.WriteString(") LIKE LOWER(")
+		b.bind(name, sc.ContainsPattern(value))
+		b.sql.WriteString(") ESCAPE '" + sc.LikeEscape + "'")
+	default:
+		return errors.Errorf("Condition %q is not supported by sql builder", cond.Condition)
+	}
+	return nil
+}
+
+func (b *namedBuilder) comparison(column string, name string, operator string, value interface{}) {
+	b.sql.WriteString(column)
+	b.sql.WriteByte(' ')
+	b.sql.WriteString(operator)
+	b.sql.WriteByte(' ')
+	b.bind(name, value)
+}
+
+func (b *namedBuilder) bind(name string, value interface{}) {
+	if b.positional {
+		b.list = append(b.list, value)
+		b.sql.WriteString(b.dialect.Placeholder(len(b.list)))
+		return
+	}
+	name = b.prefix + paramName(name) + "_" + strconv.Itoa(len(b.args))
+	b.args[name] = value
+	b.sql.WriteByte(':')
+	b.sql.WriteString(name)
+}
+
+func paramName(column string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, column)
+}
@@ -0,0 +1,219 @@
+package scsql
+
+import (
+	"reflect"
+	"testing"
+
+	sc "github.com/minipkg/selection_condition"
+)
+
+func TestWhere(t *testing.T) {
+	statusOpen := sc.WhereCondition{Field: "Status", Condition: sc.ConditionEq, Value: "open"}
+
+	tests := []struct {
+		name     string
+		cond     *sc.SelectionCondition
+		opts     []Option
+		want     string
+		wantArgs []interface{}
+		err      bool
+	}{
+		{
+			name:     "nil",
+			want:     "",
+			wantArgs: nil,
+		},
+		{
+			name:     "empty",
+			cond:     &sc.SelectionCondition{Where: sc.WhereConditions{}},
+			want:     "",
+			wantArgs: nil,
+		},
+		{
+			name: "comparisons",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{
+				statusOpen,
+				{Field: "Amount", Condition: sc.ConditionGte, Value: 10},
+				{Field: "Quantity", Condition: sc.ConditionLt, Value: 5},
+			}},
+			want:     `"status" = ? AND "amount" >= ? AND "quantity" < ?`,
+			wantArgs: []interface{}{"open", 10, 5},
+		},
+		{
+			name: "in and bt",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{
+				{Field: "Status", Condition: sc.ConditionIn, Value: []interface{}{"a", "b"}},
+				{Field: "Amount", Condition: sc.ConditionBt, Value: []interface{}{1, 5}},
+			}},
+			want:     `"status" IN (?, ?) AND "amount" BETWEEN ? AND ?`,
+			wantArgs: []interface{}{"a", "b", 1, 5},
+		},
+		{
+			name:     "empty in",
+			cond:     &sc.SelectionCondition{Where: sc.WhereConditions{{Field: "Status", Condition: sc.ConditionIn, Value: []interface{}{}}}},
+			want:     "1=0",
+			wantArgs: nil,
+		},
+		{
+			name: "groups",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditionGroup{Logic: sc.LogicOr, Conditions: []interface{}{
+				statusOpen,
+				sc.WhereConditionGroup{Logic: sc.LogicAnd, Not: true, Conditions: []interface{}{
+					sc.WhereCondition{Field: "Author.Name", Condition: sc.ConditionILike, Value: "jo"},
+				}},
+			}}},
+			want:     `("status" = ? OR NOT (LOWER("author"."name") LIKE LOWER(?) ESCAPE '!'))`,
+			wantArgs: []interface{}{"open", "%jo%"},
+		},
+		{
+			name:     "empty or",
+			cond:     &sc.SelectionCondition{Where: sc.WhereConditionGroup{Logic: sc.LogicOr}},
+			want:     "1=0",
+			wantArgs: nil,
+		},
+		{
+			name:     "postgres",
+			cond:     &sc.SelectionCondition{Where: sc.WhereConditions{statusOpen, {Field: "Quantity", Condition: sc.ConditionLt, Value: 5}}},
+			opts:     []Option{WithDialect(Postgres)},
+			want:     `"status" = $1 AND "quantity" < $2`,
+			wantArgs: []interface{}{"open", 5},
+		},
+		{
+			name:     "mysql",
+			cond:     &sc.SelectionCondition{Where: sc.WhereConditions{statusOpen}},
+			opts:     []Option{WithDialect(MySQL)},
+			want:     "`status` = ?",
+			wantArgs: []interface{}{"open"},
+		},
+		{
+			name:     "sql server",
+			cond:     &sc.SelectionCondition{Where: sc.WhereConditions{statusOpen, {Field: "Quantity", Condition: sc.ConditionLt, Value: 5}}},
+			opts:     []Option{WithDialect(SQLServer)},
+			want:     `[status] = @p1 AND [quantity] < @p2`,
+			wantArgs: []interface{}{"open", 5},
+		},
+		{
+			name:     "column names",
+			cond:     &sc.SelectionCondition{Where: sc.WhereConditions{statusOpen}},
+			opts:     []Option{WithColumnName(func(field string) string { return "o_" + field })},
+			want:     `"o_Status" = ?`,
+			wantArgs: []interface{}{"open"},
+		},
+		{
+			name: "unknown condition",
+			cond: &sc.SelectionCondition{Where: sc.WhereConditions{{Field: "Status", Condition: "unknown", Value: "open"}}},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := Where(tt.cond, tt.opts...)
+			if tt.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("got args %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestNamedWhere(t *testing.T) {
+	cond := &sc.SelectionCondition{Where: sc.WhereConditions{
+		{Field: "Status", Condition: sc.ConditionIn, Value: []interface{}{"a", "b"}},
+		{Field: "Author.Name", Condition: sc.ConditionEq, Value: "john"},
+	}}
+	got, params, err := NamedWhere(cond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"status" IN (:status_0, :status_1) AND "author"."name" = :author_name_2`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	wantParams := map[string]interface{}{"status_0": "a", "status_1": "b", "author_name_2": "john"}
+	if !reflect.DeepEqual(params, wantParams) {
+		t.Errorf("got params %v, want %v", params, wantParams)
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	tests := []struct {
+		name string
+		cond *sc.SelectionCondition
+		opts []Option
+		want string
+	}{
+		{
+			name: "empty",
+			cond: &sc.SelectionCondition{},
+			want: "",
+		},
+		{
+			name: "directions, nulls and case",
+			cond: &sc.SelectionCondition{SortOrder: []sc.SortField{
+				{Field: "CreatedAt", Direction: sc.SortOrderDesc, Nulls: sc.NullsLast},
+				{Field: "Author.Name", Direction: sc.SortOrderAsc, CaseInsensitive: true},
+			}},
+			want: `"created_at" DESC NULLS LAST, LOWER("author"."name") ASC`,
+		},
+		{
+			name: "nulls without the syntax of the dialect",
+			cond: &sc.SelectionCondition{SortOrder: []sc.SortField{{Field: "CreatedAt", Direction: sc.SortOrderDesc, Nulls: sc.NullsLast}}},
+			opts: []Option{WithDialect(MySQL)},
+			want: "CASE WHEN `created_at` IS NULL THEN 1 ELSE 0 END ASC, `created_at` DESC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OrderBy(tt.cond, tt.opts...); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumns(t *testing.T) {
+	tests := []struct {
+		name string
+		cond *sc.SelectionCondition
+		want string
+	}{
+		{
+			name: "all",
+			cond: &sc.SelectionCondition{},
+			want: "*",
+		},
+		{
+			name: "projection",
+			cond: &sc.SelectionCondition{Fields: []string{"ID", "Author.Name"}, Distinct: true},
+			want: `DISTINCT "id", "author"."name"`,
+		},
+		{
+			name: "grouping",
+			cond: &sc.SelectionCondition{
+				GroupBy:    []string{"Status"},
+				Aggregates: []sc.Aggregate{{Func: sc.AggregateCount}, {Func: sc.AggregateSum, Field: "Amount"}},
+			},
+			want: `"status", COUNT(*) AS "count", SUM("amount") AS "sum_amount"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Columns(tt.cond); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
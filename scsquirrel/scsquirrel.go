@@ -0,0 +1,397 @@
+package scsquirrel
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+
+	sc "github.com/minipkg/selection_condition"
+	"github.com/minipkg/selection_condition/internal/naming"
+)
+
+type options struct {
+	columnName func(field string) string
+}
+
+type Option func(*options)
+
+// WithColumnName sets the function that maps a struct field name to a column name. Default is snake_case.
+func WithColumnName(fn func(field string) string) Option {
+	return func(o *options) {
+		o.columnName = fn
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		columnName: naming.SnakeCase,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Apply adds the columns of the projection with DISTINCT, where conditions, sort order, limit and offset of cond to b,
+// so b is made without columns if cond may have a projection: squirrel.Select().From("users").
+func Apply(b squirrel.SelectBuilder, cond *sc.SelectionCondition, opts ...Option) (squirrel.SelectBuilder, error) {
+	if cond == nil {
+		return b, nil
+	}
+	o := newOptions(opts)
+
+	if columns := o.columns(cond); len(columns) > 0 {
+		b = b.Columns(columns...)
+	}
+	if cond.Distinct {
+		b = b.Distinct()
+	}
+	if groupBy := o.groupBy(cond); len(groupBy) > 0 {
+		b = b.GroupBy(groupBy...)
+	}
+	having, err := o.having(cond)
+	if err != nil {
+		return b, err
+	}
+	if having != nil {
+		b = b.Having(having)
+	}
+
+	where, err := o.where(cond.Where)
+	if err != nil {
+		return b, err
+	}
+	if where != nil {
+		b = b.Where(where)
+	}
+
+	if orderBy := o.orderBy(cond); len(orderBy) > 0 {
+		b = b.OrderBy(orderBy...)
+	}
+
+	if cond.Limit > 0 {
+		b = b.Limit(uint64(cond.Limit))
+	}
+	if cond.Offset > 0 {
+		b = b.Offset(uint64(cond.Offset))
+	}
+	return b, nil
+}
+
+// Count returns the query counting the rows selected by cond, e.g. for the total of with_count=true,
+// b is made without columns: squirrel.Select().From("users"). Groups and distinct rows are counted by a subquery.
+func Count(b squirrel.SelectBuilder, cond *sc.SelectionCondition, opts ...Option) (squirrel.SelectBuilder, error) {
+	if cond == nil {
+		return b.Columns("COUNT(*)"), nil
+	}
+	cond = cond.ForCount()
+
+	b, err := Apply(b, cond, opts...)
+	if err != nil {
+		return b, err
+	}
+	if !cond.IsGrouped() {
+		return b.Columns("COUNT(*)"), nil
+	}
+	return squirrel.Select("COUNT(*)").FromSelect(b, "t"), nil
+}
+
+// Where returns nil if cond has no where conditions.
+func Where(cond *sc.SelectionCondition, opts ...Option) (squirrel.Sqlizer, error) {
+	if cond == nil {
+		return nil, nil
+	}
+	return newOptions(opts).where(cond.Where)
+}
+
+// Columns returns the columns of the projection, nil if there is none. The columns of grouped rows are
+// the ones of GROUP BY followed by the aggregates like "SUM(amount) AS sum_amount".
+func Columns(cond *sc.SelectionCondition, opts ...Option) []string {
+	if cond == nil {
+		return nil
+	}
+	return newOptions(opts).columns(cond)
+}
+
+func (o *options) columns(cond *sc.SelectionCondition) []string {
+	if len(cond.GroupBy) == 0 && len(cond.Aggregates) == 0 {
+		if len(cond.Fields) == 0 {
+			return nil
+		}
+		res := make([]string, 0, len(cond.Fields))
+		for _, field := range cond.Fields {
+			res = append(res, o.columnName(field))
+		}
+		return res
+	}
+
+	res := o.groupBy(cond)
+	for _, aggregate := range cond.Aggregates {
+		alias := aggregate.Func
+		if aggregate.Field != "" {
+			alias = naming.AggregateAlias(aggregate.Func, o.columnName(aggregate.Field))
+		}
+		res = append(res, o.aggregate(aggregate)+" AS "+alias)
+	}
+	return res
+}
+
+// aggregate returns the expression of the aggregate, e.g. "SUM(amount)" or "COUNT(*)".
+func (o *options) aggregate(aggregate sc.Aggregate) string {
+	column := "*"
+	if aggregate.Field != "" {
+		column = o.columnName(aggregate.Field)
+	}
+	return strings.ToUpper(aggregate.Func) + "(" + column + ")"
+}
+
+// Having returns nil if cond has no conditions on aggregates.
+func Having(cond *sc.SelectionCondition, opts ...Option) (squirrel.Sqlizer, error) {
+	if cond == nil {
+		return nil, nil
+	}
+	return newOptions(opts).having(cond)
+}
+
+func (o *options) having(cond *sc.SelectionCondition) (squirrel.Sqlizer, error) {
+	if len(cond.Having) == 0 {
+		return nil, nil
+	}
+	res := make(squirrel.And, 0, len(cond.Having))
+
+	for _, having := range cond.Having {
+		sqlizer, err := o.condition(o.aggregate(having.Aggregate), sc.WhereCondition{
+			Field:     having.Func + sc.AggregateFieldSeparator + having.Field,
+			Condition: having.Condition,
+			Value:     having.Value,
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, sqlizer)
+	}
+	return res, nil
+}
+
+// GroupBy returns the columns of the GROUP BY clause.
+func GroupBy(cond *sc.SelectionCondition, opts ...Option) []string {
+	if cond == nil {
+		return nil
+	}
+	return newOptions(opts).groupBy(cond)
+}
+
+func (o *options) groupBy(cond *sc.SelectionCondition) []string {
+	res := make([]string, 0, len(cond.GroupBy)+len(cond.Aggregates))
+
+	for _, field := range cond.GroupBy {
+		res = append(res, o.columnName(field))
+	}
+	return res
+}
+
+func OrderBy(cond *sc.SelectionCondition, opts ...Option) []string {
+	if cond == nil {
+		return nil
+	}
+	return newOptions(opts).orderBy(cond)
+}
+
+func (o *options) orderBy(cond *sc.SelectionCondition) []string {
+	res := make([]string, 0, len(cond.SortOrder))
+
+	for _, sortField := range cond.SortOrder {
+		column := o.columnName(sortField.Field)
+		if sortField.CaseInsensitive {
+			column = "LOWER(" + column + ")"
+		}
+		res = append(res, column+orderModifiers(sortField))
+	}
+	return res
+}
+
+// orderModifiers returns the direction of the field with the placement of nulls, e.g. " DESC NULLS LAST".
+func orderModifiers(sortField sc.SortField) string {
+	res := " ASC"
+	if sortField.Direction == sc.SortOrderDesc {
+		res = " DESC"
+	}
+	switch sortField.Nulls {
+	case sc.NullsFirst:
+		res += " NULLS FIRST"
+	case sc.NullsLast:
+		res += " NULLS LAST"
+	}
+	return res
+}
+
+func (o *options) where(where interface{}) (squirrel.Sqlizer, error) {
+	switch w := where.(type) {
+	case nil:
+		return nil, nil
+	case sc.WhereCondition:
+		return o.whereCondition(w)
+	case sc.WhereConditions:
+		return o.whereConditions(w)
+	case sc.WhereConditionGroup:
+		return o.group(w)
+	default:
+		return nil, errors.Errorf("Unsupported type of Where: %T", where)
+	}
+}
+
+func (o *options) whereConditions(conds []sc.WhereCondition) (squirrel.Sqlizer, error) {
+	if len(conds) == 0 {
+		return nil, nil
+	}
+	res := make(squirrel.And, 0, len(conds))
+
+	for _, cond := range conds {
+		sqlizer, err := o.whereCondition(cond)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, sqlizer)
+	}
+	return res, nil
+}
+
+func (o *options) group(group sc.WhereConditionGroup) (squirrel.Sqlizer, error) {
+	sqlizers := make([]squirrel.Sqlizer, 0, len(group.Conditions))
+
+	for _, item := range group.Conditions {
+		var sqlizer squirrel.Sqlizer
+		var err error
+
+		switch c := item.(type) {
+		case sc.WhereCondition:
+			sqlizer, err = o.whereCondition(c)
+		case sc.WhereConditionGroup:
+			sqlizer, err = o.group(c)
+		default:
+			err = errors.Errorf("Unsupported type of a group condition: %T", item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sqlizers = append(sqlizers, sqlizer)
+	}
+
+	var sqlizer squirrel.Sqlizer
+	switch group.Logic {
+	case sc.LogicAnd:
+		sqlizer = squirrel.And(sqlizers)
+	case sc.LogicOr:
+		sqlizer = squirrel.Or(sqlizers)
+	default:
+		return nil, errors.Errorf("Unsupported logic of a group: %q", group.Logic)
+	}
+
+	if group.Not {
+		return squirrel.Expr("NOT (?)", sqlizer), nil
+	}
+	return sqlizer, nil
+}
+
+func (o *options) whereCondition(cond sc.WhereCondition) (squirrel.Sqlizer, error) {
+	column := o.columnName(cond.Field)
+	if cond.JSONPath != "" {
+		column = sc.JSONPathExpression(column, cond.JSONPath)
+	}
+	return o.condition(column, cond)
+}
+
+// condition returns the condition on the column which may be an expression like SUM(amount).
+func (o *options) condition(column string, cond sc.WhereCondition) (squirrel.Sqlizer, error) {
+	switch cond.Condition {
+	case sc.ConditionEq:
+		return squirrel.Eq{column: cond.Value}, nil
+	case sc.ConditionGt:
+		return squirrel.Gt{column: cond.Value}, nil
+	case sc.ConditionGte:
+		return squirrel.GtOrEq{column: cond.Value}, nil
+	case sc.ConditionLt:
+		return squirrel.Lt{column: cond.Value}, nil
+	case sc.ConditionLte:
+		return squirrel.LtOrEq{column: cond.Value}, nil
+	case sc.ConditionIn:
+		values, ok := cond.Value.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice", cond.Condition, cond.Field)
+		}
+		return squirrel.Eq{column: values}, nil
+	case sc.ConditionBt:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return squirrel.Expr(column+" BETWEEN ? AND ?", values[0], values[1]), nil
+	case sc.ConditionFuzzy:
+		value, ok := cond.Value.(sc.Fuzzy)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.Fuzzy", cond.Condition, cond.Field)
+		}
+		return squirrel.Expr("similarity("+column+", ?) >= ?", value.Text, value.Similarity), nil
+	case sc.ConditionAny, sc.ConditionAll:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) == 0 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a non-empty slice", cond.Condition, cond.Field)
+		}
+		return squirrel.Expr(column+" "+arrayOperator(cond.Condition)+" ARRAY["+squirrel.Placeholders(len(values))+"]", values...), nil
+	case sc.ConditionJSONContains:
+		document, err := json.Marshal(cond.Value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Value of condition %q for field %s", cond.Condition, cond.Field)
+		}
+		return squirrel.Expr(column+" @> CAST(? AS jsonb)", string(document)), nil
+	case sc.ConditionNear:
+		value, ok := cond.Value.(sc.GeoNear)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoNear", cond.Condition, cond.Field)
+		}
+		return squirrel.Expr("ST_DWithin(CAST("+column+" AS geography), CAST(ST_SetSRID(ST_MakePoint(?, ?), 4326) AS geography), ?)",
+			value.Point.Lon, value.Point.Lat, value.Radius), nil
+	case sc.ConditionBBox:
+		value, ok := cond.Value.(sc.GeoBoundingBox)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a sc.GeoBoundingBox", cond.Condition, cond.Field)
+		}
+		return squirrel.Expr("ST_Intersects(CAST("+column+" AS geometry), ST_MakeEnvelope(?, ?, ?, ?, 4326))",
+			value.Min.Lon, value.Min.Lat, value.Max.Lon, value.Max.Lat), nil
+	case sc.ConditionOverlaps:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		rangeFunction, ok := sc.PostgresRangeFunction(values[0])
+		if !ok {
+			return nil, errors.Errorf("Bounds of type %T of condition %q for field %s are not supported", values[0], cond.Condition, cond.Field)
+		}
+		return squirrel.Expr(column+" && "+rangeFunction+"(?, ?, '[]')", values[0], values[1]), nil
+	case sc.ConditionBtExcl:
+		values, ok := cond.Value.([]interface{})
+		if !ok || len(values) != 2 {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a slice of two elements", cond.Condition, cond.Field)
+		}
+		return squirrel.And{squirrel.Gt{column: values[0]}, squirrel.Lt{column: values[1]}}, nil
+	case sc.ConditionIEq:
+		return squirrel.Expr("LOWER("+column+") = LOWER(?)", cond.Value), nil
+	case sc.ConditionILike:
+		value, ok := cond.Value.(string)
+		if !ok {
+			return nil, errors.Errorf("Value of condition %q for field %s must be a string", cond.Condition, cond.Field)
+		}
+		return squirrel.Expr("LOWER("+column+") LIKE LOWER(?) ESCAPE '"+sc.LikeEscape+"'", sc.ContainsPattern(value)), nil
+	}
+	return nil, errors.Errorf("Condition %q is not supported by squirrel adapter", cond.Condition)
+}
+
+// arrayOperator returns the operator of Postgres arrays for the condition any or all.
+func arrayOperator(condition string) string {
+	if condition == sc.ConditionAny {
+		return "&&"
+	}
+	return "@>"
+}
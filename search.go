@@ -0,0 +1,87 @@
+package selection_condition
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SearchParamName is the param of the free text search across the searchable fields: q=smith
+const SearchParamName = "q"
+
+// LikeEscape is the escape character of the patterns made by ContainsPattern: LIKE ? ESCAPE '!'
+const LikeEscape = "!"
+
+var likeEscaper = strings.NewReplacer(LikeEscape, LikeEscape+LikeEscape, "%", LikeEscape+"%", "_", LikeEscape+"_")
+
+// WithSearchFields sets the fields searched by the param q by their paths of Go names, e.g. "Name" or "Author.Name".
+// It overrides the fields tagged selection:"search".
+func WithSearchFields(paths ...string) Option {
+	return func(o *options) {
+		o.searchFields = append(o.searchFields, paths...)
+	}
+}
+
+// ContainsPattern returns the LIKE pattern matching text containing s, the wildcards of s are escaped by LikeEscape.
+func ContainsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// compileSearchFields returns the paths of Go names of the searchable fields of the struct type,
+// the ones of the options or the tagged ones in the order of the struct.
+func compileSearchFields(structType reflect.Type, root *structSchema, o *options) ([]string, error) {
+	if len(o.searchFields) > 0 {
+		paths := make([]string, 0, len(o.searchFields))
+		for _, path := range o.searchFields {
+			_, f, ok := root.field(path, true)
+			if !ok {
+				return nil, errors.Errorf("Unknown search field %s", path)
+			}
+			if f.typ.Kind() != reflect.String {
+				return nil, errors.Errorf("Search field %s must be a text field", path)
+			}
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+		return paths, nil
+	}
+
+	var paths []string
+	for goName, f := range root.byGoName {
+		if f.searchable && f.typ.Kind() == reflect.String {
+			paths = append(paths, goName)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		a, _ := structType.FieldByName(paths[i])
+		b, _ := structType.FieldByName(paths[j])
+		return slices.Compare(a.Index, b.Index) < 0
+	})
+	return paths, nil
+}
+
+// parseSearchParam parses the search text to the group of ilike conditions on the searchable fields joined by OR,
+// it returns false if the text is blank.
+func parseSearchParam(s *Schema, vals []string) (WhereConditionGroup, bool) {
+	text := strings.TrimSpace(vals[0])
+	if text == "" {
+		return WhereConditionGroup{}, false
+	}
+
+	group := WhereConditionGroup{
+		Logic:      LogicOr,
+		Conditions: make([]interface{}, 0, len(s.searchFields)),
+	}
+	for _, path := range s.searchFields {
+		group.Conditions = append(group.Conditions, WhereCondition{
+			Field:     path,
+			Condition: ConditionILike,
+			Value:     text,
+		})
+	}
+	return group, true
+}
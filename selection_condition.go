@@ -1,11 +1,17 @@
 package selection_condition
 
 import (
+	"context"
+	"database/sql"
+	"encoding"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -17,6 +23,27 @@ const (
 	SortOrderAsc       = "asc"
 	SortOrderDesc      = "desc"
 
+	// SortOrderDescPrefix is the prefix of a field sorted in the descending order: sort_order=-created_at,name
+	SortOrderDescPrefix = "-"
+
+	// SortNullsFirst and SortNullsLast set the placement of nulls after the direction: sort_order=ended_at__desc_nullslast
+	SortNullsFirst        = "nullsfirst"
+	SortNullsLast         = "nullslast"
+	SortModifierSeparator = "_"
+	// SortCaseInsensitive orders a text field ignoring the case: sort_order=name__asc_ci
+	SortCaseInsensitive = "ci"
+
+	NullsFirst = "first"
+	NullsLast  = "last"
+
+	// OrderByParamName is the param of the sort order in the syntax of AIP-132 enabled by WithOrderBy: order_by=name desc,id
+	OrderByParamName = "order_by"
+
+	LimitParamName   = "limit"
+	OffsetParamName  = "offset"
+	PageParamName    = "page"
+	PerPageParamName = "per_page"
+
 	ConditionSeparator = "__"
 	ValuesSeparator    = ","
 
@@ -27,12 +54,25 @@ const (
 	ConditionLte = "lte"
 	ConditionIn  = "in"
 	ConditionBt  = "bt"
-	ConditionTS  = "ts"
+	// ConditionBtExcl is bt without the bounds: price__bt_excl=100,500 is 100 < price < 500
+	ConditionBtExcl = "bt_excl"
+	ConditionTS     = "ts"
+	// ConditionILike matches text fields containing the value ignoring the case: name__ilike=smith
+	ConditionILike = "ilike"
+	// ConditionIEq matches text fields equal to the value ignoring the case: email__ieq=Foo@Bar.com
+	ConditionIEq = "ieq"
+	// ConditionAny matches list fields containing any of the values: tags__any=go,rust
+	ConditionAny = "any"
+	// ConditionAll matches list fields containing all of the values: tags__all=go,rust
+	ConditionAll = "all"
 
 	DefaultWhereCondition = ConditionEq
 	DefaultSortDirect     = SortOrderAsc
 )
 
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var sqlScannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
 var SortOrderVariants = []interface{}{"", SortOrderAsc, SortOrderDesc}
 
 var ConditionVariants = []interface{}{
@@ -43,33 +83,132 @@ var ConditionVariants = []interface{}{
 	ConditionLte,
 	ConditionIn,
 	ConditionBt,
+	ConditionBtExcl,
 	ConditionTS,
+	ConditionILike,
+	ConditionFuzzy,
+	ConditionIEq,
+	ConditionAny,
+	ConditionAll,
+	ConditionJSONContains,
+	ConditionNear,
+	ConditionBBox,
+	ConditionOverlaps,
 }
 
+// SelectionCondition is encoded in JSON for saved filters as
+//
+//	{
+//		"where": [{"field": "Age", "condition": "gte", "value": 18}],
+//		"sort_order": [{"field": "CreatedAt", "direction": "desc"}],
+//		"limit": 20,
+//		"offset": 40
+//	}
+//
+// where is a list of conditions joined by AND or a group like {"logic": "or", "not": true, "conditions": [...]},
+// see UnmarshalJSON for the types of decoded values. Fields is the projection by paths of Go names,
+// empty means all the fields, Distinct removes duplicates of its rows. WithCount requests the total number of the rows
+// along with them and CountOnly requests only the number, see ForCount. Include lists the relations to be loaded with the rows by paths of Go names.
+// GroupBy lists the fields the rows are grouped by and Aggregates are the functions of the groups,
+// Having are the conditions on the aggregates joined by AND.
 type SelectionCondition struct {
-	Where     interface{}
-	SortOrder []map[string]string
-	Limit     uint
-	Offset    uint
+	Where      Where             `json:"where"`
+	SortOrder  []SortField       `json:"sort_order"`
+	Limit      uint              `json:"limit"`
+	Offset     uint              `json:"offset"`
+	Fields     []string          `json:"fields,omitempty"`
+	Distinct   bool              `json:"distinct,omitempty"`
+	WithCount  bool              `json:"with_count,omitempty"`
+	CountOnly  bool              `json:"count_only,omitempty"`
+	Include    []string          `json:"include,omitempty"`
+	GroupBy    []string          `json:"group_by,omitempty"`
+	Aggregates []Aggregate       `json:"aggregates,omitempty"`
+	Having     []HavingCondition `json:"having,omitempty"`
+	// forcedFields are the fields of the forced conditions joined by parsing
+	forcedFields []string
 }
 
+// Validate validates the condition made by hand as parsing makes it: the where conditions and groups,
+// the fields and directions of the sort order and the page which must not go beyond the maximum of uint.
 func (e *SelectionCondition) Validate() error {
-	return validation.ValidateStruct(e) //validation.Field(e.SortOrder, validation.Each(validation.In(SortOrderVariants...))),
+	return validation.ValidateStruct(e,
+		validation.Field(&e.Where, validation.By(validateWhere)),
+		validation.Field(&e.SortOrder),
+		validation.Field(&e.Offset, validation.Max(uint(math.MaxUint)-e.Limit).Error("offset and limit must not exceed the maximum of uint")),
+	)
+}
+
+// validateWhere validates the where conditions of the types made by parsing.
+func validateWhere(value interface{}) error {
+	switch w := value.(type) {
+	case WhereConditions:
+		return w.Validate()
+	case WhereCondition, WhereConditionGroup:
+		return validateGroupCondition(w)
+	}
+	return nil
+}
 
+// SortField is a field of the sort order by its path of Go names, Direction is SortOrderAsc or SortOrderDesc.
+// Nulls is NullsFirst or NullsLast to place nulls before or after other values, empty means the default of the storage.
+// CaseInsensitive orders the values ignoring the case, e.g. by LOWER(field) in SQL.
+type SortField struct {
+	Field           string `json:"field"`
+	Direction       string `json:"direction"`
+	Nulls           string `json:"nulls,omitempty"`
+	CaseInsensitive bool   `json:"case_insensitive,omitempty"`
+}
+
+func (f SortField) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.Field, validation.Required),
+		validation.Field(&f.Direction, validation.Required, validation.In(SortOrderAsc, SortOrderDesc)),
+		validation.Field(&f.Nulls, validation.In(NullsFirst, NullsLast)),
+	)
+}
+
+// SortOrderFromMaps converts the sort order of the former form where each map holds a field and its direction.
+func SortOrderFromMaps(sortOrder []map[string]string) []SortField {
+	res := make([]SortField, 0, len(sortOrder))
+	for _, m := range sortOrder {
+		// several fields of a map are sorted by their names as a map has no order
+		fields := make([]string, 0, len(m))
+		for field := range m {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			res = append(res, SortField{Field: field, Direction: m[field]})
+		}
+	}
+	return res
+}
+
+// SortOrderMaps returns the sort order in the former form where each map holds a field and its direction.
+func (e *SelectionCondition) SortOrderMaps() []map[string]string {
+	res := make([]map[string]string, 0, len(e.SortOrder))
+	for _, sortField := range e.SortOrder {
+		res = append(res, map[string]string{sortField.Field: sortField.Direction})
+	}
+	return res
 }
 
 type WhereCondition struct {
-	Field     string
-	Condition string
-	Value     interface{}
+	Field     string      `json:"field"`
+	Condition string      `json:"condition"`
+	Value     interface{} `json:"value"`
+	// JSONPath is the path of the keys inside the document of a JSON field separated by dots, the value
+	// at the path is compared as a string
+	JSONPath string `json:"json_path,omitempty"`
 }
 
 type WhereConditions []WhereCondition
 
 func (s WhereCondition) Validate() error {
 	return validation.ValidateStruct(&s,
-		validation.Field(&s.Condition, validation.In(ConditionVariants...)),
-		validation.Field(&s.Value, validation.When(s.Condition == ConditionBt, validation.Length(2, 2))),
+		validation.Field(&s.Field, validation.Required),
+		validation.Field(&s.Condition, validation.Required, validation.In(conditionVariants()...)),
+		validation.Field(&s.Value, validation.When(s.Condition == ConditionBt || s.Condition == ConditionBtExcl, validation.Length(2, 2))),
 	)
 }
 
@@ -77,133 +216,832 @@ func (s WhereConditions) Validate() error {
 	return validation.Validate([]WhereCondition(s))
 }
 
-func ParseQueryParams(params map[string][]string, struc interface{}) (*SelectionCondition, error) {
-	structType, err := getTypeOfAStruct(struc)
-	if err != nil {
-		return nil, err
+// ParseQueryParams parses params by the fields of the struct pointed by struc, it is NewParser(opts...).Parse(params, struc).
+func ParseQueryParams(params map[string][]string, struc interface{}, opts ...Option) (*SelectionCondition, error) {
+	return NewParser(opts...).Parse(params, struc)
+}
+
+func parseQueryParams(ctx context.Context, params map[string][]string, s *Schema) (*SelectionCondition, error) {
+	return parseSelection(ctx, params, s, nil, nil)
+}
+
+// parseSelection parses params with the where conditions and the conditions of having parsed apart from them,
+// e.g. from a JSON body, so all of them are checked by the same rules.
+func parseSelection(ctx context.Context, params map[string][]string, s *Schema, where WhereConditions, having []HavingCondition) (*SelectionCondition, error) {
+	o := s.o
+	if o.jsonAPI {
+		params = jsonAPIParams(params, o)
 	}
 
-	conditions := SelectionCondition{}
-	whereConditions := make(WhereConditions, 0, len(params))
-	indexesByNames := structFieldIndexesByJsonName(structType)
+	conditions := SelectionCondition{Having: having}
+	whereConditions := make(WhereConditions, 0, len(params)+len(where))
+	whereConditions = append(whereConditions, where...)
+	var whereGroups []WhereConditionGroup
+	var page uint
+	var cursor string
+	var unknownParams []string
+	errs := &errorCollector{all: o.allErrors}
+
+	// params are parsed in the order of their names so the errors are the same for the same params
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	for key, vals := range params {
-		if len(vals) < 0 {
+	for _, key := range keys {
+		vals := params[key]
+		if len(vals) == 0 {
 			continue
 		}
 
-		sortOrderConditions, ok, err := parseSortOrderParam(structType, indexesByNames, key, vals)
+		ok, err := parsePaginationParam(&conditions, &page, o, key, vals)
 		if err != nil {
-			return nil, err
+			if !errs.add(err) {
+				return nil, err
+			}
+			continue
+		}
+		if ok {
+			continue
+		}
+
+		if key == o.cursorParamName {
+			cursor = vals[0]
+			continue
+		}
+
+		sortOrderConditions, ok, err := parseSortOrderParam(s, key, vals)
+		if err != nil {
+			if !errs.add(err) {
+				return nil, err
+			}
+			continue
 		}
 		if ok {
 			conditions.SortOrder = sortOrderConditions
 			continue
 		}
 
-		whereCondition, ok, err := parseWhereParam(structType, indexesByNames, key, vals)
+		if key == FieldsParamName {
+			fields, err := parseFieldsParam(s, vals)
+			if err != nil {
+				if !errs.add(err) {
+					return nil, err
+				}
+				continue
+			}
+			conditions.Fields = fields
+			continue
+		}
+
+		if key == DistinctParamName {
+			distinct, err := parseBoolParam(key, vals)
+			if err != nil {
+				if !errs.add(err) {
+					return nil, err
+				}
+				continue
+			}
+			conditions.Distinct = distinct
+			continue
+		}
+
+		if key == WithCountParamName || key == CountOnlyParamName {
+			value, err := parseBoolParam(key, vals)
+			if err != nil {
+				if !errs.add(err) {
+					return nil, err
+				}
+				continue
+			}
+			if key == WithCountParamName {
+				conditions.WithCount = value
+			} else {
+				conditions.CountOnly = value
+			}
+			continue
+		}
+
+		if key == IncludeParamName || key == ExpandParamName {
+			include, err := parseIncludeParam(s, key, vals)
+			if err != nil {
+				if !errs.add(err) {
+					return nil, err
+				}
+				continue
+			}
+			conditions.Include = append(conditions.Include, include...)
+			continue
+		}
+
+		if key == GroupByParamName {
+			groupBy, err := parseGroupByParam(s, vals)
+			if err != nil {
+				if !errs.add(err) {
+					return nil, err
+				}
+				continue
+			}
+			conditions.GroupBy = groupBy
+			continue
+		}
+
+		if key == AggregateParamName {
+			aggregates, err := parseAggregateParam(s, vals)
+			if err != nil {
+				if !errs.add(err) {
+					return nil, err
+				}
+				continue
+			}
+			conditions.Aggregates = aggregates
+			continue
+		}
+
+		if key == HavingParamName {
+			having, err := parseHavingParam(s, vals)
+			if err != nil {
+				if !errs.add(err) {
+					return nil, err
+				}
+				continue
+			}
+			conditions.Having = having
+			continue
+		}
+
+		if key == SearchParamName && len(s.searchFields) > 0 {
+			if group, ok := parseSearchParam(s, vals); ok {
+				whereGroups = append(whereGroups, group)
+			}
+			continue
+		}
+
+		if o.odata && key == ODataFilterParamName {
+			where, err := parseODataFilter(s, vals[0])
+			if err != nil {
+				if !errs.add(err) {
+					return nil, err
+				}
+				continue
+			}
+			// the items of the filter joined by and are joined with the other params
+			items := []interface{}{where}
+			if g, ok := where.(WhereConditionGroup); ok && g.Logic == LogicAnd && !g.Not {
+				items = g.Conditions
+			}
+			for _, item := range items {
+				switch w := item.(type) {
+				case WhereCondition:
+					whereConditions = append(whereConditions, w)
+				case WhereConditionGroup:
+					whereGroups = append(whereGroups, w)
+				}
+			}
+			continue
+		}
+
+		groups, ok, err := parseGroupParam(s, key, vals)
 		if err != nil {
-			return nil, err
+			if !errs.add(err) {
+				return nil, err
+			}
+			continue
+		}
+		if ok {
+			whereGroups = append(whereGroups, groups...)
+			continue
+		}
+
+		parseWhere := parseRepeatedWhereParam
+		if strings.HasSuffix(key, ArrayParamSuffix) {
+			parseWhere = parseArrayWhereParam
+		}
+		items, ok, err := parseWhere(s, key, vals)
+		if err != nil {
+			if !errs.add(err) {
+				return nil, err
+			}
+			continue
 		}
 		if !ok {
+			unknownParams = append(unknownParams, key)
 			continue
 		}
-		whereConditions = append(whereConditions, *whereCondition)
+		for _, item := range items {
+			switch w := item.(type) {
+			case WhereCondition:
+				whereConditions = append(whereConditions, w)
+			case WhereConditionGroup:
+				whereGroups = append(whereGroups, w)
+			}
+		}
+	}
+
+	if o.strictParams && len(unknownParams) > 0 {
+		err := newParamError(ErrUnknownParam, strings.Join(unknownParams, ValuesSeparator), "Unknown parameters: %s", strings.Join(unknownParams, ", "))
+		if !errs.add(err) {
+			return nil, err
+		}
+	}
+
+	conditions.Where = joinWhere(whereConditions, whereGroups)
+	if err := checkWhere(ctx, s, &conditions, errs); err != nil {
+		return nil, err
+	}
+
+	if len(conditions.SortOrder) == 0 && o.defaultSortOrder != "" {
+		sortOrder, _, err := parseSortOrderParam(s, SortOrderParamName, []string{o.defaultSortOrder})
+		if err != nil {
+			if !errs.add(err) {
+				return nil, err
+			}
+		}
+		conditions.SortOrder = sortOrder
+	}
+
+	if err := checkGrouping(&conditions, s); err != nil {
+		if !errs.add(err) {
+			return nil, err
+		}
+	}
+	if err := checkHaving(&conditions); err != nil {
+		if !errs.add(err) {
+			return nil, err
+		}
+	}
+
+	if err := applyLimitOptions(&conditions, o); err != nil {
+		if !errs.add(err) {
+			return nil, err
+		}
+	}
+
+	if page > 0 {
+		if conditions.Limit == 0 {
+			err := newParamError(ErrInvalidPagination, o.pageParamName, "Parameter %s requires parameter %s", o.pageParamName, o.perPageParamName)
+			if !errs.add(err) {
+				return nil, err
+			}
+		} else if page-1 > (math.MaxUint-conditions.Limit)/conditions.Limit {
+			// the offset and the limit of the page must not exceed the maximum of uint as Validate requires
+			err := newParamError(ErrInvalidPagination, o.pageParamName, "Parameter %s is too large", o.pageParamName)
+			if !errs.add(err) {
+				return nil, err
+			}
+		} else {
+			conditions.Offset = (page - 1) * conditions.Limit
+		}
+	}
+
+	if cursor != "" {
+		cursorCondition, cursorGroup, err := applyCursor(&conditions, s, cursor)
+		if err != nil {
+			err := newParamError(ErrInvalidCursor, o.cursorParamName, "%s", err)
+			if !errs.add(err) {
+				return nil, err
+			}
+		}
+		// the conditions of the cursor are not the client's ones, so they are joined after checkWhere
+		if cursorCondition != nil {
+			conditions.Where = andWhere(conditions.Where, *cursorCondition)
+		}
+		if cursorGroup != nil {
+			conditions.Where = andWhere(conditions.Where, *cursorGroup)
+		}
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
 	}
-	conditions.Where = whereConditions
 	return &conditions, nil
 }
 
-func parseWhereParam(structType reflect.Type, indexesByNames map[string]int, key string, vals []string) (*WhereCondition, bool, error) {
-	paramName, strCond, err := splitConditionParameterName(key)
+// joinWhere returns the conditions and the groups joined by AND: whereConditions if there are no groups,
+// otherwise a group with the conditions followed by the groups.
+func joinWhere(whereConditions WhereConditions, whereGroups []WhereConditionGroup) Where {
+	if len(whereGroups) == 0 {
+		return whereConditions
+	}
+
+	where := WhereConditionGroup{
+		Logic:      LogicAnd,
+		Conditions: make([]interface{}, 0, len(whereConditions)+len(whereGroups)),
+	}
+	for _, whereCondition := range whereConditions {
+		where.Conditions = append(where.Conditions, whereCondition)
+	}
+	for _, group := range whereGroups {
+		where.Conditions = append(where.Conditions, group)
+	}
+	return where
+}
+
+// checkWhere checks the where conditions parsed from the params or a filter of a client by the options and joins
+// the forced conditions with them: the maximum number of conditions, the required filters, the conditions
+// on the forced fields and contradictions. Every syntax is parsed by the same rules, so it is called by all of them.
+func checkWhere(ctx context.Context, s *Schema, conditions *SelectionCondition, errs *errorCollector) error {
+	o := s.o
+	whereConditions, whereGroups, err := splitWhere(conditions.Where)
+	if err != nil {
+		return err
+	}
+	if o.maxConditions > 0 && countConditions(whereConditions, whereGroups) > o.maxConditions {
+		err := newParamError(ErrTooManyConditions, "", "Number of conditions exceeds the maximum %d", o.maxConditions)
+		if !errs.add(err) {
+			return err
+		}
+	}
+
+	for _, field := range o.requiredFilters {
+		if !hasCondition(whereConditions, whereGroups, field) {
+			err := newParamError(ErrRequiredFilter, field, "Condition on field %s is required", field)
+			if !errs.add(err) {
+				return err
+			}
+		}
+	}
+
+	forced, err := forcedConditions(ctx, o)
+	if err != nil {
+		return err
+	}
+	for _, c := range forced {
+		if usesField(whereConditions, whereGroups, c.Field) {
+			paramName, _ := s.paramNameByPath(c.Field)
+			err := newParamError(ErrNotFilterable, paramName, "Field %s is not filterable", paramName)
+			if !errs.add(err) {
+				return err
+			}
+		}
+	}
+	if len(forced) > 0 {
+		conditions.Where = joinWhere(append(whereConditions, forced...), whereGroups)
+		conditions.setForcedFields(forced)
+	}
+
+	if o.rejectContradictions {
+		if contradictions := conditions.Contradictions(); len(contradictions) > 0 {
+			paramName, ok := s.paramNameByPath(contradictions[0].Field)
+			if !ok {
+				paramName = contradictions[0].Field
+			}
+			err := newParamError(ErrContradiction, paramName, "Conditions on field %s contradict each other", paramName)
+			if !errs.add(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// andWhere returns where joined by AND with the items which are WhereCondition or WhereConditionGroup like joinWhere.
+func andWhere(where Where, items ...interface{}) Where {
+	// where is made by parsing, so it has no items of other types
+	whereConditions, whereGroups, _ := splitWhere(where)
+	for _, item := range items {
+		switch c := item.(type) {
+		case WhereCondition:
+			whereConditions = append(whereConditions, c)
+		case WhereConditionGroup:
+			whereGroups = append(whereGroups, c)
+		}
+	}
+	return joinWhere(whereConditions, whereGroups)
+}
+
+// countConditions returns the number of the conditions including the ones inside the groups.
+func countConditions(whereConditions []WhereCondition, whereGroups []WhereConditionGroup) uint {
+	n := uint(len(whereConditions))
+	for _, group := range whereGroups {
+		for _, item := range group.Conditions {
+			switch c := item.(type) {
+			case WhereCondition:
+				n++
+			case WhereConditionGroup:
+				n += countConditions(nil, []WhereConditionGroup{c})
+			}
+		}
+	}
+	return n
+}
+
+// hasCondition reports whether there is a condition on the field which must be met by every selected row,
+// so the conditions of OR and negated groups are not taken into account.
+func hasCondition(whereConditions []WhereCondition, whereGroups []WhereConditionGroup, field string) bool {
+	for _, whereCondition := range whereConditions {
+		if whereCondition.Field == field {
+			return true
+		}
+	}
+
+	for _, group := range whereGroups {
+		if group.Logic != LogicAnd || group.Not {
+			continue
+		}
+		var conds []WhereCondition
+		var groups []WhereConditionGroup
+		for _, item := range group.Conditions {
+			switch c := item.(type) {
+			case WhereCondition:
+				conds = append(conds, c)
+			case WhereConditionGroup:
+				groups = append(groups, c)
+			}
+		}
+		if hasCondition(conds, groups, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePaginationParam sets Limit and Offset of conditions, in the page mode the number of the page is set to page
+// and the offset is to be calculated after all the params are parsed.
+func parsePaginationParam(conditions *SelectionCondition, page *uint, o *options, key string, vals []string) (bool, error) {
+	var dest *uint
+	switch {
+	case o.pagination == PaginationLimitOffset && key == o.limitParamName:
+		dest = &conditions.Limit
+	case o.pagination == PaginationLimitOffset && key == o.offsetParamName:
+		dest = &conditions.Offset
+	case o.pagination == PaginationPage && key == o.perPageParamName:
+		dest = &conditions.Limit
+	case o.pagination == PaginationPage && key == o.pageParamName:
+		dest = page
+	default:
+		return false, nil
+	}
+
+	value, err := strconv.ParseUint(vals[0], 10, 0)
+	if err != nil {
+		return false, &ErrBadValue{Field: key, Raw: vals[0], Kind: "uint", Err: errors.New("must be a non-negative integer")}
+	}
+	if dest == page && value == 0 {
+		return false, &ErrBadValue{Field: key, Raw: vals[0], Kind: "uint", Err: errors.New("must be a positive integer")}
+	}
+	*dest = uint(value)
+	return true, nil
+}
+
+func parseBoolParam(key string, vals []string) (bool, error) {
+	value, err := strconv.ParseBool(vals[0])
+	if err != nil {
+		return false, &ErrBadValue{Field: key, Raw: vals[0], Kind: "bool", Err: err}
+	}
+	return value, nil
+}
+
+func applyLimitOptions(conditions *SelectionCondition, o *options) error {
+	if conditions.Limit == 0 {
+		conditions.Limit = o.defaultLimit
+	}
+	if o.maxLimit == 0 {
+		return nil
+	}
+
+	if conditions.Limit == 0 {
+		conditions.Limit = o.maxLimit
+		return nil
+	}
+	if conditions.Limit <= o.maxLimit {
+		return nil
+	}
+
+	if o.limitPolicy == LimitReject {
+		return newParamError(ErrInvalidPagination, o.limitParamName, "Limit %d exceeds the maximum %d", conditions.Limit, o.maxLimit)
+	}
+	conditions.Limit = o.maxLimit
+	return nil
+}
+
+func parseWhereParam(s *Schema, key string, vals []string) (*WhereCondition, bool, error) {
+	return parseWhereValue(s, key, vals[0], nil)
+}
+
+// parseWhereList parses the list condition of the key with the values of a list of a filter syntax like OData,
+// each value is converted as it is even if it has the separator of values.
+func parseWhereList(s *Schema, key string, values []string) (*WhereCondition, bool, error) {
+	return parseWhereValue(s, key, strings.Join(values, s.o.valuesSeparator), values)
+}
+
+// parseWhereValue parses the condition of the key with the raw value of a param, items are the values
+// of a list condition if they are parsed apart.
+func parseWhereValue(s *Schema, key string, raw string, items []string) (*WhereCondition, bool, error) {
+	o := s.o
+	paramName, strCond, err := splitConditionParameterName(key, o)
 	if err != nil {
 		return nil, false, err
 	}
 
-	fieldName, fieldKind, ok := getFieldNameAndKindByName(structType, indexesByNames, paramName)
+	fieldName, field, ok := s.fieldByParamName(paramName)
+	var jsonPath string
+	if !ok {
+		if fieldName, jsonPath, ok, err = s.jsonFieldByParamName(paramName); err != nil {
+			return nil, false, err
+		}
+		if ok {
+			field, _ = s.fieldByPath(fieldName)
+		}
+	}
 	if !ok {
+		if o.strictFilters {
+			return nil, false, newParamError(ErrUnknownField, paramName, "Unknown field %s", paramName)
+		}
 		return nil, false, nil
 	}
-
-	value, err := string2valByCondition(vals[0], strCond, fieldKind)
+	if o.strictFilters && !field.filterable {
+		return nil, false, newParamError(ErrNotFilterable, paramName, "Field %s is not filterable", paramName)
+	}
+	if !s.isConditionAllowed(fieldName, field, strCond) {
+		return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q is not allowed for field %s", strCond, paramName)
+	}
+	fieldType := field.typ
+	if jsonPath != "" {
+		if strCond == ConditionJSONContains || strCond == ConditionAny || strCond == ConditionAll || isGeoCondition(strCond) {
+			return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q is not supported for JSON paths", strCond)
+		}
+		fieldType = stringType
+	}
+	if strCond == ConditionJSONContains && (jsonPath != "" || !isJSONType(fieldType)) {
+		return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q requires a JSON field, %s is not", strCond, paramName)
+	}
+	if isGeoCondition(strCond) && !isGeoType(fieldType) {
+		return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q requires a geo field, %s is not", strCond, paramName)
+	}
+	if isUUIDType(fieldType) && strCond != ConditionEq && strCond != ConditionIn {
+		return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q is not supported for UUID values", strCond)
+	}
+	if isTextCondition(strCond) && fieldType.Kind() != reflect.String {
+		return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q requires a text field, %s is not", strCond, paramName)
+	}
+	if strCond == ConditionAny || strCond == ConditionAll {
+		if (fieldType.Kind() != reflect.Slice && fieldType.Kind() != reflect.Array) || fieldType.Elem().Kind() == reflect.Uint8 {
+			return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q requires a list field, %s is not", strCond, paramName)
+		}
+		// the values are the ones of the elements of the list
+		fieldType = valueType(fieldType.Elem())
+	}
+	custom, isCustom := customConditionByName(strCond)
+	var value interface{}
+	switch {
+	case strCond == ConditionOverlaps:
+		if !isRangeType(fieldType) {
+			return nil, false, newParamError(ErrInvalidOperator, paramName, "Condition %q requires a range field, %s is not", strCond, paramName)
+		}
+		var bounds []interface{}
+		bounds, err = parseBounds(raw, valueType(fieldType.Elem()), o)
+		value = bounds
+	case isCustom:
+		value, err = custom.parse(raw, fieldType, o)
+	case items != nil && isListCondition(strCond):
+		value, err = string2vals(items, strCond, fieldType, o)
+	default:
+		value, strCond, err = parseConditionValue(raw, strCond, fieldType, o)
+	}
 	if err != nil {
-		return nil, false, err
+		return nil, false, &ErrBadValue{Field: paramName, Raw: raw, Kind: fieldType.String(), Err: err}
+	}
+	if value, err = s.transformValue(fieldName, field, value); err != nil {
+		return nil, false, &ErrBadValue{Field: paramName, Raw: raw, Kind: fieldType.String(), Err: err}
+	}
+	if field.enum != nil && jsonPath == "" && isEnumCondition(strCond) {
+		if err := checkEnum(field.enum, strCond, value); err != nil {
+			return nil, false, &ErrBadValue{Field: paramName, Raw: raw, Kind: fieldType.String(), Err: err}
+		}
+	}
+	if rules, ok := s.filterRules[fieldName]; ok && jsonPath == "" && isRuleCondition(strCond) {
+		if err := validateFilterValue(rules, value); err != nil {
+			return nil, false, &ErrBadValue{Field: paramName, Raw: raw, Kind: fieldType.String(), Err: err}
+		}
 	}
 
-	return &WhereCondition{
+	cond := &WhereCondition{
 		Field:     fieldName,
 		Condition: strCond,
 		Value:     value,
-	}, true, nil
+		JSONPath:  jsonPath,
+	}
+	if isCustom {
+		if custom.Validate != nil {
+			if err := custom.Validate(value); err != nil {
+				return nil, false, &ErrBadValue{Field: paramName, Raw: raw, Kind: fieldType.String(), Err: err}
+			}
+		}
+		if custom.Expand != nil {
+			expanded, err := custom.Expand(fieldName, value)
+			if err != nil {
+				return nil, false, &ErrBadValue{Field: paramName, Raw: raw, Kind: fieldType.String(), Err: err}
+			}
+			cond = &expanded
+		}
+	}
+	return cond, true, nil
+}
+
+// parseConditionValue parses the value of the condition on a field of type typ, it returns the condition
+// of the value as one bound of bt makes it gte or lte and a date of eq makes it bt for the whole day.
+func parseConditionValue(strValue string, condition string, typ reflect.Type, o *options) (interface{}, string, error) {
+	if halfOpen, bound, ok := halfOpenCondition(strValue, condition, o); ok {
+		condition, strValue = halfOpen, bound
+	}
+	if typ == timeType {
+		value, dateCondition, ok, err := parseDateCondition(strValue, condition, o)
+		if err != nil {
+			return nil, "", err
+		}
+		if ok {
+			return value, dateCondition, nil
+		}
+	}
+	value, err := string2valByCondition(strValue, condition, typ, o)
+	return value, condition, err
 }
 
-func parseSortOrderParam(structType reflect.Type, indexesByNames map[string]int, key string, vals []string) ([]map[string]string, bool, error) {
-	if key != SortOrderParamName {
+// halfOpenCondition returns the condition of a between with one bound only: price__bt=100, is price__gte=100
+// and price__bt=,500 is price__lte=500, bt_excl gives gt and lt. It returns false if there are no two values
+// with exactly one of them empty.
+func halfOpenCondition(strValue string, condition string, o *options) (string, string, bool) {
+	if condition != ConditionBt && condition != ConditionBtExcl {
+		return "", "", false
+	}
+	from, to, ok := strings.Cut(strValue, o.valuesSeparator)
+	if !ok || strings.Contains(to, o.valuesSeparator) {
+		return "", "", false
+	}
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	switch {
+	case from != "" && to == "":
+		if condition == ConditionBtExcl {
+			return ConditionGt, from, true
+		}
+		return ConditionGte, from, true
+	case from == "" && to != "":
+		if condition == ConditionBtExcl {
+			return ConditionLt, to, true
+		}
+		return ConditionLte, to, true
+	}
+	return "", "", false
+}
+
+// isListCondition reports whether the value of the condition is a list.
+func isListCondition(condition string) bool {
+	switch condition {
+	case ConditionIn, ConditionBt, ConditionBtExcl, ConditionAny, ConditionAll, ConditionOverlaps:
+		return true
+	}
+	if custom, ok := customConditionByName(condition); ok {
+		return custom.Arity != 1
+	}
+	return false
+}
+
+// isTextCondition reports whether the condition is only for text fields.
+func isTextCondition(condition string) bool {
+	return condition == ConditionILike || condition == ConditionFuzzy || condition == ConditionIEq
+}
+
+func parseSortOrderParam(s *Schema, key string, vals []string) ([]SortField, bool, error) {
+	o := s.o
+	var split func(param string) (string, SortField, error)
+	switch {
+	case key == SortOrderParamName:
+		split = func(param string) (string, SortField, error) {
+			return splitSortOrderParameterName(param, o)
+		}
+	case o.orderBy && key == OrderByParamName:
+		split = func(item string) (string, SortField, error) {
+			return splitOrderByItem(item, OrderByParamName)
+		}
+	case o.odata && key == ODataOrderByParamName:
+		split = func(item string) (string, SortField, error) {
+			return splitOrderByItem(item, ODataOrderByParamName)
+		}
+	default:
 		return nil, false, nil
 	}
-	params := strings.Split(vals[0], ",")
-	sortOrderParams := make([]map[string]string, 0, len(params))
+	params := strings.Split(vals[0], o.valuesSeparator)
+	sortOrderParams := make([]SortField, 0, len(params))
 
 	for _, param := range params {
-		paramName, sortDirect, err := splitSortOrderParameterName(param)
+		paramName, sortField, err := split(param)
 		if err != nil {
 			return nil, false, err
 		}
 
-		fieldName, _, ok := getFieldNameAndKindByName(structType, indexesByNames, paramName)
+		fieldName, field, ok := s.fieldByParamName(paramName)
 		if !ok {
+			if o.strictSort || o.strictParams {
+				return nil, false, newParamError(ErrUnknownField, paramName, "Unknown field %s", paramName)
+			}
 			continue
 		}
-		sortOrderParams = append(sortOrderParams, map[string]string{fieldName: sortDirect})
+		if o.strictSort && !field.sortable {
+			return nil, false, newParamError(ErrNotSortable, paramName, "Field %s is not sortable", paramName)
+		}
+		sortField.Field = fieldName
+		sortOrderParams = append(sortOrderParams, sortField)
 	}
 
 	return sortOrderParams, true, nil
 }
 
+// splitOrderByItem splits an item of the param in the syntax of AIP-132 like "name desc" to the field and the direction.
+func splitOrderByItem(item string, param string) (string, SortField, error) {
+	words := strings.Fields(item)
+	switch len(words) {
+	case 1:
+		return words[0], SortField{Direction: DefaultSortDirect}, nil
+	case 2:
+		sortOrder := strings.ToLower(words[1])
+		if sortOrder == SortOrderAsc || sortOrder == SortOrderDesc {
+			return words[0], SortField{Direction: sortOrder}, nil
+		}
+	}
+	return "", SortField{}, newParamError(ErrInvalidParam, param, "Item %q of parameter %s must be in the form field [asc|desc]", item, param)
+}
+
 func getTypeOfAStruct(struc interface{}) (reflect.Type, error) {
 	stVal := reflect.ValueOf(struc)
-	stType := stVal.Type()
-	if stType.Kind() != reflect.Ptr {
-		return nil, fmt.Errorf("Parameter struc must be a pointer")
+	if stVal.Kind() != reflect.Ptr {
+		return nil, newParamError(ErrInvalidStruct, "struc", "Parameter struc must be a pointer")
 	}
 
 	stValElem := stVal.Elem()
 
 	outPtrType := stValElem.Kind()
 	if outPtrType != reflect.Struct {
-		return nil, fmt.Errorf("Parameter struc must be a pointer on a struct")
+		return nil, newParamError(ErrInvalidStruct, "struc", "Parameter struc must be a pointer on a struct")
 	}
 	return stValElem.Type(), nil
 }
 
-func getFieldNameAndKind(structType reflect.Type, fieldIndex int) (fieldName string, fieldKind reflect.Kind) {
-	field := structType.Field(fieldIndex)
-	return field.Name, field.Type.Kind()
+// valueType returns the type of values of a field of type typ, e.g. int64 for *int64 or sql.NullInt64.
+func valueType(typ reflect.Type) reflect.Type {
+	for {
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+			continue
+		}
+		if nullType, ok := nullableValueType(typ); ok {
+			typ = nullType
+			continue
+		}
+		return typ
+	}
 }
 
-func getFieldNameAndKindByName(structType reflect.Type, indexesByNames map[string]int, paramName string) (fieldName string, fieldKind reflect.Kind, ok bool) {
-	fieldIndex, ok := indexesByNames[paramName]
-	if !ok {
-		return "", fieldKind, false
+// nullableValueType returns the type of the value of database/sql Null* types like sql.NullString or sql.Null[T],
+// that is a scanner struct with the value field followed by the Valid field.
+func nullableValueType(typ reflect.Type) (reflect.Type, bool) {
+	if typ.Kind() != reflect.Struct || typ.NumField() != 2 || !reflect.PtrTo(typ).Implements(sqlScannerType) {
+		return nil, false
 	}
-	fieldName, fieldKind = getFieldNameAndKind(structType, fieldIndex)
-	return fieldName, fieldKind, true
+
+	valid := typ.Field(1)
+	if valid.Name != "Valid" || valid.Type.Kind() != reflect.Bool {
+		return nil, false
+	}
+	return typ.Field(0).Type, true
 }
 
-func string2valByCondition(strValue string, condition string, kind reflect.Kind) (value interface{}, err error) {
+func string2valByCondition(strValue string, condition string, typ reflect.Type, o *options) (value interface{}, err error) {
 	var isSlice bool
 	var strValues []string
 
-	if condition == ConditionIn || condition == ConditionBt {
+	switch condition {
+	case ConditionFuzzy:
+		return parseFuzzyValue(strValue)
+	case ConditionJSONContains:
+		return parseJSONDocument(strValue)
+	case ConditionNear:
+		return parseGeoNearValue(strValue, o)
+	case ConditionBBox:
+		return parseGeoBoundingBoxValue(strValue, o)
+	}
+
+	if isListCondition(condition) {
+		// values are counted before splitting to not allocate for a huge list
+		n := strings.Count(strValue, o.valuesSeparator) + 1
+		twoValues := condition == ConditionBt || condition == ConditionBtExcl
+		if twoValues && n != 2 {
+			return nil, errors.Errorf("Condition %q requires two values, got %d", condition, n)
+		}
+		if !twoValues && o.maxListValues > 0 && uint(n) > o.maxListValues {
+			return nil, errors.Wrapf(ErrTooManyValues, "Condition %q accepts at most %d values, got %d", condition, o.maxListValues, n)
+		}
+
 		isSlice = true
-		strValues = strings.Split(strValue, ValuesSeparator)
+		strValues = strings.Split(strValue, o.valuesSeparator)
 	}
 
 	if isSlice {
 		vals := make([]interface{}, 0, len(strValues))
 
 		for _, v := range strValues {
-			val, err := string2val(v, kind)
+			val, err := string2val(v, typ, o)
 			if err != nil {
 				return nil, err
 			}
@@ -212,11 +1050,33 @@ func string2valByCondition(strValue string, condition string, kind reflect.Kind)
 		sliceSort(vals)
 		value = vals
 	} else {
-		value, err = string2val(strValue, kind)
+		value, err = string2val(strValue, typ, o)
 	}
 	return value, err
 }
 
+// string2vals converts the values of a list condition parsed apart like string2valByCondition does the joined ones.
+func string2vals(strValues []string, condition string, typ reflect.Type, o *options) (interface{}, error) {
+	twoValues := condition == ConditionBt || condition == ConditionBtExcl
+	if twoValues && len(strValues) != 2 {
+		return nil, errors.Errorf("Condition %q requires two values, got %d", condition, len(strValues))
+	}
+	if !twoValues && o.maxListValues > 0 && uint(len(strValues)) > o.maxListValues {
+		return nil, errors.Wrapf(ErrTooManyValues, "Condition %q accepts at most %d values, got %d", condition, o.maxListValues, len(strValues))
+	}
+
+	vals := make([]interface{}, 0, len(strValues))
+	for _, v := range strValues {
+		val, err := string2val(v, typ, o)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+	sliceSort(vals)
+	return vals, nil
+}
+
 func sliceSort(sl []interface{}) {
 	sort.Slice(sl, func(i, j int) bool {
 		if iEl, ok := sl[i].(string); ok {
@@ -238,19 +1098,39 @@ func sliceSort(sl []interface{}) {
 		if iEl, ok := sl[i].(float64); ok {
 			return iEl < sl[j].(float64)
 		}
+
+		if iEl, ok := sl[i].(time.Time); ok {
+			return iEl.Before(sl[j].(time.Time))
+		}
 		return false
 	})
 	return
 }
 
-func string2val(strValue string, kind reflect.Kind) (value interface{}, err error) {
+func string2val(strValue string, typ reflect.Type, o *options) (value interface{}, err error) {
+	if value, ok, err := convert(strValue, typ); ok {
+		return value, err
+	}
+	if typ == timeType {
+		return parseTimeValue(strValue, o)
+	}
+	if isUUIDType(typ) {
+		return parseUUID(strValue, typ)
+	}
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		ptr := reflect.New(typ)
+		if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(strValue)); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
 
-	switch kind {
+	switch typ.Kind() {
 	case reflect.Bool:
 		value, err = strconv.ParseBool(strValue)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		value, err = strconv.ParseUint(strValue, 10, 64)
-	case reflect.Int:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		value, err = strconv.ParseInt(strValue, 10, 64)
 	case reflect.Float32, reflect.Float64:
 		value, err = strconv.ParseFloat(strValue, 64)
@@ -262,29 +1142,64 @@ func string2val(strValue string, kind reflect.Kind) (value interface{}, err erro
 	return value, err
 }
 
-func splitConditionParameterName(param string) (field string, condition string, err error) {
-	return splitParameterName(param, DefaultWhereCondition, ConditionVariants)
+func splitConditionParameterName(param string, o *options) (field string, condition string, err error) {
+	return splitParameterName(param, o.conditionSeparator, DefaultWhereCondition, conditionVariants())
 }
 
-func splitSortOrderParameterName(param string) (field string, sortOrder string, err error) {
-	return splitParameterName(param, DefaultSortDirect, SortOrderVariants)
+// splitSortOrderParameterName splits an item of sort_order like "-name", "name__desc" or "name__desc_nullslast_ci"
+// to the field and its direction with the modifiers.
+func splitSortOrderParameterName(param string, o *options) (string, SortField, error) {
+	field, descPrefix := strings.CutPrefix(param, SortOrderDescPrefix)
+	field, modifiers, hasModifiers := strings.Cut(field, o.conditionSeparator)
+	if strings.Contains(modifiers, o.conditionSeparator) {
+		return "", SortField{}, newParamError(ErrInvalidParam, param, "Must be only one separator %q in name of parameter %s", o.conditionSeparator, param)
+	}
+
+	var sortField SortField
+	if hasModifiers {
+		for _, modifier := range strings.Split(modifiers, SortModifierSeparator) {
+			switch {
+			case (modifier == SortOrderAsc || modifier == SortOrderDesc) && sortField.Direction == "":
+				sortField.Direction = modifier
+			case modifier == SortNullsFirst && sortField.Nulls == "":
+				sortField.Nulls = NullsFirst
+			case modifier == SortNullsLast && sortField.Nulls == "":
+				sortField.Nulls = NullsLast
+			case modifier == SortCaseInsensitive && !sortField.CaseInsensitive:
+				sortField.CaseInsensitive = true
+			default:
+				return "", SortField{}, newParamError(ErrInvalidOperator, param, "Unknown operator %q in name of parameter %s", modifiers, param)
+			}
+		}
+	}
+
+	if descPrefix {
+		if sortField.Direction != "" {
+			return "", SortField{}, newParamError(ErrInvalidParam, param, "Item %s of the sort order with prefix %q must have no direction", param, SortOrderDescPrefix)
+		}
+		sortField.Direction = SortOrderDesc
+	}
+	if sortField.Direction == "" {
+		sortField.Direction = DefaultSortDirect
+	}
+	return field, sortField, nil
 }
 
-func splitParameterName(param string, defaultCondition string, variants []interface{}) (field string, condition string, err error) {
-	if !strings.Contains(param, ConditionSeparator) {
+func splitParameterName(param string, separator string, defaultCondition string, variants []interface{}) (field string, condition string, err error) {
+	if !strings.Contains(param, separator) {
 		return param, defaultCondition, nil
 	}
 
-	s := strings.Split(param, ConditionSeparator)
+	s := strings.Split(param, separator)
 	if len(s) != 2 {
-		return "", "", errors.Errorf("Must be only one separator %q in name of parameter %s", ConditionSeparator, param)
+		return "", "", newParamError(ErrInvalidParam, param, "Must be only one separator %q in name of parameter %s", separator, param)
 	}
 
 	field = s[0]
 	condition = s[1]
 	err = validation.Validate(condition, validation.In(variants...))
 	if err != nil {
-		return "", "", err
+		return "", "", newParamError(ErrInvalidOperator, param, "Unknown operator %q in name of parameter %s", condition, param)
 	}
 
 	return field, condition, nil
@@ -330,6 +1245,16 @@ func strings2struct(data interface{}, out interface{}) error {
 	outPtrType := reflect.Indirect(outVal).Kind()
 	dataVal := reflect.ValueOf(data)
 
+	if str, ok := data.(string); ok {
+		value, ok, err := convert(str, outValElem.Type())
+		if err != nil {
+			return err
+		}
+		if ok {
+			return setConverted(outValElem, value)
+		}
+	}
+
 	switch outPtrType {
 	case reflect.Bool:
 		str, ok := data.(string)
@@ -410,7 +1335,7 @@ func strings2struct(data interface{}, out interface{}) error {
 		}
 		iter := dataVal.MapRange()
 
-		indexesByNames := structFieldIndexesByJsonName(outValElem.Type())
+		indexesByNames := cachedStructFieldIndexes(outValElem.Type(), DefaultTagName)
 
 		for iter.Next() {
 			k := iter.Key()
@@ -420,7 +1345,7 @@ func strings2struct(data interface{}, out interface{}) error {
 			if !ok {
 				continue
 			}
-			field := outValElem.Field(i)
+			field := fieldByIndexAlloc(outValElem, i)
 
 			if !field.CanAddr() {
 				return fmt.Errorf("Cannot get address!")
@@ -437,21 +1362,94 @@ func strings2struct(data interface{}, out interface{}) error {
 	return nil
 }
 
-func structFieldIndexesByJsonName(struc reflect.Type) map[string]int {
+type fieldIndexesKey struct {
+	structType reflect.Type
+	tagName    string
+}
+
+// fieldIndexesCache holds the results of structFieldIndexesByTagName by fieldIndexesKey.
+var fieldIndexesCache sync.Map
+
+// cachedStructFieldIndexes returns the result of structFieldIndexesByTagName made once for the struct and the tag,
+// the result must not be changed.
+func cachedStructFieldIndexes(struc reflect.Type, tagName string) map[string][]int {
+	key := fieldIndexesKey{structType: struc, tagName: tagName}
+	if indexes, ok := fieldIndexesCache.Load(key); ok {
+		return indexes.(map[string][]int)
+	}
+	indexes, _ := fieldIndexesCache.LoadOrStore(key, structFieldIndexesByTagName(struc, tagName))
+	return indexes.(map[string][]int)
+}
+
+// structFieldIndexesByTagName returns indexes of the fields by their names in the tag, the fields of embedded structs
+// without a name in the tag are included as promoted ones unless the outer struct has fields with the same names.
+func structFieldIndexesByTagName(struc reflect.Type, tagName string) map[string][]int {
 	numField := struc.NumField()
-	res := make(map[string]int, numField)
+	res := make(map[string][]int, numField)
+	var embedded []int
 
 	for i := 0; i < numField; i++ {
 		field := struc.Field(i)
-		name := field.Tag.Get("json")
+		name, ok := tagFieldName(field, tagName)
+		if !ok {
+			continue
+		}
+		if name == "" && field.Anonymous && valueType(field.Type).Kind() == reflect.Struct {
+			embedded = append(embedded, i)
+			continue
+		}
 		if name == "" {
 			name = field.Name
 		}
-		res[name] = i
+		res[name] = []int{i}
+	}
+
+	for _, i := range embedded {
+		field := struc.Field(i)
+		embeddedType := field.Type
+		if embeddedType.Kind() == reflect.Ptr {
+			embeddedType = embeddedType.Elem()
+		}
+
+		for name, index := range structFieldIndexesByTagName(embeddedType, tagName) {
+			if _, ok := res[name]; ok || !embeddedType.FieldByIndex(index).IsExported() {
+				continue
+			}
+			res[name] = append([]int{i}, index...)
+		}
 	}
 	return res
 }
 
+// tagFieldName returns the name of the field from the tag without options like in json, it returns false
+// if the field is skipped by the tag "-".
+func tagFieldName(field reflect.StructField, tagName string) (string, bool) {
+	tag := field.Tag.Get(tagName)
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+// fieldByIndexAlloc returns the field of the struct by its index allocating nil pointers on embedded structs.
+// The result is invalid if a nil pointer cannot be set, e.g. it is on an unexported struct.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
 func IntSlice2EmptyInterfaceSlice(sl []int) []interface{} {
 	res := make([]interface{}, len(sl))
 	for i, val := range sl {
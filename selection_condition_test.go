@@ -0,0 +1,127 @@
+package selection_condition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type testAuthor struct {
+	ID    uint   `json:"id" selection:"filter"`
+	Name  string `json:"name" selection:"filter,sort"`
+	Email string `json:"email" selection:"filter"`
+}
+
+type testOrder struct {
+	ID        uint       `json:"id" selection:"filter,sort"`
+	Status    string     `json:"status" selection:"filter,sort"`
+	Amount    float64    `json:"amount" selection:"filter,sort"`
+	Quantity  int        `json:"quantity" selection:"filter,sort"`
+	Paid      bool       `json:"paid" selection:"filter"`
+	Comment   *string    `json:"comment" selection:"filter"`
+	CreatedAt time.Time  `json:"created_at" selection:"filter,sort"`
+	Note      string     `json:"note"`
+	Author    testAuthor `json:"author" selection:"filter"`
+}
+
+func TestParseQueryParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string][]string
+		opts   []Option
+		want   *SelectionCondition
+		err    error
+	}{
+		{
+			name:   "empty",
+			params: map[string][]string{},
+			want:   &SelectionCondition{Where: WhereConditions{}},
+		},
+		{
+			name: "conditions, sort and page",
+			params: map[string][]string{
+				"status__in":  {"open,new"},
+				"amount__gte": {"10.5"},
+				"author.name": {"john"},
+				"sort_order":  {"created_at__desc,id"},
+				"limit":       {"20"},
+				"offset":      {"40"},
+			},
+			want: &SelectionCondition{
+				Where: WhereConditions{
+					{Field: "Amount", Condition: ConditionGte, Value: 10.5},
+					{Field: "Author.Name", Condition: ConditionEq, Value: "john"},
+					{Field: "Status", Condition: ConditionIn, Value: []interface{}{"new", "open"}},
+				},
+				SortOrder: []SortField{
+					{Field: "CreatedAt", Direction: SortOrderDesc},
+					{Field: "ID", Direction: SortOrderAsc},
+				},
+				Limit:  20,
+				Offset: 40,
+			},
+		},
+		{
+			name:   "bool and pointer",
+			params: map[string][]string{"paid": {"true"}, "comment__ilike": {"%late%"}},
+			want: &SelectionCondition{
+				Where: WhereConditions{
+					{Field: "Comment", Condition: ConditionILike, Value: "%late%"},
+					{Field: "Paid", Condition: ConditionEq, Value: true},
+				},
+			},
+		},
+		{
+			name:   "unknown field",
+			params: map[string][]string{"unknown": {"1"}},
+			want:   &SelectionCondition{Where: WhereConditions{}},
+		},
+		{
+			name:   "not filterable",
+			params: map[string][]string{"note": {"x"}},
+			opts:   []Option{WithStrictFilters()},
+			err:    ErrNotFilterable,
+		},
+		{
+			name:   "not sortable",
+			params: map[string][]string{"sort_order": {"paid"}},
+			opts:   []Option{WithStrictSort()},
+			err:    ErrNotSortable,
+		},
+		{
+			name:   "bad value",
+			params: map[string][]string{"quantity": {"many"}},
+			err:    &ErrBadValue{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQueryParams(tt.params, &testOrder{}, tt.opts...)
+			checkError(t, err, tt.err)
+			if tt.err == nil && !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got.normalizedJSON(), tt.want.normalizedJSON())
+			}
+		})
+	}
+}
+
+// checkError fails the test if err is not want, a want of the type *ErrBadValue matches any error of the type.
+func checkError(t *testing.T, err error, want error) {
+	t.Helper()
+	switch w := want.(type) {
+	case nil:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case *ErrBadValue:
+		if !errors.As(err, &w) {
+			t.Fatalf("got error %v, want a bad value", err)
+		}
+	default:
+		if !errors.Is(err, want) {
+			t.Fatalf("got error %v, want %v", err, want)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package selection_condition
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Simplify replaces the where conditions by equivalent fewer ones, so queries are smaller and the same selections
+// made in different ways tend to have the same Hash:
+//   - equal conditions of a group are merged and nested groups of the same logic are flattened,
+//   - of the bounds of numbers and times on a field joined by AND only the tightest ones are kept,
+//   - gte and lte on a field joined by AND are joined to bt, gt and lt to bt_excl,
+//   - in on a field joined by AND with eq on it of one of its values is removed,
+//   - eq and in on a field joined by OR are joined to a single in.
+//
+// A group left with a single condition is replaced by it unless it is negated. Bounds of strings are kept
+// as they are since their order depends on the collation of a database. The sort order and the pagination
+// are not changed.
+func (e *SelectionCondition) Simplify() {
+	switch w := e.Where.(type) {
+	case WhereConditions:
+		items := simplifyItems(LogicAnd, w.Group().Conditions)
+		res := make(WhereConditions, 0, len(items))
+		for _, item := range items {
+			res = append(res, item.(WhereCondition))
+		}
+		e.Where = res
+	case WhereConditionGroup:
+		e.Where = asWhere(simplifyGroup(w))
+	}
+}
+
+// simplifyGroup returns the simplified group, a group of a single item which is not negated is returned as the item.
+func simplifyGroup(group WhereConditionGroup) interface{} {
+	items := make([]interface{}, 0, len(group.Conditions))
+	for _, item := range group.Conditions {
+		nested, ok := item.(WhereConditionGroup)
+		if !ok {
+			items = append(items, item)
+			continue
+		}
+		simplified := simplifyGroup(nested)
+		// a AND (b AND c) is a AND b AND c
+		if g, ok := simplified.(WhereConditionGroup); ok && g.Logic == group.Logic && !g.Not {
+			items = append(items, g.Conditions...)
+			continue
+		}
+		items = append(items, simplified)
+	}
+	items = simplifyItems(group.Logic, items)
+
+	if len(items) == 1 && !group.Not {
+		return items[0]
+	}
+	group.Conditions = items
+	return group
+}
+
+// simplifyItems merges the equal items joined by the logic and the conditions on the same fields,
+// the merged conditions on a field take the place of the first one of them.
+func simplifyItems(logic string, items []interface{}) []interface{} {
+	unique := make([]interface{}, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	byField := make(map[string][]WhereCondition)
+	for _, item := range items {
+		key := normalizationKey(item)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, item)
+		if c, ok := item.(WhereCondition); ok {
+			byField[simplifyFieldKey(c)] = append(byField[simplifyFieldKey(c)], c)
+		}
+	}
+
+	res := make([]interface{}, 0, len(unique))
+	for _, item := range unique {
+		c, ok := item.(WhereCondition)
+		if !ok {
+			res = append(res, item)
+			continue
+		}
+		conds, ok := byField[simplifyFieldKey(c)]
+		if !ok {
+			// the conditions on the field are already added
+			continue
+		}
+		delete(byField, simplifyFieldKey(c))
+		if logic == LogicOr {
+			conds = simplifyOr(conds)
+		} else {
+			conds = simplifyAnd(conds)
+		}
+		for _, c := range conds {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func simplifyFieldKey(c WhereCondition) string {
+	return c.Field + "\x00" + c.JSONPath
+}
+
+// simplifyAnd returns the conditions on a field joined by AND without the redundant ones.
+func simplifyAnd(conds []WhereCondition) []WhereCondition {
+	if len(conds) < 2 {
+		return conds
+	}
+
+	var eqValues []interface{}
+	for _, c := range conds {
+		if c.Condition == ConditionEq {
+			eqValues = append(eqValues, c.Value)
+		}
+	}
+	lower := tightestBound(conds, ConditionGt, ConditionGte, 1)
+	upper := tightestBound(conds, ConditionLt, ConditionLte, -1)
+
+	res := make([]WhereCondition, 0, len(conds))
+	for i, c := range conds {
+		switch c.Condition {
+		case ConditionIn:
+			// x = a AND x IN (a, b) is x = a
+			if containsAnyValue(c.Value, eqValues) {
+				continue
+			}
+		case ConditionGt, ConditionGte:
+			if lower >= 0 && i != lower {
+				continue
+			}
+		case ConditionLt, ConditionLte:
+			if upper >= 0 && i != upper {
+				continue
+			}
+		}
+		res = append(res, c)
+	}
+	return joinBounds(res)
+}
+
+// joinBounds joins a single lower bound and a single upper bound of the same inclusion to bt or bt_excl
+// in the place of the first of them.
+func joinBounds(conds []WhereCondition) []WhereCondition {
+	lower, upper := -1, -1
+	for i, c := range conds {
+		switch c.Condition {
+		case ConditionGt, ConditionGte:
+			if lower >= 0 {
+				return conds
+			}
+			lower = i
+		case ConditionLt, ConditionLte:
+			if upper >= 0 {
+				return conds
+			}
+			upper = i
+		}
+	}
+	if lower < 0 || upper < 0 {
+		return conds
+	}
+
+	var condition string
+	switch {
+	case conds[lower].Condition == ConditionGte && conds[upper].Condition == ConditionLte:
+		condition = ConditionBt
+	case conds[lower].Condition == ConditionGt && conds[upper].Condition == ConditionLt:
+		condition = ConditionBtExcl
+	default:
+		return conds
+	}
+
+	joined := WhereCondition{
+		Field:     conds[lower].Field,
+		Condition: condition,
+		Value:     []interface{}{conds[lower].Value, conds[upper].Value},
+		JSONPath:  conds[lower].JSONPath,
+	}
+	res := make([]WhereCondition, 0, len(conds)-1)
+	for i, c := range conds {
+		switch i {
+		case min(lower, upper):
+			res = append(res, joined)
+		case max(lower, upper):
+		default:
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// tightestBound returns the index of the tightest of the bounds of the conditions exclusive and inclusive,
+// sign is 1 for lower bounds and -1 for upper ones. It returns -1 if there are no such bounds or some of them
+// are not numbers or times.
+func tightestBound(conds []WhereCondition, exclusive string, inclusive string, sign int) int {
+	res := -1
+	for i, c := range conds {
+		if c.Condition != exclusive && c.Condition != inclusive {
+			continue
+		}
+		if !isOrderedBound(c.Value) {
+			return -1
+		}
+		if res < 0 {
+			res = i
+			continue
+		}
+		cmp, ok := compareConditionValues(c.Value, conds[res].Value)
+		if !ok {
+			return -1
+		}
+		// of equal values the exclusive bound is the tighter one
+		if cmp*sign > 0 || (cmp == 0 && c.Condition == exclusive) {
+			res = i
+		}
+	}
+	return res
+}
+
+// isOrderedBound reports whether the value is a number or a time, which are ordered in the same way by databases.
+func isOrderedBound(value interface{}) bool {
+	v, ok := comparableValue(reflect.ValueOf(value))
+	if !ok {
+		return false
+	}
+	switch v.(type) {
+	case int64, uint64, float64, time.Time:
+		return true
+	}
+	return false
+}
+
+// simplifyOr returns the conditions on a field joined by OR with the eq and in ones joined to a single in.
+func simplifyOr(conds []WhereCondition) []WhereCondition {
+	first := -1
+	var values []interface{}
+	n := 0
+	for i, c := range conds {
+		switch c.Condition {
+		case ConditionEq:
+			if c.Value == nil {
+				continue
+			}
+			values = append(values, c.Value)
+		case ConditionIn:
+			list, ok := conditionValues(c.Value)
+			if !ok {
+				continue
+			}
+			values = append(values, list...)
+		default:
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		n++
+	}
+	if n < 2 {
+		return conds
+	}
+
+	joined := WhereCondition{
+		Field:     conds[first].Field,
+		Condition: ConditionIn,
+		Value:     sortConditionValues(uniqueValues(values)),
+		JSONPath:  conds[first].JSONPath,
+	}
+	res := make([]WhereCondition, 0, len(conds)-n+1)
+	for i, c := range conds {
+		switch {
+		case i == first:
+			res = append(res, joined)
+		case c.Condition == ConditionEq && c.Value != nil:
+		case c.Condition == ConditionIn && isConditionValues(c.Value):
+		default:
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// conditionValues returns the values of a list condition, it returns false if the value is not a slice.
+func conditionValues(value interface{}) ([]interface{}, bool) {
+	if values, ok := value.([]interface{}); ok {
+		return values, true
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || isUUIDType(v.Type()) {
+		return nil, false
+	}
+	values := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		values = append(values, v.Index(i).Interface())
+	}
+	return values, true
+}
+
+func isConditionValues(value interface{}) bool {
+	_, ok := conditionValues(value)
+	return ok
+}
+
+// containsAnyValue reports whether the values of the list condition contain any of the values.
+func containsAnyValue(list interface{}, values []interface{}) bool {
+	items, ok := conditionValues(list)
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		for _, value := range values {
+			if equalConditionValues(item, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func uniqueValues(values []interface{}) []interface{} {
+	res := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		duplicate := false
+		for _, v := range res {
+			if equalConditionValues(v, value) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			res = append(res, value)
+		}
+	}
+	return res
+}
+
+// sortConditionValues orders the values if all of them are comparable, otherwise they are kept as they are.
+func sortConditionValues(values []interface{}) []interface{} {
+	for i := 1; i < len(values); i++ {
+		if _, ok := compareConditionValues(values[i-1], values[i]); !ok {
+			return values
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		cmp, _ := compareConditionValues(values[i], values[j])
+		return cmp < 0
+	})
+	return values
+}
+
+func equalConditionValues(a, b interface{}) bool {
+	if cmp, ok := compareConditionValues(a, b); ok {
+		return cmp == 0
+	}
+	return normalizationKey(a) == normalizationKey(b)
+}
+
+// compareConditionValues compares values of conditions like the ones of fields, numbers of different types
+// are compared by their values. It returns false if the values are not comparable.
+func compareConditionValues(a, b interface{}) (int, bool) {
+	value, ok := comparableValue(reflect.ValueOf(a))
+	if !ok {
+		return 0, false
+	}
+	cmp, err := compareWithValue(value, b)
+	return cmp, err == nil
+}
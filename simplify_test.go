@@ -0,0 +1,120 @@
+package selection_condition
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSimplify(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	statusOpen := WhereCondition{Field: "Status", Condition: ConditionEq, Value: "open"}
+	statusNew := WhereCondition{Field: "Status", Condition: ConditionEq, Value: "new"}
+	paid := WhereCondition{Field: "Paid", Condition: ConditionEq, Value: true}
+
+	tests := []struct {
+		name  string
+		where Where
+		want  Where
+	}{
+		{
+			name:  "nil",
+			where: nil,
+			want:  nil,
+		},
+		{
+			name:  "equal conditions",
+			where: WhereConditions{statusOpen, paid, statusOpen},
+			want:  WhereConditions{statusOpen, paid},
+		},
+		{
+			name: "tightest bounds",
+			where: WhereConditions{
+				{Field: "Amount", Condition: ConditionGt, Value: 10.0},
+				{Field: "Amount", Condition: ConditionGt, Value: 20.0},
+				{Field: "CreatedAt", Condition: ConditionLt, Value: day},
+				{Field: "CreatedAt", Condition: ConditionLte, Value: day.Add(time.Hour)},
+			},
+			want: WhereConditions{
+				{Field: "Amount", Condition: ConditionGt, Value: 20.0},
+				{Field: "CreatedAt", Condition: ConditionLt, Value: day},
+			},
+		},
+		{
+			name: "gte and lte are bt",
+			where: WhereConditions{
+				{Field: "Quantity", Condition: ConditionGte, Value: 1},
+				{Field: "Quantity", Condition: ConditionLte, Value: 5},
+			},
+			want: WhereConditions{{Field: "Quantity", Condition: ConditionBt, Value: []interface{}{1, 5}}},
+		},
+		{
+			name: "gt and lt are bt_excl",
+			where: WhereConditions{
+				{Field: "Quantity", Condition: ConditionGt, Value: 1},
+				{Field: "Quantity", Condition: ConditionLt, Value: 5},
+			},
+			want: WhereConditions{{Field: "Quantity", Condition: ConditionBtExcl, Value: []interface{}{1, 5}}},
+		},
+		{
+			name: "in with eq of its value",
+			where: WhereConditions{
+				{Field: "Status", Condition: ConditionIn, Value: []interface{}{"open", "new"}},
+				statusOpen,
+			},
+			want: WhereConditions{statusOpen},
+		},
+		{
+			name: "eq and in joined by or",
+			where: WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{
+				statusOpen,
+				WhereCondition{Field: "Status", Condition: ConditionIn, Value: []interface{}{"new", "paid"}},
+			}},
+			want: WhereCondition{Field: "Status", Condition: ConditionIn, Value: []interface{}{"new", "open", "paid"}},
+		},
+		{
+			name: "nested groups of the same logic",
+			where: WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{
+				paid,
+				WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{statusOpen, paid}},
+			}},
+			want: WhereConditionGroup{Logic: LogicAnd, Conditions: []interface{}{paid, statusOpen}},
+		},
+		{
+			name: "negated group of a single condition",
+			where: WhereConditionGroup{Logic: LogicAnd, Not: true, Conditions: []interface{}{
+				WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{statusOpen, statusOpen}},
+			}},
+			want: WhereConditionGroup{Logic: LogicAnd, Not: true, Conditions: []interface{}{statusOpen}},
+		},
+		{
+			name: "different fields are kept",
+			where: WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{
+				statusNew,
+				paid,
+			}},
+			want: WhereConditionGroup{Logic: LogicOr, Conditions: []interface{}{statusNew, paid}},
+		},
+		{
+			name: "bounds of strings are kept",
+			where: WhereConditions{
+				{Field: "Status", Condition: ConditionGt, Value: "a"},
+				{Field: "Status", Condition: ConditionGt, Value: "b"},
+			},
+			want: WhereConditions{
+				{Field: "Status", Condition: ConditionGt, Value: "a"},
+				{Field: "Status", Condition: ConditionGt, Value: "b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := &SelectionCondition{Where: tt.where}
+			cond.Simplify()
+			if !reflect.DeepEqual(cond.Where, tt.want) {
+				t.Errorf("got %#v, want %#v", cond.Where, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package selection_condition
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SelectionTagName is the struct tag listing what can be done with the field in a selection, e.g.
+//
+//	Status string `json:"status" selection:"filter"`
+const SelectionTagName = "selection"
+
+const (
+	// SelectionFilter allows conditions on the field if parsing is made with WithStrictFilters.
+	SelectionFilter = "filter"
+	// SelectionSort allows sorting by the field if parsing is made with WithStrictSort.
+	SelectionSort = "sort"
+	// SelectionSearch makes the text field searched by the param q.
+	SelectionSearch = "search"
+	// SelectionConditions lists the only conditions allowed on the field, e.g. selection:"filter,conditions=eq|in"
+	SelectionConditions = "conditions"
+	// SelectionTransform lists the names of the transformers of values of conditions on the field,
+	// e.g. selection:"filter,transform=trim|lower"
+	SelectionTransform = "transform"
+
+	selectionTagSeparator      = ","
+	selectionTagValueSeparator = "="
+	selectionTagListSeparator  = "|"
+)
+
+// hasSelectionTag reports whether the selection tag of the field contains the item.
+func hasSelectionTag(field reflect.StructField, item string) bool {
+	for _, tagItem := range strings.Split(field.Tag.Get(SelectionTagName), selectionTagSeparator) {
+		if strings.TrimSpace(tagItem) == item {
+			return true
+		}
+	}
+	return false
+}
+
+// selectionTagValue returns the value of the item of the selection tag of the field set as key=value.
+func selectionTagValue(field reflect.StructField, key string) (string, bool) {
+	for _, tagItem := range strings.Split(field.Tag.Get(SelectionTagName), selectionTagSeparator) {
+		k, v, ok := strings.Cut(strings.TrimSpace(tagItem), selectionTagValueSeparator)
+		if ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// tagTransformers returns the names of the transformers of values of the field by its selection tag.
+func tagTransformers(field reflect.StructField) []string {
+	value, ok := selectionTagValue(field, SelectionTransform)
+	if !ok {
+		return nil
+	}
+	return strings.Split(value, selectionTagListSeparator)
+}
+
+// tagConditions returns the only conditions allowed on the field by its selection tag, nil means any.
+func tagConditions(field reflect.StructField) []string {
+	value, ok := selectionTagValue(field, SelectionConditions)
+	if !ok {
+		return nil
+	}
+	return strings.Split(value, selectionTagListSeparator)
+}
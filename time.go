@@ -0,0 +1,171 @@
+package selection_condition
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var DefaultTimeLayouts = []string{time.RFC3339}
+
+const RelativeTimeNow = "now"
+
+var relativeTimeUnits = map[string]time.Duration{
+	"w": 7 * 24 * time.Hour,
+	"d": 24 * time.Hour,
+	"h": time.Hour,
+	"m": time.Minute,
+	"s": time.Second,
+}
+
+func parseTimeValue(strValue string, o *options) (time.Time, error) {
+	if strings.HasPrefix(strValue, RelativeTimeNow) {
+		return parseRelativeTime(strValue, o.now())
+	}
+	return parseTime(strValue, o.timeLayouts)
+}
+
+// parseRelativeTime parses values like "now", "now-7d", "now-1h30m" or "now+1w".
+func parseRelativeTime(strValue string, now time.Time) (time.Time, error) {
+	expr := strings.TrimPrefix(strValue, RelativeTimeNow)
+	if expr == "" {
+		return now, nil
+	}
+
+	var sign time.Duration
+	switch expr[0] {
+	case '-':
+		sign = -1
+	case '+':
+		sign = 1
+	default:
+		return time.Time{}, errors.Errorf("Relative time %q must be in the form now[+-]<number><unit>..., units are w, d, h, m, s", strValue)
+	}
+
+	d, err := parseRelativeDuration(expr[1:])
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "Relative time %q must be in the form now[+-]<number><unit>..., units are w, d, h, m, s", strValue)
+	}
+	return now.Add(sign * d), nil
+}
+
+func parseRelativeDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, errors.New("empty duration")
+	}
+	var res time.Duration
+
+	for s != "" {
+		i := 0
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		if i == 0 {
+			return 0, errors.Errorf("number expected in %q", s)
+		}
+		n, err := strconv.ParseInt(s[:i], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		s = s[i:]
+
+		j := 0
+		for j < len(s) && (s[j] < '0' || '9' < s[j]) {
+			j++
+		}
+		unit, ok := relativeTimeUnits[s[:j]]
+		if !ok {
+			return 0, errors.Errorf("unknown unit %q", s[:j])
+		}
+		if n > math.MaxInt64/int64(unit) || res > math.MaxInt64-time.Duration(n)*unit {
+			return 0, errors.New("duration overflows the maximum of about 292 years")
+		}
+		res += time.Duration(n) * unit
+		s = s[j:]
+	}
+	return res, nil
+}
+
+func parseTime(strValue string, layouts []string) (time.Time, error) {
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, strValue)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("Value %q does not match any of the time layouts %q", strValue, layouts)
+}
+
+const DateLayout = "2006-01-02"
+
+// parseDateCondition expands date-only values of a time field to the boundaries of the days:
+// gte and lt get the start of the day, gt and lte get the end of it, bt gets the start of the first day
+// and the end of the last one, bt_excl gets the end of the first day and the start of the last one,
+// eq becomes bt for the whole day. It returns false if there are no date-only values.
+func parseDateCondition(strValue string, condition string, o *options) (value interface{}, resCondition string, ok bool, err error) {
+	switch condition {
+	case ConditionEq, ConditionGt, ConditionGte, ConditionLt, ConditionLte:
+		day, ok := parseDate(strValue, o.timeLocation)
+		if !ok {
+			return nil, "", false, nil
+		}
+
+		switch condition {
+		case ConditionEq:
+			return []interface{}{day, endOfDay(day)}, ConditionBt, true, nil
+		case ConditionGt, ConditionLte:
+			return endOfDay(day), condition, true, nil
+		}
+		return day, condition, true, nil
+	case ConditionBt, ConditionBtExcl:
+		strValues := strings.Split(strValue, o.valuesSeparator)
+		if len(strValues) != 2 {
+			return nil, "", false, nil
+		}
+
+		var hasDate bool
+		bounds := make([]time.Time, 2)
+		isDate := make([]bool, 2)
+		for i, v := range strValues {
+			if bounds[i], isDate[i] = parseDate(v, o.timeLocation); isDate[i] {
+				hasDate = true
+				continue
+			}
+			if bounds[i], err = parseTimeValue(v, o); err != nil {
+				return nil, "", false, err
+			}
+		}
+		if !hasDate {
+			return nil, "", false, nil
+		}
+
+		if bounds[1].Before(bounds[0]) {
+			bounds[0], bounds[1] = bounds[1], bounds[0]
+			isDate[0], isDate[1] = isDate[1], isDate[0]
+		}
+		if condition == ConditionBtExcl {
+			if isDate[0] {
+				bounds[0] = endOfDay(bounds[0])
+			}
+		} else if isDate[1] {
+			bounds[1] = endOfDay(bounds[1])
+		}
+		return []interface{}{bounds[0], bounds[1]}, condition, true, nil
+	}
+	return nil, "", false, nil
+}
+
+func parseDate(strValue string, loc *time.Location) (time.Time, bool) {
+	day, err := time.ParseInLocation(DateLayout, strValue, loc)
+	return day, err == nil
+}
+
+func endOfDay(day time.Time) time.Time {
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
@@ -0,0 +1,121 @@
+package selection_condition
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ValueTransformer transforms a value of a condition parsed from a param, e.g. normalizes a phone number.
+// It gets a value of the type made by parsing of the field: a string, an int64, a time.Time and so on,
+// values of list conditions are transformed one by one.
+type ValueTransformer func(value interface{}) (interface{}, error)
+
+// Names of the transformers for the selection tag: selection:"filter,transform=trim|lower"
+const (
+	TransformTrim  = "trim"
+	TransformLower = "lower"
+	TransformUpper = "upper"
+)
+
+var (
+	valueTransformersMu sync.RWMutex
+	// valueTransformers are the transformers by their names in the selection tag
+	valueTransformers = map[string]ValueTransformer{
+		TransformTrim:  StringTransformer(strings.TrimSpace),
+		TransformLower: StringTransformer(strings.ToLower),
+		TransformUpper: StringTransformer(strings.ToUpper),
+	}
+)
+
+// StringTransformer returns the transformer of string values by fn, values of other types are left as they are.
+func StringTransformer(fn func(string) string) ValueTransformer {
+	return func(value interface{}) (interface{}, error) {
+		if s, ok := value.(string); ok {
+			return fn(s), nil
+		}
+		return value, nil
+	}
+}
+
+// RegisterValueTransformer registers the transformer by its name for the selection tag, e.g. "phone"
+// for selection:"filter,transform=phone". It replaces a transformer registered by the name before.
+func RegisterValueTransformer(name string, transformer ValueTransformer) {
+	valueTransformersMu.Lock()
+	defer valueTransformersMu.Unlock()
+	valueTransformers[name] = transformer
+}
+
+func valueTransformerByName(name string) (ValueTransformer, bool) {
+	valueTransformersMu.RLock()
+	defer valueTransformersMu.RUnlock()
+	transformer, ok := valueTransformers[name]
+	return transformer, ok
+}
+
+// WithValueTransformers adds transformers of values of conditions on all the fields,
+// they are applied before the ones of the fields.
+func WithValueTransformers(transformers ...ValueTransformer) Option {
+	return func(o *options) {
+		o.valueTransformers = append(o.valueTransformers, transformers...)
+	}
+}
+
+// WithFieldValueTransformers adds transformers of values of conditions on fields by their paths of Go names,
+// e.g. {"Email": {sc.StringTransformer(strings.ToLower)}}. They are applied after the ones of the selection tag.
+func WithFieldValueTransformers(transformers map[string][]ValueTransformer) Option {
+	return func(o *options) {
+		if o.fieldValueTransformers == nil {
+			o.fieldValueTransformers = make(map[string][]ValueTransformer, len(transformers))
+		}
+		for field, fieldTransformers := range transformers {
+			o.fieldValueTransformers[field] = append(o.fieldValueTransformers[field], fieldTransformers...)
+		}
+	}
+}
+
+// transformValue applies the transformers of the options and of the selection tag of the field by its path
+// of Go names to the value, the values of a list are sorted again after it.
+func (s *Schema) transformValue(path string, f *schemaField, value interface{}) (interface{}, error) {
+	fieldTransformers := s.o.fieldValueTransformers[path]
+	if len(s.o.valueTransformers) == 0 && len(f.transformers) == 0 && len(fieldTransformers) == 0 {
+		return value, nil
+	}
+
+	transformers := make([]ValueTransformer, 0, len(s.o.valueTransformers)+len(f.transformers)+len(fieldTransformers))
+	transformers = append(transformers, s.o.valueTransformers...)
+	for _, name := range f.transformers {
+		transformer, ok := valueTransformerByName(name)
+		if !ok {
+			return nil, errors.Errorf("Unknown value transformer %s of field %s", name, path)
+		}
+		transformers = append(transformers, transformer)
+	}
+	transformers = append(transformers, fieldTransformers...)
+
+	transform := func(value interface{}) (interface{}, error) {
+		var err error
+		for _, transformer := range transformers {
+			if value, err = transformer(value); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		return transform(value)
+	}
+	res := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		v, err := transform(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	sliceSort(res)
+	return res, nil
+}
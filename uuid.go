@@ -0,0 +1,38 @@
+package selection_condition
+
+import (
+	"encoding/hex"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// isUUIDType reports whether typ is a UUID, that is [16]byte or a type based on it like uuid.UUID.
+func isUUIDType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Len() == 16 && typ.Elem().Kind() == reflect.Uint8
+}
+
+// parseUUID parses a UUID in the canonical form xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx, also without hyphens
+// or enclosed in braces, and returns it as a value of typ.
+func parseUUID(strValue string, typ reflect.Type) (interface{}, error) {
+	s := strings.TrimSuffix(strings.TrimPrefix(strValue, "{"), "}")
+	if len(s) == 36 {
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return nil, errors.Errorf("Invalid UUID %q", strValue)
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	}
+	if len(s) != 32 {
+		return nil, errors.Errorf("Invalid UUID %q", strValue)
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.Errorf("Invalid UUID %q", strValue)
+	}
+
+	value := reflect.New(typ).Elem()
+	reflect.Copy(value, reflect.ValueOf(b))
+	return value.Interface(), nil
+}
@@ -0,0 +1,92 @@
+package selection_condition
+
+import "github.com/pkg/errors"
+
+// Visitor is called by Visit for the parts of a condition in their order: the where conditions with the groups
+// around them, the fields of the sort order and the page.
+type Visitor interface {
+	// EnterGroup is called before the conditions of a group and LeaveGroup after them,
+	// the conditions of WhereConditions are visited without a group
+	EnterGroup(group WhereConditionGroup) error
+	LeaveGroup(group WhereConditionGroup) error
+	VisitCondition(c WhereCondition) error
+	VisitSortField(f SortField) error
+	VisitPage(limit uint, offset uint) error
+}
+
+// Walk calls fn for each where condition of cond including the ones inside groups, it stops on the first error
+// of fn and returns it.
+func Walk(cond *SelectionCondition, fn func(WhereCondition) error) error {
+	if cond == nil {
+		return nil
+	}
+	return walkWhere(cond.Where, &walkVisitor{fn: fn})
+}
+
+// Visit calls the methods of v for the parts of cond, it stops on the first error of v and returns it.
+func Visit(cond *SelectionCondition, v Visitor) error {
+	if cond == nil {
+		return nil
+	}
+	if err := walkWhere(cond.Where, v); err != nil {
+		return err
+	}
+	for _, f := range cond.SortOrder {
+		if err := v.VisitSortField(f); err != nil {
+			return err
+		}
+	}
+	return v.VisitPage(cond.Limit, cond.Offset)
+}
+
+func walkWhere(where interface{}, v Visitor) error {
+	switch w := where.(type) {
+	case nil:
+		return nil
+	case WhereConditions:
+		for _, c := range w {
+			if err := v.VisitCondition(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case WhereCondition:
+		return v.VisitCondition(w)
+	case WhereConditionGroup:
+		if err := v.EnterGroup(w); err != nil {
+			return err
+		}
+		for _, item := range w.Conditions {
+			if err := walkWhere(item, v); err != nil {
+				return err
+			}
+		}
+		return v.LeaveGroup(w)
+	}
+	return errors.Errorf("Condition of a group must be a WhereCondition or a WhereConditionGroup, got %T", where)
+}
+
+// walkVisitor visits only the where conditions by fn.
+type walkVisitor struct {
+	fn func(WhereCondition) error
+}
+
+func (w *walkVisitor) EnterGroup(WhereConditionGroup) error {
+	return nil
+}
+
+func (w *walkVisitor) LeaveGroup(WhereConditionGroup) error {
+	return nil
+}
+
+func (w *walkVisitor) VisitCondition(c WhereCondition) error {
+	return w.fn(c)
+}
+
+func (w *walkVisitor) VisitSortField(SortField) error {
+	return nil
+}
+
+func (w *walkVisitor) VisitPage(uint, uint) error {
+	return nil
+}
@@ -0,0 +1,78 @@
+package selection_condition
+
+import "github.com/pkg/errors"
+
+// Where is the where part of a SelectionCondition: WhereConditions joined by AND, a WhereConditionGroup
+// or a single WhereCondition. A consumer gets the conditions by Group instead of switching on the types:
+//
+//	for _, item := range cond.Where.Group().Conditions {
+//
+// Where is nil if there are no conditions. A former value of Where of another type like []WhereCondition
+// is converted by ToWhere.
+type Where interface {
+	// Group returns the conditions as a group, WhereConditions and a WhereCondition are a group with the logic and
+	Group() WhereConditionGroup
+	// Empty reports whether there are no conditions
+	Empty() bool
+	isWhere()
+}
+
+func (s WhereConditions) Group() WhereConditionGroup {
+	group := WhereConditionGroup{
+		Logic:      LogicAnd,
+		Conditions: make([]interface{}, 0, len(s)),
+	}
+	for _, c := range s {
+		group.Conditions = append(group.Conditions, c)
+	}
+	return group
+}
+
+func (s WhereConditions) Empty() bool {
+	return len(s) == 0
+}
+
+func (s WhereConditions) isWhere() {}
+
+func (s WhereCondition) Group() WhereConditionGroup {
+	return WhereConditionGroup{
+		Logic:      LogicAnd,
+		Conditions: []interface{}{s},
+	}
+}
+
+func (s WhereCondition) Empty() bool {
+	return false
+}
+
+func (s WhereCondition) isWhere() {}
+
+func (g WhereConditionGroup) Group() WhereConditionGroup {
+	return g
+}
+
+func (g WhereConditionGroup) Empty() bool {
+	return len(g.Conditions) == 0
+}
+
+func (g WhereConditionGroup) isWhere() {}
+
+// ToWhere converts a former value of Where: nil, WhereConditions, a []WhereCondition, a WhereCondition
+// or a WhereConditionGroup.
+func ToWhere(where interface{}) (Where, error) {
+	switch w := where.(type) {
+	case nil:
+		return nil, nil
+	case Where:
+		return w, nil
+	case []WhereCondition:
+		return WhereConditions(w), nil
+	}
+	return nil, errors.Errorf("Where must be WhereConditions, a WhereCondition or a WhereConditionGroup, got %T", where)
+}
+
+// asWhere converts the where made by the package as a group item, it is always of a type of Where or nil.
+func asWhere(where interface{}) Where {
+	w, _ := ToWhere(where)
+	return w
+}